@@ -0,0 +1,303 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamba/pkg/log"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+)
+
+// Alert states.
+const (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// notifyTimeout is the maximum time a sink has to send a notification.
+const notifyTimeout = 10 * time.Second
+
+// notifyQueueSize is the number of notifications that can wait to be sent.
+const notifyQueueSize = 100
+
+// Snapshot represents the state of a cluster the rules are evaluated against.
+type Snapshot struct {
+	Cluster         string
+	Brokers         []kafka.Broker
+	BrokerMetadata  store.BrokerMetadata
+	ConsumerOffsets store.ConsumerOffsets
+	Time            time.Time
+}
+
+// Alert represents the state of a rule for a single subject.
+type Alert struct {
+	Rule       string
+	Type       string
+	Cluster    string
+	Labels     map[string]string
+	State      string
+	Value      float64
+	Threshold  float64
+	Message    string
+	Since      time.Time
+	FiredAt    time.Time
+	ResolvedAt time.Time
+}
+
+// Sink represents an alert notification sink.
+type Sink interface {
+	// Notify sends a notification of a firing or resolved alert.
+	Notify(ctx context.Context, a Alert) error
+}
+
+// EngineFunc represents a function that configures the Engine.
+type EngineFunc func(e *Engine)
+
+// Rules configures the rules evaluated by the Engine.
+func Rules(rules []Rule) EngineFunc {
+	return func(e *Engine) {
+		e.rules = rules
+	}
+}
+
+// Sinks configures the notification sinks on the Engine.
+func Sinks(sinks ...Sink) EngineFunc {
+	return func(e *Engine) {
+		e.sinks = sinks
+	}
+}
+
+// Log configures the logger on the Engine.
+func Log(log log.Logger) EngineFunc {
+	return func(e *Engine) {
+		e.log = log
+	}
+}
+
+// Engine evaluates alert rules and notifies the sinks of state changes.
+//
+// An alert becomes pending once its value exceeds the threshold, and fires once it
+// stayed above the threshold for the duration of the rule. A firing alert is resolved
+// once its value drops to the resolve threshold, or its subject disappears.
+//
+// Notifications are sent in the background, so slow sinks do not block the evaluation.
+// When the queue is full, new notifications are dropped.
+type Engine struct {
+	rules []Rule
+	sinks []Sink
+	log   log.Logger
+
+	mu     sync.Mutex
+	alerts map[string]*Alert
+	closed bool
+
+	queue chan Alert
+	done  chan struct{}
+}
+
+// New creates and returns a new Engine.
+func New(opts ...EngineFunc) (*Engine, error) {
+	e := &Engine{
+		log:    log.Null,
+		alerts: make(map[string]*Alert),
+		queue:  make(chan Alert, notifyQueueSize),
+		done:   make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	names := map[string]bool{}
+	for _, r := range e.rules {
+		if r.Name == "" {
+			return nil, errors.New("alert: rule name is required")
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("alert: duplicate rule %q", r.Name)
+		}
+		names[r.Name] = true
+
+		if err := r.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	go e.run()
+
+	return e, nil
+}
+
+// Evaluate evaluates the rules against the cluster state.
+func (e *Engine) Evaluate(s Snapshot) {
+	var notify []Alert
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range e.rules {
+		if !matches(r.Cluster, s.Cluster) {
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, inst := range r.instances(s) {
+			key := alertKey(r.Name, s.Cluster, inst.labels)
+			seen[key] = true
+
+			a, ok := e.alerts[key]
+			if !ok {
+				if inst.value <= r.Threshold {
+					continue
+				}
+
+				a = &Alert{
+					Rule:      r.Name,
+					Type:      r.Type,
+					Cluster:   s.Cluster,
+					Labels:    inst.labels,
+					State:     StatePending,
+					Threshold: r.Threshold,
+					Since:     s.Time,
+				}
+				e.alerts[key] = a
+			}
+			a.Value = inst.value
+			a.Message = message(r, s.Cluster, inst)
+
+			switch {
+			case inst.value > r.Threshold:
+				if a.State == StatePending && s.Time.Sub(a.Since) >= r.For {
+					a.State = StateFiring
+					a.FiredAt = s.Time
+					notify = append(notify, *a)
+				}
+
+			case a.State == StatePending:
+				delete(e.alerts, key)
+
+			case inst.value <= r.resolveThreshold():
+				notify = append(notify, e.resolve(key, s.Time))
+			}
+		}
+
+		for key, a := range e.alerts {
+			if a.Rule != r.Name || a.Cluster != s.Cluster || seen[key] {
+				continue
+			}
+
+			if a.State == StatePending {
+				delete(e.alerts, key)
+				continue
+			}
+			notify = append(notify, e.resolve(key, s.Time))
+		}
+	}
+
+	e.enqueue(notify)
+}
+
+// resolve resolves and removes the firing alert.
+func (e *Engine) resolve(key string, ts time.Time) Alert {
+	a := e.alerts[key]
+	a.State = StateResolved
+	a.ResolvedAt = ts
+	delete(e.alerts, key)
+
+	return *a
+}
+
+// enqueue queues the alerts to be sent to the sinks.
+//
+// The caller must hold the lock.
+func (e *Engine) enqueue(alerts []Alert) {
+	if e.closed || len(e.sinks) == 0 {
+		return
+	}
+
+	for _, a := range alerts {
+		select {
+		case e.queue <- a:
+		default:
+			e.log.Error(fmt.Sprintf("alert: notification queue is full, dropping %s alert %s", a.State, a.Rule))
+		}
+	}
+}
+
+// run sends the queued alerts to the sinks until the queue is closed.
+func (e *Engine) run() {
+	defer close(e.done)
+
+	for a := range e.queue {
+		e.notify(a)
+	}
+}
+
+// notify sends the alert to all sinks.
+func (e *Engine) notify(a Alert) {
+	for _, sink := range e.sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := sink.Notify(ctx, a); err != nil {
+			e.log.Error(fmt.Sprintf("alert: cannot notify %s alert %s: %v", a.State, a.Rule, err))
+		}
+		cancel()
+	}
+}
+
+// Close stops the Engine once the queued notifications have been sent.
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	<-e.done
+
+	return nil
+}
+
+// Alerts returns the pending and firing alerts.
+func (e *Engine) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	keys := make([]string, 0, len(e.alerts))
+	for key := range e.alerts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	alerts := make([]Alert, 0, len(keys))
+	for _, key := range keys {
+		a := *e.alerts[key]
+
+		a.Labels = make(map[string]string, len(e.alerts[key].Labels))
+		for k, v := range e.alerts[key].Labels {
+			a.Labels[k] = v
+		}
+
+		alerts = append(alerts, a)
+	}
+
+	return alerts
+}
+
+// alertKey creates the unique key of an alert.
+func alertKey(rule, cluster string, labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return rule + "\x00" + cluster + "\x00" + strings.Join(pairs, "\x00")
+}
@@ -0,0 +1,309 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msales/kage/alert"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	alerts []alert.Alert
+	err    error
+}
+
+func (s *recordingSink) Notify(ctx context.Context, a alert.Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.alerts = append(s.alerts, a)
+	return s.err
+}
+
+// waitAlerts waits until the sink received n alerts and returns them.
+func (s *recordingSink) waitAlerts(t *testing.T, n int) []alert.Alert {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		alerts := append([]alert.Alert(nil), s.alerts...)
+		s.mu.Unlock()
+
+		if len(alerts) >= n || time.Now().After(deadline) {
+			assert.Len(t, alerts, n)
+			return alerts
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type blockingSink struct {
+	unblock chan struct{}
+}
+
+func (s *blockingSink) Notify(ctx context.Context, a alert.Alert) error {
+	<-s.unblock
+	return nil
+}
+
+func lagSnapshot(ts time.Time, lag int64) alert.Snapshot {
+	return alert.Snapshot{
+		Cluster: "eu",
+		ConsumerOffsets: store.ConsumerOffsets{
+			"orders": {
+				"foo": []*store.ConsumerOffset{{Lag: lag / 2}, nil, {Lag: lag - lag/2}},
+			},
+			"other": {
+				"foo": []*store.ConsumerOffset{{Lag: 10000}},
+			},
+		},
+		Time: ts,
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestNew(t *testing.T) {
+	e, err := alert.New(alert.Rules([]alert.Rule{{Name: "test", Type: alert.RuleLeaderless}}))
+
+	assert.NoError(t, err)
+	assert.IsType(t, &alert.Engine{}, e)
+}
+
+func TestNew_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []alert.Rule
+	}{
+		{name: "missing name", rules: []alert.Rule{{Type: alert.RuleLeaderless}}},
+		{name: "duplicate name", rules: []alert.Rule{{Name: "test", Type: alert.RuleLeaderless}, {Name: "test", Type: alert.RuleLeaderless}}},
+		{name: "unknown type", rules: []alert.Rule{{Name: "test", Type: "foo"}}},
+		{name: "negative duration", rules: []alert.Rule{{Name: "test", Type: alert.RuleLeaderless, For: -time.Second}}},
+		{name: "resolve threshold", rules: []alert.Rule{{Name: "test", Type: alert.RuleConsumerLag, Threshold: 10, ResolveThreshold: floatPtr(20)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := alert.New(alert.Rules(tt.rules))
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEngine_ConsumerLag(t *testing.T) {
+	ts := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	sink := &recordingSink{}
+	e, err := alert.New(
+		alert.Rules([]alert.Rule{{
+			Name:             "lag",
+			Type:             alert.RuleConsumerLag,
+			Group:            "order*",
+			Threshold:        1000,
+			ResolveThreshold: floatPtr(500),
+			For:              5 * time.Minute,
+		}}),
+		alert.Sinks(sink),
+	)
+	assert.NoError(t, err)
+
+	// Pending while the duration has not passed.
+	e.Evaluate(lagSnapshot(ts, 1500))
+	e.Evaluate(lagSnapshot(ts.Add(4*time.Minute), 1500))
+
+	alerts := e.Alerts()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, alert.StatePending, alerts[0].State)
+	assert.Equal(t, map[string]string{"group": "orders", "topic": "foo"}, alerts[0].Labels)
+	sink.waitAlerts(t, 0)
+
+	// Fires once the duration has passed.
+	e.Evaluate(lagSnapshot(ts.Add(5*time.Minute), 1500))
+
+	alerts = e.Alerts()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, alert.StateFiring, alerts[0].State)
+	assert.Equal(t, ts, alerts[0].Since)
+	assert.Equal(t, ts.Add(5*time.Minute), alerts[0].FiredAt)
+	notified := sink.waitAlerts(t, 1)
+	assert.Equal(t, alert.StateFiring, notified[0].State)
+	assert.Equal(t, "cluster eu: consumer group orders lag on topic foo is 1500 (threshold 1000)", notified[0].Message)
+
+	// Keeps firing above the resolve threshold.
+	e.Evaluate(lagSnapshot(ts.Add(6*time.Minute), 800))
+
+	alerts = e.Alerts()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, alert.StateFiring, alerts[0].State)
+	assert.Equal(t, float64(800), alerts[0].Value)
+	sink.waitAlerts(t, 1)
+
+	// Resolves at the resolve threshold.
+	e.Evaluate(lagSnapshot(ts.Add(7*time.Minute), 500))
+
+	assert.Len(t, e.Alerts(), 0)
+	notified = sink.waitAlerts(t, 2)
+	assert.Equal(t, alert.StateResolved, notified[1].State)
+	assert.Equal(t, ts.Add(7*time.Minute), notified[1].ResolvedAt)
+}
+
+func TestEngine_PendingReset(t *testing.T) {
+	ts := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	sink := &recordingSink{}
+	e, _ := alert.New(
+		alert.Rules([]alert.Rule{{Name: "lag", Type: alert.RuleConsumerLag, Group: "orders", Threshold: 1000, For: time.Minute}}),
+		alert.Sinks(sink),
+	)
+
+	e.Evaluate(lagSnapshot(ts, 1500))
+	e.Evaluate(lagSnapshot(ts.Add(30*time.Second), 100))
+	e.Evaluate(lagSnapshot(ts.Add(time.Minute), 1500))
+
+	alerts := e.Alerts()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, alert.StatePending, alerts[0].State)
+	assert.Equal(t, ts.Add(time.Minute), alerts[0].Since)
+	sink.waitAlerts(t, 0)
+}
+
+func TestEngine_ResolvesDisappearedSubject(t *testing.T) {
+	ts := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	sink := &recordingSink{}
+	e, _ := alert.New(
+		alert.Rules([]alert.Rule{{Name: "leaderless", Type: alert.RuleLeaderless}}),
+		alert.Sinks(sink),
+	)
+
+	e.Evaluate(alert.Snapshot{
+		Cluster:        "eu",
+		BrokerMetadata: store.BrokerMetadata{"foo": []*store.Metadata{{Leader: 1}, {Leader: -1}}},
+		Time:           ts,
+	})
+
+	alerts := e.Alerts()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, alert.StateFiring, alerts[0].State)
+	assert.Equal(t, map[string]string{"topic": "foo", "partition": "1"}, alerts[0].Labels)
+
+	// Other clusters do not affect the alert.
+	e.Evaluate(alert.Snapshot{Cluster: "us", Time: ts})
+	assert.Len(t, e.Alerts(), 1)
+
+	e.Evaluate(alert.Snapshot{Cluster: "eu", Time: ts.Add(time.Minute)})
+
+	assert.Len(t, e.Alerts(), 0)
+	notified := sink.waitAlerts(t, 2)
+	assert.Equal(t, alert.StateResolved, notified[1].State)
+}
+
+func TestEngine_UnderReplicated(t *testing.T) {
+	e, _ := alert.New(alert.Rules([]alert.Rule{{Name: "isr", Type: alert.RuleUnderReplicated, Topic: "foo"}}))
+
+	e.Evaluate(alert.Snapshot{
+		BrokerMetadata: store.BrokerMetadata{
+			"foo": []*store.Metadata{
+				{Leader: 1, Replicas: []int32{1, 2, 3}, Isr: []int32{1, 2, 3}},
+				{Leader: 1, Replicas: []int32{1, 2, 3}, Isr: []int32{1}},
+			},
+			"bar": []*store.Metadata{{Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1}}},
+		},
+		Time: time.Now(),
+	})
+
+	alerts := e.Alerts()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, float64(2), alerts[0].Value)
+	assert.Equal(t, "topic foo partition 1 has 2 out of sync replicas (threshold 0)", alerts[0].Message)
+}
+
+func TestEngine_BrokerDisconnected(t *testing.T) {
+	e, _ := alert.New(alert.Rules([]alert.Rule{{Name: "brokers", Type: alert.RuleBrokerDisconnected, Cluster: "eu"}}))
+
+	e.Evaluate(alert.Snapshot{
+		Cluster: "eu",
+		Brokers: []kafka.Broker{{ID: 1, Connected: true}, {ID: 2, Connected: false}},
+		Time:    time.Now(),
+	})
+	e.Evaluate(alert.Snapshot{
+		Cluster: "us",
+		Brokers: []kafka.Broker{{ID: 3, Connected: false}},
+		Time:    time.Now(),
+	})
+
+	alerts := e.Alerts()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "eu", alerts[0].Cluster)
+	assert.Equal(t, map[string]string{"broker": "2"}, alerts[0].Labels)
+}
+
+func TestEngine_SinkError(t *testing.T) {
+	sink := &recordingSink{err: errors.New("test error")}
+	e, _ := alert.New(
+		alert.Rules([]alert.Rule{{Name: "brokers", Type: alert.RuleBrokerDisconnected}}),
+		alert.Sinks(sink),
+		alert.Log(testutil.Logger),
+	)
+
+	e.Evaluate(alert.Snapshot{Brokers: []kafka.Broker{{ID: 1}}, Time: time.Now()})
+
+	sink.waitAlerts(t, 1)
+	assert.Len(t, e.Alerts(), 1)
+}
+
+func TestEngine_NotifiesInBackground(t *testing.T) {
+	sink := &blockingSink{unblock: make(chan struct{})}
+	e, _ := alert.New(
+		alert.Rules([]alert.Rule{{Name: "brokers", Type: alert.RuleBrokerDisconnected}}),
+		alert.Sinks(sink),
+		alert.Log(testutil.Logger),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		// More notifications than the queue holds, the rest are dropped.
+		for i := 0; i < 200; i++ {
+			e.Evaluate(alert.Snapshot{Brokers: []kafka.Broker{{ID: int32(i)}}, Time: time.Now()})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("evaluation blocked on the sink")
+	}
+	assert.Len(t, e.Alerts(), 1)
+
+	close(sink.unblock)
+	assert.NoError(t, e.Close())
+}
+
+func TestEngine_Close(t *testing.T) {
+	sink := &recordingSink{}
+	e, _ := alert.New(
+		alert.Rules([]alert.Rule{{Name: "brokers", Type: alert.RuleBrokerDisconnected}}),
+		alert.Sinks(sink),
+	)
+
+	e.Evaluate(alert.Snapshot{Brokers: []kafka.Broker{{ID: 1}}, Time: time.Now()})
+
+	assert.NoError(t, e.Close())
+	assert.Len(t, sink.alerts, 1)
+
+	// Evaluating after closing does not notify.
+	e.Evaluate(alert.Snapshot{Time: time.Now()})
+	assert.NoError(t, e.Close())
+	assert.Len(t, sink.alerts, 1)
+}
@@ -0,0 +1,184 @@
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ryanuber/go-glob"
+)
+
+// Rule types.
+const (
+	// RuleConsumerLag fires when the lag of a consumer group on a topic exceeds the threshold.
+	RuleConsumerLag = "consumer_lag"
+	// RuleUnderReplicated fires when the number of out of sync replicas of a partition exceeds the threshold.
+	RuleUnderReplicated = "under_replicated"
+	// RuleLeaderless fires when a partition has no leader.
+	RuleLeaderless = "leaderless"
+	// RuleBrokerDisconnected fires when a broker is disconnected.
+	RuleBrokerDisconnected = "broker_disconnected"
+)
+
+// Rule represents an alert rule.
+//
+// The Cluster, Group and Topic patterns may contain wildcards and
+// match everything when empty.
+type Rule struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"`
+	Cluster string `yaml:"cluster"`
+	Group   string `yaml:"group"`
+	Topic   string `yaml:"topic"`
+
+	// Threshold is the value that must be exceeded for the alert to fire.
+	Threshold float64 `yaml:"threshold"`
+	// ResolveThreshold is the value the alert must drop to before it resolves.
+	// It defaults to the Threshold.
+	ResolveThreshold *float64 `yaml:"resolve-threshold"`
+	// For is the duration the threshold must be exceeded before the alert fires.
+	For time.Duration `yaml:"for"`
+}
+
+// validate checks the rule configuration.
+func (r Rule) validate() error {
+	switch r.Type {
+	case RuleConsumerLag, RuleUnderReplicated, RuleLeaderless, RuleBrokerDisconnected:
+	default:
+		return fmt.Errorf("alert: rule %s has unknown type %q", r.Name, r.Type)
+	}
+
+	if r.For < 0 {
+		return fmt.Errorf("alert: rule %s has a negative duration", r.Name)
+	}
+
+	if r.ResolveThreshold != nil && *r.ResolveThreshold > r.Threshold {
+		return fmt.Errorf("alert: rule %s resolve threshold exceeds the threshold", r.Name)
+	}
+
+	return nil
+}
+
+// resolveThreshold returns the value a firing alert must drop to before it resolves.
+func (r Rule) resolveThreshold() float64 {
+	if r.ResolveThreshold == nil {
+		return r.Threshold
+	}
+
+	return *r.ResolveThreshold
+}
+
+// instance represents the value of a rule for a single subject.
+type instance struct {
+	labels map[string]string
+	value  float64
+}
+
+// instances returns the values of the rule for every subject in the snapshot.
+func (r Rule) instances(s Snapshot) []instance {
+	var insts []instance
+
+	switch r.Type {
+	case RuleConsumerLag:
+		for group, topics := range s.ConsumerOffsets {
+			if !matches(r.Group, group) {
+				continue
+			}
+
+			for topic, partitions := range topics {
+				if !matches(r.Topic, topic) {
+					continue
+				}
+
+				var lag int64
+				for _, p := range partitions {
+					if p == nil {
+						continue
+					}
+					lag += p.Lag
+				}
+
+				insts = append(insts, instance{
+					labels: map[string]string{"group": group, "topic": topic},
+					value:  float64(lag),
+				})
+			}
+		}
+
+	case RuleUnderReplicated, RuleLeaderless:
+		for topic, partitions := range s.BrokerMetadata {
+			if !matches(r.Topic, topic) {
+				continue
+			}
+
+			for partition, md := range partitions {
+				if md == nil {
+					continue
+				}
+
+				value := float64(len(md.Replicas) - len(md.Isr))
+				if r.Type == RuleLeaderless {
+					value = 0
+					if md.Leader < 0 {
+						value = 1
+					}
+				}
+
+				insts = append(insts, instance{
+					labels: map[string]string{"topic": topic, "partition": strconv.Itoa(partition)},
+					value:  value,
+				})
+			}
+		}
+
+	case RuleBrokerDisconnected:
+		for _, b := range s.Brokers {
+			value := float64(0)
+			if !b.Connected {
+				value = 1
+			}
+
+			insts = append(insts, instance{
+				labels: map[string]string{"broker": strconv.Itoa(int(b.ID))},
+				value:  value,
+			})
+		}
+	}
+
+	return insts
+}
+
+// message describes the rule instance.
+func message(r Rule, cluster string, inst instance) string {
+	var msg string
+	switch r.Type {
+	case RuleConsumerLag:
+		msg = fmt.Sprintf("consumer group %s lag on topic %s is %g (threshold %g)",
+			inst.labels["group"], inst.labels["topic"], inst.value, r.Threshold)
+
+	case RuleUnderReplicated:
+		msg = fmt.Sprintf("topic %s partition %s has %g out of sync replicas (threshold %g)",
+			inst.labels["topic"], inst.labels["partition"], inst.value, r.Threshold)
+
+	case RuleLeaderless:
+		msg = fmt.Sprintf("topic %s partition %s has no leader", inst.labels["topic"], inst.labels["partition"])
+
+	case RuleBrokerDisconnected:
+		msg = fmt.Sprintf("broker %s is disconnected", inst.labels["broker"])
+	}
+
+	if cluster != "" {
+		msg = "cluster " + cluster + ": " + msg
+	}
+
+	return msg
+}
+
+// matches determines if the subject matches the pattern, where an empty pattern matches everything.
+func matches(pattern, subject string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	return glob.Glob(pattern, subject)
+}
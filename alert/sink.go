@@ -0,0 +1,179 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WebhookSink posts alerts as JSON to a generic webhook.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates and returns a new WebhookSink.
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &WebhookSink{
+		url:    url,
+		client: client,
+	}
+}
+
+type webhookPayload struct {
+	Rule       string            `json:"rule"`
+	Type       string            `json:"type"`
+	Cluster    string            `json:"cluster,omitempty"`
+	Labels     map[string]string `json:"labels"`
+	State      string            `json:"state"`
+	Value      float64           `json:"value"`
+	Threshold  float64           `json:"threshold"`
+	Message    string            `json:"message"`
+	Since      time.Time         `json:"since"`
+	FiredAt    time.Time         `json:"fired_at"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+}
+
+// Notify sends a notification of a firing or resolved alert.
+func (s *WebhookSink) Notify(ctx context.Context, a Alert) error {
+	payload := webhookPayload{
+		Rule:      a.Rule,
+		Type:      a.Type,
+		Cluster:   a.Cluster,
+		Labels:    a.Labels,
+		State:     a.State,
+		Value:     a.Value,
+		Threshold: a.Threshold,
+		Message:   a.Message,
+		Since:     a.Since,
+		FiredAt:   a.FiredAt,
+	}
+	if !a.ResolvedAt.IsZero() {
+		payload.ResolvedAt = &a.ResolvedAt
+	}
+
+	return postJSON(ctx, s.client, s.url, payload)
+}
+
+// SlackSink posts alerts to a Slack compatible incoming webhook.
+type SlackSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackSink creates and returns a new SlackSink.
+func NewSlackSink(url string, client *http.Client) *SlackSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &SlackSink{
+		url:    url,
+		client: client,
+	}
+}
+
+// Notify sends a notification of a firing or resolved alert.
+func (s *SlackSink) Notify(ctx context.Context, a Alert) error {
+	payload := struct {
+		Text string `json:"text"`
+	}{
+		Text: fmt.Sprintf("[%s] %s: %s", strings.ToUpper(a.State), a.Rule, a.Message),
+	}
+
+	return postJSON(ctx, s.client, s.url, payload)
+}
+
+// SMTPSink sends alerts by email.
+type SMTPSink struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSink creates and returns a new SMTPSink.
+//
+// The auth may be nil when the server does not require authentication.
+func NewSMTPSink(addr string, auth smtp.Auth, from string, to []string) *SMTPSink {
+	return &SMTPSink{
+		addr: addr,
+		auth: auth,
+		from: from,
+		to:   to,
+		send: smtp.SendMail,
+	}
+}
+
+// Notify sends a notification of a firing or resolved alert.
+//
+// The context is not supported by the SMTP client and is only checked before sending.
+func (s *SMTPSink) Notify(ctx context.Context, a Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	labels := make([]string, 0, len(a.Labels))
+	for k, v := range a.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&msg, "Subject: [%s] %s\r\n", strings.ToUpper(a.State), a.Rule)
+	fmt.Fprint(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\n", a.Message)
+	fmt.Fprintf(&msg, "Labels: %s\r\n", strings.Join(labels, ", "))
+	fmt.Fprintf(&msg, "Since: %s\r\n", a.Since.Format(time.RFC3339))
+	if !a.ResolvedAt.IsZero() {
+		fmt.Fprintf(&msg, "Resolved: %s\r\n", a.ResolvedAt.Format(time.RFC3339))
+	}
+
+	return s.send(s.addr, s.auth, s.from, s.to, msg.Bytes())
+}
+
+// postJSON posts the payload as JSON to the url.
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("alert: %s responded with status %d", url, resp.StatusCode)
+	}
+
+	return nil
+}
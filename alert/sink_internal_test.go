@@ -0,0 +1,63 @@
+package alert
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTPSink_Notify(t *testing.T) {
+	var (
+		gotAddr string
+		gotFrom string
+		gotTo   []string
+		gotMsg  string
+	)
+
+	s := NewSMTPSink("localhost:25", nil, "kage@example.com", []string{"ops@example.com", "dev@example.com"})
+	s.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, string(msg)
+		return nil
+	}
+
+	err := s.Notify(context.Background(), Alert{
+		Rule:    "brokers",
+		Type:    RuleBrokerDisconnected,
+		Labels:  map[string]string{"broker": "1"},
+		State:   StateFiring,
+		Value:   1,
+		Message: "broker 1 is disconnected",
+		Since:   time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC),
+	})
+
+	want := "From: kage@example.com\r\n" +
+		"To: ops@example.com, dev@example.com\r\n" +
+		"Subject: [FIRING] brokers\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n" +
+		"broker 1 is disconnected\r\n\r\n" +
+		"Labels: broker=1\r\n" +
+		"Since: 2020-09-01T12:00:00Z\r\n"
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:25", gotAddr)
+	assert.Equal(t, "kage@example.com", gotFrom)
+	assert.Equal(t, []string{"ops@example.com", "dev@example.com"}, gotTo)
+	assert.Equal(t, want, gotMsg)
+}
+
+func TestSMTPSink_NotifyCanceled(t *testing.T) {
+	s := NewSMTPSink("localhost:25", nil, "kage@example.com", []string{"ops@example.com"})
+	s.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		t.Fatal("unexpected send")
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.Notify(ctx, Alert{})
+
+	assert.Error(t, err)
+}
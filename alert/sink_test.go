@@ -0,0 +1,76 @@
+package alert_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/msales/kage/alert"
+	"github.com/stretchr/testify/assert"
+)
+
+var fixtureAlert = alert.Alert{
+	Rule:       "lag",
+	Type:       alert.RuleConsumerLag,
+	Cluster:    "eu",
+	Labels:     map[string]string{"group": "foo", "topic": "bar"},
+	State:      alert.StateResolved,
+	Value:      500,
+	Threshold:  1000,
+	Message:    "cluster eu: consumer group foo lag on topic bar is 500 (threshold 1000)",
+	Since:      time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC),
+	FiredAt:    time.Date(2020, 9, 1, 12, 5, 0, 0, time.UTC),
+	ResolvedAt: time.Date(2020, 9, 1, 12, 10, 0, 0, time.UTC),
+}
+
+func newRecordingServer(t *testing.T, status int, body *string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		b, _ := ioutil.ReadAll(r.Body)
+		*body = string(b)
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestWebhookSink_Notify(t *testing.T) {
+	var body string
+	srv := newRecordingServer(t, http.StatusOK, &body)
+
+	s := alert.NewWebhookSink(srv.URL, nil)
+	err := s.Notify(context.Background(), fixtureAlert)
+
+	want := `{"rule":"lag","type":"consumer_lag","cluster":"eu","labels":{"group":"foo","topic":"bar"},"state":"resolved","value":500,"threshold":1000,` +
+		`"message":"cluster eu: consumer group foo lag on topic bar is 500 (threshold 1000)","since":"2020-09-01T12:00:00Z","fired_at":"2020-09-01T12:05:00Z","resolved_at":"2020-09-01T12:10:00Z"}`
+	assert.NoError(t, err)
+	assert.Equal(t, want, body)
+}
+
+func TestWebhookSink_NotifyError(t *testing.T) {
+	var body string
+	srv := newRecordingServer(t, http.StatusInternalServerError, &body)
+
+	s := alert.NewWebhookSink(srv.URL, nil)
+	err := s.Notify(context.Background(), fixtureAlert)
+
+	assert.Error(t, err)
+}
+
+func TestSlackSink_Notify(t *testing.T) {
+	var body string
+	srv := newRecordingServer(t, http.StatusOK, &body)
+
+	s := alert.NewSlackSink(srv.URL, nil)
+	err := s.Notify(context.Background(), fixtureAlert)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"text":"[RESOLVED] lag: cluster eu: consumer group foo lag on topic bar is 500 (threshold 1000)"}`, body)
+}
@@ -1,15 +1,30 @@
 package kage
 
 import (
+	"context"
+	"io"
+	"sync"
+
 	"github.com/hamba/pkg/log"
 )
 
 // Application represents the kage application.
+//
+// The Store, Reporters and Monitor belong to the default cluster. When multiple
+// clusters are monitored, Clusters contains all of them, including the default cluster.
 type Application struct {
+	Name      string
 	Store     Store
 	Reporters *Reporters
 	Monitor   Monitor
 
+	// FailOnOfflinePartitions marks the default cluster unhealthy when it has offline partitions.
+	FailOnOfflinePartitions bool
+
+	Clusters Clusters
+
+	Alerter Alerter
+
 	Logger log.Logger
 }
 
@@ -18,39 +33,87 @@ func NewApplication() *Application {
 	return &Application{}
 }
 
+// DefaultCluster returns the default cluster of the Application.
+func (a *Application) DefaultCluster() *Cluster {
+	return &Cluster{
+		Name:      a.Name,
+		Store:     a.Store,
+		Reporters: a.Reporters,
+		Monitor:   a.Monitor,
+
+		FailOnOfflinePartitions: a.FailOnOfflinePartitions,
+	}
+}
+
+// Cluster returns the cluster with the given name.
+func (a *Application) Cluster(name string) (*Cluster, bool) {
+	c, ok := a.clusters()[name]
+	return c, ok
+}
+
+// clusters returns all the clusters of the Application.
+func (a *Application) clusters() Clusters {
+	if len(a.Clusters) == 0 {
+		return Clusters{a.Name: a.DefaultCluster()}
+	}
+
+	return a.Clusters
+}
+
 // Close gracefully shuts down the application.
 func (a *Application) Close() {
-	if a.Store != nil {
-		a.Store.Close()
-	}
+	a.clusters().each(func(c *Cluster) {
+		c.Close()
+	})
 
-	if a.Monitor != nil {
-		a.Monitor.Close()
+	if c, ok := a.Alerter.(io.Closer); ok {
+		_ = c.Close()
 	}
 }
 
-// Collect collects the current state of the Kafka cluster.
+// Collect collects the current state of all Kafka clusters.
 func (a *Application) Collect() {
-	a.Monitor.Collect()
+	a.clusters().each(func(c *Cluster) {
+		c.Collect()
+	})
 }
 
-// Report reports the current state of the MemoryStore to the Reporters.
-func (a *Application) Report() {
-	bo := a.Store.BrokerOffsets()
-	a.Reporters.ReportBrokerOffsets(&bo)
+// Flush waits until all collected states have been applied to the Stores.
+func (a *Application) Flush(ctx context.Context) error {
+	var (
+		mu     sync.Mutex
+		result error
+	)
+	a.clusters().each(func(c *Cluster) {
+		if err := c.Flush(ctx); err != nil {
+			mu.Lock()
+			result = err
+			mu.Unlock()
+		}
+	})
+
+	return result
+}
 
-	bm := a.Store.BrokerMetadata()
-	a.Reporters.ReportBrokerMetadata(&bm)
+// Report reports the current state of the Stores to the Reporters,
+// then evaluates the alert rules against it.
+func (a *Application) Report() {
+	a.clusters().each(func(c *Cluster) {
+		c.Report()
 
-	co := a.Store.ConsumerOffsets()
-	a.Reporters.ReportConsumerOffsets(&co)
+		if a.Alerter != nil {
+			a.Alerter.Evaluate(c.Snapshot())
+		}
+	})
 }
 
 // IsHealthy checks the health of the Application.
 func (a *Application) IsHealthy() bool {
-	if a.Monitor == nil {
-		return false
+	for _, c := range a.clusters() {
+		if !c.IsHealthy() {
+			return false
+		}
 	}
 
-	return a.Monitor.IsHealthy()
+	return true
 }
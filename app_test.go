@@ -1,12 +1,17 @@
 package kage_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/msales/kage"
+	"github.com/msales/kage/alert"
+	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/store"
 	"github.com/msales/kage/testutil/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestNewApplication(t *testing.T) {
@@ -57,14 +62,42 @@ func TestApplication_IsHealthyNoServices(t *testing.T) {
 	assert.False(t, app.IsHealthy())
 }
 
+func TestApplication_IsHealthyOfflinePartitions(t *testing.T) {
+	bm := store.BrokerMetadata{"test": []*store.Metadata{{Leader: -1, Replicas: []int32{1}}}}
+
+	s := new(mocks.MockStore)
+	s.On("BrokerMetadata").Return(bm)
+	s.On("TopicConfigs").Return(store.TopicConfigs{})
+
+	monitor := new(mocks.MockMonitor)
+	monitor.On("IsHealthy").Return(true)
+
+	app := &kage.Application{
+		Store:   s,
+		Monitor: monitor,
+	}
+
+	assert.True(t, app.IsHealthy())
+
+	app.FailOnOfflinePartitions = true
+
+	assert.False(t, app.IsHealthy())
+}
+
 func TestApplication_Report(t *testing.T) {
 	bo := store.BrokerOffsets{}
 	bm := store.BrokerMetadata{}
+	tc := store.TopicConfigs{}
 	co := store.ConsumerOffsets{}
+	h := store.ClusterHealth{Topics: map[string]*store.TopicHealth{}}
+	ld := store.BrokerLogDirs{}
+	cs := kafka.ClusterSummary{ControllerID: 1, Brokers: 1, ConnectedBrokers: 1}
 
 	store := new(mocks.MockStore)
 	store.On("BrokerOffsets").Return(bo)
 	store.On("BrokerMetadata").Return(bm)
+	store.On("TopicConfigs").Return(tc)
+	store.On("BrokerLogDirs").Return(ld)
 	store.On("ConsumerOffsets").Return(co)
 
 	reporters := &kage.Reporters{}
@@ -72,12 +105,19 @@ func TestApplication_Report(t *testing.T) {
 	reporter := new(mocks.MockReporter)
 	reporter.On("ReportBrokerOffsets", &bo).Return()
 	reporter.On("ReportBrokerMetadata", &bm).Return()
+	reporter.On("ReportClusterHealth", &h).Return()
+	reporter.On("ReportBrokerLogDirs", &ld).Return()
+	reporter.On("ReportClusterSummary", &cs).Return()
 	reporter.On("ReportConsumerOffsets", &co).Return()
 	reporters.Add("test", reporter)
 
+	monitor := new(mocks.MockMonitor)
+	monitor.On("Brokers").Return([]kafka.Broker{{ID: 1, Connected: true, Controller: true}})
+
 	app := &kage.Application{
 		Store:     store,
 		Reporters: reporters,
+		Monitor:   monitor,
 	}
 
 	app.Report()
@@ -86,6 +126,41 @@ func TestApplication_Report(t *testing.T) {
 	reporter.AssertExpectations(t)
 }
 
+func TestApplication_ReportEvaluatesAlerts(t *testing.T) {
+	bo := store.BrokerOffsets{}
+	bm := store.BrokerMetadata{"test": []*store.Metadata{{Leader: -1}}}
+	tc := store.TopicConfigs{}
+	ld := store.BrokerLogDirs{}
+	co := store.ConsumerOffsets{}
+
+	store := new(mocks.MockStore)
+	store.On("BrokerOffsets").Return(bo)
+	store.On("BrokerMetadata").Return(bm)
+	store.On("TopicConfigs").Return(tc)
+	store.On("BrokerLogDirs").Return(ld)
+	store.On("ConsumerOffsets").Return(co)
+
+	monitor := new(mocks.MockMonitor)
+	monitor.On("Brokers").Return([]kafka.Broker{{ID: 1, Connected: true}})
+
+	alerter := new(mocks.MockAlerter)
+	alerter.On("Evaluate", mock.MatchedBy(func(s alert.Snapshot) bool {
+		return s.Cluster == "eu" && len(s.Brokers) == 1 && len(s.BrokerMetadata) == 1 && !s.Time.IsZero()
+	})).Return()
+
+	app := &kage.Application{
+		Name:      "eu",
+		Store:     store,
+		Reporters: &kage.Reporters{},
+		Monitor:   monitor,
+		Alerter:   alerter,
+	}
+
+	app.Report()
+
+	alerter.AssertExpectations(t)
+}
+
 func TestApplication_Collect(t *testing.T) {
 	monitor := new(mocks.MockMonitor)
 	monitor.On("Collect").Once()
@@ -98,3 +173,103 @@ func TestApplication_Collect(t *testing.T) {
 
 	monitor.AssertExpectations(t)
 }
+
+func TestApplication_Flush(t *testing.T) {
+	memStore, err := store.New()
+	assert.NoError(t, err)
+	defer memStore.Close()
+
+	app := &kage.Application{Store: memStore}
+
+	memStore.Channel() <- &store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              100,
+		Timestamp:           time.Now().Unix() * 1000,
+		TopicPartitionCount: 1,
+	}
+	err = app.Flush(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), memStore.BrokerOffsets()["test"][0].NewestOffset)
+}
+
+func TestApplication_FlushCanceled(t *testing.T) {
+	s := new(mocks.MockStore)
+	s.On("Channel").Return(make(chan interface{}))
+
+	app := &kage.Application{Store: s}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := app.Flush(ctx)
+
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestApplication_Cluster(t *testing.T) {
+	eu := &kage.Cluster{Name: "eu"}
+	us := &kage.Cluster{Name: "us"}
+
+	app := &kage.Application{
+		Name:     "eu",
+		Clusters: kage.Clusters{"eu": eu, "us": us},
+	}
+
+	c, ok := app.Cluster("us")
+	assert.True(t, ok)
+	assert.Equal(t, us, c)
+
+	_, ok = app.Cluster("none")
+	assert.False(t, ok)
+
+	assert.Equal(t, "eu", app.DefaultCluster().Name)
+}
+
+func TestApplication_CollectClusters(t *testing.T) {
+	euMonitor := new(mocks.MockMonitor)
+	euMonitor.On("Collect").Once()
+	usMonitor := new(mocks.MockMonitor)
+	usMonitor.On("Collect").Once()
+
+	app := &kage.Application{
+		Name:    "eu",
+		Monitor: euMonitor,
+		Clusters: kage.Clusters{
+			"eu": {Name: "eu", Monitor: euMonitor},
+			"us": {Name: "us", Monitor: usMonitor},
+		},
+	}
+
+	app.Collect()
+
+	euMonitor.AssertExpectations(t)
+	usMonitor.AssertExpectations(t)
+}
+
+func TestApplication_IsHealthyClusters(t *testing.T) {
+	euMonitor := new(mocks.MockMonitor)
+	euMonitor.On("IsHealthy").Return(true)
+	usMonitor := new(mocks.MockMonitor)
+	usMonitor.On("IsHealthy").Return(false)
+
+	app := &kage.Application{
+		Name:    "eu",
+		Monitor: euMonitor,
+		Clusters: kage.Clusters{
+			"eu": {Name: "eu", Monitor: euMonitor},
+			"us": {Name: "us", Monitor: usMonitor},
+		},
+	}
+
+	assert.False(t, app.IsHealthy())
+	assert.True(t, app.Clusters["eu"].IsHealthy())
+}
+
+func TestClusters_Names(t *testing.T) {
+	clusters := kage.Clusters{"us": {}, "eu": {}}
+
+	assert.Equal(t, []string{"eu", "us"}, clusters.Names())
+}
@@ -0,0 +1,146 @@
+package kage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/msales/kage/alert"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+)
+
+// Cluster represents a monitored Kafka cluster.
+type Cluster struct {
+	Name      string
+	Store     Store
+	Reporters *Reporters
+	Monitor   Monitor
+
+	// FailOnOfflinePartitions marks the cluster unhealthy when it has offline partitions.
+	FailOnOfflinePartitions bool
+}
+
+// Close gracefully shuts down the cluster.
+func (c *Cluster) Close() {
+	if c.Store != nil {
+		c.Store.Close()
+	}
+
+	if c.Monitor != nil {
+		c.Monitor.Close()
+	}
+
+	if c.Reporters != nil {
+		c.Reporters.Close()
+	}
+}
+
+// Collect collects the current state of the Kafka cluster.
+func (c *Cluster) Collect() {
+	c.Monitor.Collect()
+}
+
+// Flush waits until all collected states have been applied to the Store.
+func (c *Cluster) Flush(ctx context.Context) error {
+	f := store.NewFlush()
+
+	select {
+	case c.Store.Channel() <- f:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-f.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Report reports the current state of the Store to the Reporters.
+func (c *Cluster) Report() {
+	bo := c.Store.BrokerOffsets()
+	c.Reporters.ReportBrokerOffsets(&bo)
+
+	bm := c.Store.BrokerMetadata()
+	c.Reporters.ReportBrokerMetadata(&bm)
+
+	h := store.EvaluateClusterHealth(bm, c.Store.TopicConfigs())
+	c.Reporters.ReportClusterHealth(&h)
+
+	ld := c.Store.BrokerLogDirs()
+	c.Reporters.ReportBrokerLogDirs(&ld)
+
+	if c.Monitor != nil {
+		cs := kafka.Summarize(c.Monitor.Brokers())
+		c.Reporters.ReportClusterSummary(&cs)
+	}
+
+	co := c.Store.ConsumerOffsets()
+	c.Reporters.ReportConsumerOffsets(&co)
+}
+
+// Snapshot returns the current state of the cluster for alert evaluation.
+func (c *Cluster) Snapshot() alert.Snapshot {
+	s := alert.Snapshot{
+		Cluster:         c.Name,
+		BrokerMetadata:  c.Store.BrokerMetadata(),
+		ConsumerOffsets: c.Store.ConsumerOffsets(),
+		Time:            time.Now(),
+	}
+
+	if c.Monitor != nil {
+		s.Brokers = c.Monitor.Brokers()
+	}
+
+	return s
+}
+
+// Health returns the partition health of the cluster.
+func (c *Cluster) Health() store.ClusterHealth {
+	return store.EvaluateClusterHealth(c.Store.BrokerMetadata(), c.Store.TopicConfigs())
+}
+
+// IsHealthy checks the health of the cluster.
+func (c *Cluster) IsHealthy() bool {
+	if c.Monitor == nil || !c.Monitor.IsHealthy() {
+		return false
+	}
+
+	if c.FailOnOfflinePartitions && c.Health().Offline > 0 {
+		return false
+	}
+
+	return true
+}
+
+// Clusters represents a set of named clusters.
+type Clusters map[string]*Cluster
+
+// Names returns the sorted cluster names.
+func (cs Clusters) Names() []string {
+	names := make([]string, 0, len(cs))
+	for name := range cs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// each calls fn concurrently for every cluster and waits for them to return.
+func (cs Clusters) each(fn func(c *Cluster)) {
+	var wg sync.WaitGroup
+	for _, c := range cs {
+		wg.Add(1)
+		go func(c *Cluster) {
+			defer wg.Done()
+
+			fn(c)
+		}(c)
+	}
+	wg.Wait()
+}
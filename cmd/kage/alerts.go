@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/msales/kage/alert"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// alertsConfig represents the alerting configuration.
+type alertsConfig struct {
+	Rules []alert.Rule `yaml:"rules"`
+	Sinks []sinkConfig `yaml:"sinks"`
+}
+
+// sinkConfig represents the configuration of an alert notification sink.
+type sinkConfig struct {
+	Type     string   `yaml:"type"`
+	URL      string   `yaml:"url"`
+	Addr     string   `yaml:"addr"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+}
+
+// loadAlertsConfig loads the alerting configuration from the YAML configuration file.
+func loadAlertsConfig(c *cli.Context) (alertsConfig, error) {
+	path := c.String(FlagConfig)
+	if path == "" {
+		return alertsConfig{}, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return alertsConfig{}, err
+	}
+
+	var cfg struct {
+		Alerts alertsConfig `yaml:"alerts"`
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return alertsConfig{}, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	return cfg.Alerts, nil
+}
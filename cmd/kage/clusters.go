@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// clusterConfig represents the configuration of a monitored Kafka cluster.
+type clusterConfig struct {
+	Name          string     `yaml:"name"`
+	Brokers       []string   `yaml:"brokers"`
+	Version       string     `yaml:"version"`
+	OffsetsSource string     `yaml:"offsets-source"`
+	IncludeTopics []string   `yaml:"include-topics"`
+	IncludeGroups []string   `yaml:"include-groups"`
+	IgnoreTopics  []string   `yaml:"ignore-topics"`
+	IgnoreGroups  []string   `yaml:"ignore-groups"`
+	TLS           tlsConfig  `yaml:"tls"`
+	SASL          saslConfig `yaml:"sasl"`
+	StorePath     string     `yaml:"store-path"`
+}
+
+// tlsConfig represents the TLS configuration of a Kafka cluster.
+type tlsConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"ca-file"`
+	CertFile           string `yaml:"cert-file"`
+	KeyFile            string `yaml:"key-file"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify"`
+}
+
+// saslConfig represents the SASL configuration of a Kafka cluster.
+type saslConfig struct {
+	Mechanism string `yaml:"mechanism"`
+	User      string `yaml:"user"`
+	Password  string `yaml:"password"`
+}
+
+// clusterConfigFromFlags creates the configuration of the single cluster from the flags.
+func clusterConfigFromFlags(c *cli.Context) clusterConfig {
+	return clusterConfig{
+		Brokers:       c.StringSlice(FlagKafkaBrokers),
+		Version:       c.String(FlagKafkaVersion),
+		OffsetsSource: c.String(FlagKafkaOffsetsSource),
+		IncludeTopics: c.StringSlice(FlagKafkaIncludeTopics),
+		IncludeGroups: c.StringSlice(FlagKafkaIncludeGroups),
+		IgnoreTopics:  c.StringSlice(FlagKafkaIgnoreTopics),
+		IgnoreGroups:  c.StringSlice(FlagKafkaIgnoreGroups),
+		TLS: tlsConfig{
+			Enabled:            c.Bool(FlagKafkaTLS),
+			CAFile:             c.String(FlagKafkaTLSCAFile),
+			CertFile:           c.String(FlagKafkaTLSCertFile),
+			KeyFile:            c.String(FlagKafkaTLSKeyFile),
+			InsecureSkipVerify: c.Bool(FlagKafkaTLSInsecureSkipVerify),
+		},
+		SASL: saslConfig{
+			Mechanism: c.String(FlagKafkaSASLMechanism),
+			User:      c.String(FlagKafkaSASLUser),
+			Password:  c.String(FlagKafkaSASLPassword),
+		},
+		StorePath: c.String(FlagStorePath),
+	}
+}
+
+// loadClusterConfigs loads the cluster configurations from the YAML configuration file.
+//
+// The version, offsets source and store path of a cluster default to the flag values.
+func loadClusterConfigs(c *cli.Context) ([]clusterConfig, error) {
+	path := c.String(FlagConfig)
+	if path == "" {
+		return nil, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg struct {
+		Clusters []clusterConfig `yaml:"clusters"`
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	names := map[string]bool{}
+	for i, cluster := range cfg.Clusters {
+		if cluster.Name == "" {
+			return nil, errors.New("cluster name is required")
+		}
+		if names[cluster.Name] {
+			return nil, fmt.Errorf("duplicate cluster \"%s\"", cluster.Name)
+		}
+		names[cluster.Name] = true
+
+		if cluster.Version == "" {
+			cfg.Clusters[i].Version = c.String(FlagKafkaVersion)
+		}
+		if cluster.OffsetsSource == "" {
+			cfg.Clusters[i].OffsetsSource = c.String(FlagKafkaOffsetsSource)
+		}
+		if cluster.StorePath == "" {
+			cfg.Clusters[i].StorePath = clusterStorePath(c.String(FlagStorePath), cluster.Name)
+		}
+	}
+
+	return cfg.Clusters, nil
+}
+
+// clusterStorePath adds the cluster name to the store file path.
+func clusterStorePath(path, name string) string {
+	ext := filepath.Ext(path)
+
+	return strings.TrimSuffix(path, ext) + "-" + name + ext
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/Shopify/sarama"
 	"github.com/hamba/cmd"
 	"github.com/hamba/pkg/log"
-	"github.com/influxdata/influxdb/client/v2"
 	"github.com/msales/kage"
+	"github.com/msales/kage/alert"
 	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/reporter"
 	"github.com/msales/kage/store"
@@ -19,67 +25,281 @@ import (
 // Application =============================
 
 func newApplication(c *cmd.Context) (*kage.Application, error) {
+	app := kage.NewApplication()
+	app.Logger = c.Logger()
+	app.FailOnOfflinePartitions = c.Bool(FlagHealthFailOnOfflinePartitions)
+
+	alerter, err := newAlerter(c)
+	if err != nil {
+		return nil, err
+	}
+	app.Alerter = alerter
+
+	configs, err := loadClusterConfigs(c.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configs) == 0 {
+		cluster, err := newCluster(c, clusterConfigFromFlags(c.Context))
+		if err != nil {
+			return nil, err
+		}
+
+		app.Store = cluster.Store
+		app.Reporters = cluster.Reporters
+		app.Monitor = cluster.Monitor
+	} else {
+		// The first configured cluster is the default cluster.
+		app.Clusters = kage.Clusters{}
+		for i, cfg := range configs {
+			cluster, err := newCluster(c, cfg)
+			if err != nil {
+				app.Close()
+				return nil, fmt.Errorf("cluster %s: %w", cfg.Name, err)
+			}
+			app.Clusters[cfg.Name] = cluster
+
+			if i == 0 {
+				app.Name = cluster.Name
+				app.Store = cluster.Store
+				app.Reporters = cluster.Reporters
+				app.Monitor = cluster.Monitor
+			}
+		}
+	}
+
+	return app, nil
+}
+
+// newCluster creates a monitored cluster from its config.
+func newCluster(c *cmd.Context, cfg clusterConfig) (*kage.Cluster, error) {
 	logger := c.Logger()
 
-	memStore, err := store.New()
+	s, err := newStore(c.Context, cfg.StorePath)
 	if err != nil {
 		return nil, err
 	}
 
-	reporters, err := newReporters(c.Context, logger)
+	reporters, err := newReporters(c.Context, cfg.Name, logger)
 	if err != nil {
+		s.Close()
 		return nil, err
 	}
 
-	monitor, err := kafka.New(
-		kafka.Brokers(c.StringSlice(FlagKafkaBrokers)),
-		kafka.IgnoreTopics(c.StringSlice(FlagKafkaIgnoreTopics)),
-		kafka.IgnoreGroups(c.StringSlice(FlagKafkaIgnoreGroups)),
-		kafka.StateChannel(memStore.Channel()),
+	opts := []kafka.MonitorFunc{
+		kafka.Brokers(cfg.Brokers),
+		kafka.Version(cfg.Version),
+		kafka.OffsetsSource(cfg.OffsetsSource),
+		kafka.RefreshInterval(c.Duration(FlagKafkaRefreshInterval)),
+		kafka.IncludeTopics(cfg.IncludeTopics),
+		kafka.IncludeGroups(cfg.IncludeGroups),
+		kafka.IgnoreTopics(cfg.IgnoreTopics),
+		kafka.IgnoreGroups(cfg.IgnoreGroups),
+		kafka.StateChannel(s.Channel()),
 		kafka.Log(logger),
-	)
+	}
+
+	securityOpts, err := newKafkaSecurityOpts(cfg)
 	if err != nil {
+		reporters.Close()
+		s.Close()
 		return nil, err
 	}
+	opts = append(opts, securityOpts...)
 
-	app := kage.NewApplication()
-	app.Store = memStore
-	app.Reporters = reporters
-	app.Monitor = monitor
-	app.Logger = logger
+	monitor, err := kafka.New(opts...)
+	if err != nil {
+		reporters.Close()
+		s.Close()
+		return nil, err
+	}
 
-	return app, nil
+	return &kage.Cluster{
+		Name:      cfg.Name,
+		Store:     s,
+		Reporters: reporters,
+		Monitor:   monitor,
+
+		FailOnOfflinePartitions: c.Bool(FlagHealthFailOnOfflinePartitions),
+	}, nil
+}
+
+// newStore creates a store from the config.
+func newStore(c *cli.Context, path string) (kage.Store, error) {
+	opts := []store.StoreFunc{
+		store.CleanupInterval(c.Duration(FlagStoreCleanupInterval)),
+		store.Expiry(c.Duration(FlagStoreExpiry)),
+		store.AtRiskThreshold(c.Float64(FlagStoreAtRiskThreshold)),
+	}
+
+	switch name := c.String(FlagStore); name {
+	case "memory":
+		return store.New(opts...)
+
+	case "disk":
+		return store.NewDiskStore(path, opts...)
+
+	default:
+		return nil, fmt.Errorf("unknown store \"%s\"", name)
+	}
+}
+
+// newKafkaSecurityOpts creates the Kafka TLS and SASL options from the cluster config.
+func newKafkaSecurityOpts(cfg clusterConfig) ([]kafka.MonitorFunc, error) {
+	var opts []kafka.MonitorFunc
+
+	if cfg.TLS.Enabled {
+		tlsConfig, err := kafka.NewTLSConfig(
+			cfg.TLS.CAFile,
+			cfg.TLS.CertFile,
+			cfg.TLS.KeyFile,
+			cfg.TLS.InsecureSkipVerify,
+		)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kafka.TLS(tlsConfig))
+	}
+
+	if cfg.SASL.Mechanism != "" {
+		opts = append(opts, kafka.SASL(cfg.SASL.Mechanism, cfg.SASL.User, cfg.SASL.Password))
+	}
+
+	return opts, nil
+}
+
+// Alerts ==================================
+
+// newAlerter creates the alert engine from the config, or nil when no rules are configured.
+func newAlerter(c *cmd.Context) (kage.Alerter, error) {
+	cfg, err := loadAlertsConfig(c.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+
+	sinks := make([]alert.Sink, 0, len(cfg.Sinks))
+	for _, sc := range cfg.Sinks {
+		sink, err := newSink(sc)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return alert.New(
+		alert.Rules(cfg.Rules),
+		alert.Sinks(sinks...),
+		alert.Log(c.Logger()),
+	)
+}
+
+// newSink creates an alert notification sink from its config.
+func newSink(cfg sinkConfig) (alert.Sink, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch cfg.Type {
+	case "webhook":
+		return alert.NewWebhookSink(cfg.URL, client), nil
+
+	case "slack":
+		return alert.NewSlackSink(cfg.URL, client), nil
+
+	case "smtp":
+		host, _, err := net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid smtp address \"%s\": %w", cfg.Addr, err)
+		}
+
+		var auth smtp.Auth
+		if cfg.Username != "" {
+			auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+		}
+
+		return alert.NewSMTPSink(cfg.Addr, auth, cfg.From, cfg.To), nil
+
+	default:
+		return nil, fmt.Errorf("unknown sink \"%s\"", cfg.Type)
+	}
 }
 
 // Reporters ===============================
 
 // newReporters creates reporters from the config.
-func newReporters(c *cli.Context, logger log.Logger) (*kage.Reporters, error) {
+func newReporters(c *cli.Context, cluster string, logger log.Logger) (*kage.Reporters, error) {
 	rs := &kage.Reporters{}
 
 	for _, name := range c.StringSlice(FlagReporters) {
+		var (
+			r   kage.Reporter
+			err error
+		)
+
 		switch name {
+		case "graphite":
+			r, err = newGraphiteReporter(c, cluster, logger)
+
 		case "influx":
-			r, err := newInfluxReporter(c, logger)
-			if err != nil {
-				return nil, err
-			}
-			rs.Add(name, r)
+			r, err = newInfluxReporter(c, cluster, logger)
+
+		case "kafka":
+			r, err = newKafkaReporter(c, cluster, logger)
+
+		case "otlp":
+			r, err = newOTLPReporter(c, cluster, logger)
+
+		case "statsd":
+			r, err = newStatsDReporter(c, cluster, logger)
 
 		case "stdout":
-			r := reporter.NewConsoleReporter(os.Stdout)
-			rs.Add(name, r)
+			var opts []reporter.ConsoleReporterFunc
+			if cluster != "" {
+				opts = append(opts, reporter.Prefix("cluster:"+cluster+" "))
+			}
+
+			r = reporter.NewConsoleReporter(os.Stdout, opts...)
 
 		default:
-			return nil, fmt.Errorf("unknown reporter \"%s\"", name)
+			err = fmt.Errorf("unknown reporter \"%s\"", name)
 		}
+
+		if err != nil {
+			// Release the connections of the reporters already created.
+			rs.Close()
+			return nil, err
+		}
+
+		rs.Add(name, r)
 	}
 
 	return rs, nil
 }
 
+// newGraphiteReporter create a new Graphite reporter.
+func newGraphiteReporter(c *cli.Context, cluster string, logger log.Logger) (kage.Reporter, error) {
+	prefix := c.String(FlagGraphitePrefix)
+	if cluster != "" {
+		// Graphite has no tags, so the cluster is part of the path.
+		prefix = strings.TrimPrefix(prefix+"."+strings.Replace(cluster, ".", "_", -1), ".")
+	}
+
+	return reporter.NewGraphiteReporter(c.String(FlagGraphite),
+		reporter.GraphitePrefix(prefix),
+		reporter.GraphiteProtocol(c.String(FlagGraphiteProtocol)),
+		reporter.GraphiteBufferSize(c.Int(FlagGraphiteBufferSize)),
+		reporter.GraphiteLog(logger),
+	)
+}
+
 // newInfluxReporter create a new InfluxDB reporter.
-func newInfluxReporter(c *cli.Context, logger log.Logger) (kage.Reporter, error) {
+//
+// The InfluxDB 2.x write API is used when an org or token is configured,
+// and UDP when the DSN scheme is "udp".
+func newInfluxReporter(c *cli.Context, cluster string, logger log.Logger) (kage.Reporter, error) {
 	dsn, err := url.Parse(c.String(FlagInflux))
 	if err != nil {
 		return nil, err
@@ -92,27 +312,184 @@ func newInfluxReporter(c *cli.Context, logger log.Logger) (kage.Reporter, error)
 	addr := dsn.Scheme + "://" + dsn.Host
 	username := dsn.User.Username()
 	password, _ := dsn.User.Password()
+	db := strings.Trim(dsn.Path, "/")
 
-	influx, err := client.NewHTTPClient(client.HTTPConfig{
-		Addr:     addr,
-		Username: username,
-		Password: password,
-	})
+	tags, err := cmd.SplitTags(c.StringSlice(FlagInfluxTags), "=")
 	if err != nil {
 		return nil, err
 	}
-	db := strings.Trim(dsn.Path, "/")
+	if cluster != "" {
+		tags = append(tags, "cluster", cluster)
+	}
 
-	tags, err := cmd.SplitTags(c.StringSlice(FlagInfluxTags), "=")
+	var w reporter.LineWriter
+	switch {
+	case dsn.Scheme == "udp":
+		w, err = reporter.NewUDPLineWriter(dsn.Host, 0)
+		if err != nil {
+			return nil, err
+		}
+
+	case c.String(FlagInfluxOrg) != "" || c.String(FlagInfluxToken) != "":
+		bucket := c.String(FlagInfluxBucket)
+		if bucket == "" {
+			bucket = db
+		}
+
+		client := &http.Client{Timeout: 10 * time.Second}
+		w = reporter.NewInfluxV2Writer(addr, c.String(FlagInfluxOrg), bucket, c.String(FlagInfluxToken), client)
+
+	default:
+		client := &http.Client{Timeout: 10 * time.Second}
+		w = reporter.NewInfluxV1Writer(addr, db, c.String(FlagInfluxPolicy), username, password, client)
+	}
+
+	return reporter.NewLineProtocolReporter(w,
+		reporter.LineMetric(c.String(FlagInfluxMetric)),
+		reporter.LineTags(tags),
+		reporter.LineLog(logger),
+	), nil
+}
+
+// newKafkaReporter create a new Kafka topic reporter.
+func newKafkaReporter(c *cli.Context, cluster string, logger log.Logger) (kage.Reporter, error) {
+	version, err := sarama.ParseKafkaVersion(c.String(FlagKafkaReporterVersion))
 	if err != nil {
 		return nil, err
 	}
 
-	return reporter.NewInfluxReporter(influx,
-		reporter.Database(db),
-		reporter.Metric(c.String(FlagInfluxMetric)),
-		reporter.Policy(c.String(FlagInfluxPolicy)),
-		reporter.Tags(tags),
-		reporter.Log(logger),
-	), nil
+	compression, err := parseCompression(c.String(FlagKafkaReporterCompression))
+	if err != nil {
+		return nil, err
+	}
+
+	acks, err := parseAcks(c.String(FlagKafkaReporterAcks))
+	if err != nil {
+		return nil, err
+	}
+
+	config := sarama.NewConfig()
+	config.ClientID = "kage"
+	config.Version = version
+	config.Producer.Compression = compression
+	config.Producer.RequiredAcks = acks
+	config.Producer.Return.Successes = true
+
+	var tlsConfig *tls.Config
+	if c.Bool(FlagKafkaReporterTLS) {
+		tlsConfig, err = kafka.NewTLSConfig(
+			c.String(FlagKafkaReporterTLSCAFile),
+			c.String(FlagKafkaReporterTLSCertFile),
+			c.String(FlagKafkaReporterTLSKeyFile),
+			c.Bool(FlagKafkaReporterTLSInsecureSkipVerify),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = kafka.ConfigureSecurity(config, tlsConfig,
+		c.String(FlagKafkaReporterSASLMechanism),
+		c.String(FlagKafkaReporterSASLUser),
+		c.String(FlagKafkaReporterSASLPassword),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := sarama.NewSyncProducer(c.StringSlice(FlagKafkaReporterBrokers), config)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := reporter.NewKafkaReporter(producer, c.String(FlagKafkaReporterTopic),
+		reporter.KafkaFormat(c.String(FlagKafkaReporterFormat)),
+		reporter.KafkaCluster(cluster),
+		reporter.KafkaLog(logger),
+	)
+	if err != nil {
+		_ = producer.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// parseCompression parses a Kafka compression codec.
+func parseCompression(s string) (sarama.CompressionCodec, error) {
+	switch s {
+	case "none":
+		return sarama.CompressionNone, nil
+
+	case "gzip":
+		return sarama.CompressionGZIP, nil
+
+	case "snappy":
+		return sarama.CompressionSnappy, nil
+
+	case "lz4":
+		return sarama.CompressionLZ4, nil
+
+	case "zstd":
+		return sarama.CompressionZSTD, nil
+
+	default:
+		return sarama.CompressionNone, fmt.Errorf("unknown compression \"%s\"", s)
+	}
+}
+
+// parseAcks parses the acknowledgements required from Kafka.
+func parseAcks(s string) (sarama.RequiredAcks, error) {
+	switch s {
+	case "none":
+		return sarama.NoResponse, nil
+
+	case "leader":
+		return sarama.WaitForLocal, nil
+
+	case "all":
+		return sarama.WaitForAll, nil
+
+	default:
+		return sarama.NoResponse, fmt.Errorf("unknown acks \"%s\"", s)
+	}
+}
+
+// newOTLPReporter create a new OpenTelemetry OTLP reporter.
+func newOTLPReporter(c *cli.Context, cluster string, logger log.Logger) (kage.Reporter, error) {
+	headers, err := cmd.SplitTags(c.StringSlice(FlagOTLPHeaders), "=")
+	if err != nil {
+		return nil, err
+	}
+
+	resource := []string{"service.name", "kage", "service.version", version}
+	if cluster != "" {
+		resource = append(resource, "kafka.cluster.name", cluster)
+	}
+
+	return reporter.NewOTLPReporter(c.String(FlagOTLP),
+		reporter.OTLPProtocol(c.String(FlagOTLPProtocol)),
+		reporter.OTLPPrefix(c.String(FlagOTLPPrefix)),
+		reporter.OTLPResource(resource),
+		reporter.OTLPHeaders(headers),
+		reporter.OTLPLog(logger),
+	)
+}
+
+// newStatsDReporter create a new StatsD reporter.
+func newStatsDReporter(c *cli.Context, cluster string, logger log.Logger) (kage.Reporter, error) {
+	tags, err := cmd.SplitTags(c.StringSlice(FlagStatsDTags), "=")
+	if err != nil {
+		return nil, err
+	}
+	if cluster != "" {
+		tags = append(tags, "cluster", cluster)
+	}
+
+	return reporter.NewStatsDReporter(c.String(FlagStatsD),
+		reporter.StatsDFormat(c.String(FlagStatsDFormat)),
+		reporter.StatsDPrefix(c.String(FlagStatsDPrefix)),
+		reporter.StatsDTags(tags),
+		reporter.StatsDPacketSize(c.Int(FlagStatsDPacketSize)),
+		reporter.StatsDLog(logger),
+	)
 }
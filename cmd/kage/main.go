@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/hamba/cmd"
 	_ "github.com/joho/godotenv/autoload"
@@ -14,9 +15,32 @@ import (
 const (
 	FlagConfig = "config"
 
-	FlagKafkaBrokers      = "kafka.brokers"
-	FlagKafkaIgnoreTopics = "kafka.ignore-topics"
-	FlagKafkaIgnoreGroups = "kafka.ignore-groups"
+	FlagCollectInterval = "collect.interval"
+	FlagReportInterval  = "report.interval"
+
+	FlagKafkaBrokers         = "kafka.brokers"
+	FlagKafkaVersion         = "kafka.version"
+	FlagKafkaRefreshInterval = "kafka.refresh-interval"
+	FlagKafkaOffsetsSource   = "kafka.offsets-source"
+	FlagKafkaIncludeTopics   = "kafka.include-topics"
+	FlagKafkaIncludeGroups   = "kafka.include-groups"
+	FlagKafkaIgnoreTopics    = "kafka.ignore-topics"
+	FlagKafkaIgnoreGroups    = "kafka.ignore-groups"
+
+	FlagKafkaTLS                   = "kafka.tls"
+	FlagKafkaTLSCAFile             = "kafka.tls.ca-file"
+	FlagKafkaTLSCertFile           = "kafka.tls.cert-file"
+	FlagKafkaTLSKeyFile            = "kafka.tls.key-file"
+	FlagKafkaTLSInsecureSkipVerify = "kafka.tls.insecure-skip-verify"
+	FlagKafkaSASLMechanism         = "kafka.sasl.mechanism"
+	FlagKafkaSASLUser              = "kafka.sasl.user"
+	FlagKafkaSASLPassword          = "kafka.sasl.password"
+
+	FlagStore                = "store"
+	FlagStorePath            = "store.path"
+	FlagStoreCleanupInterval = "store.cleanup-interval"
+	FlagStoreExpiry          = "store.expiry"
+	FlagStoreAtRiskThreshold = "store.at-risk-threshold"
 
 	FlagReporters = "reporters"
 
@@ -24,8 +48,45 @@ const (
 	FlagInfluxMetric = "influx.metric"
 	FlagInfluxPolicy = "influx.policy"
 	FlagInfluxTags   = "influx.tags"
+	FlagInfluxOrg    = "influx.org"
+	FlagInfluxBucket = "influx.bucket"
+	FlagInfluxToken  = "influx.token"
+
+	FlagStatsD           = "statsd"
+	FlagStatsDFormat     = "statsd.format"
+	FlagStatsDPrefix     = "statsd.prefix"
+	FlagStatsDTags       = "statsd.tags"
+	FlagStatsDPacketSize = "statsd.max-packet-size"
+
+	FlagGraphite           = "graphite"
+	FlagGraphitePrefix     = "graphite.prefix"
+	FlagGraphiteProtocol   = "graphite.protocol"
+	FlagGraphiteBufferSize = "graphite.buffer-size"
+
+	FlagKafkaReporterBrokers     = "kafka-reporter.brokers"
+	FlagKafkaReporterVersion     = "kafka-reporter.version"
+	FlagKafkaReporterTopic       = "kafka-reporter.topic"
+	FlagKafkaReporterFormat      = "kafka-reporter.format"
+	FlagKafkaReporterCompression = "kafka-reporter.compression"
+	FlagKafkaReporterAcks        = "kafka-reporter.acks"
+
+	FlagKafkaReporterTLS                   = "kafka-reporter.tls"
+	FlagKafkaReporterTLSCAFile             = "kafka-reporter.tls.ca-file"
+	FlagKafkaReporterTLSCertFile           = "kafka-reporter.tls.cert-file"
+	FlagKafkaReporterTLSKeyFile            = "kafka-reporter.tls.key-file"
+	FlagKafkaReporterTLSInsecureSkipVerify = "kafka-reporter.tls.insecure-skip-verify"
+	FlagKafkaReporterSASLMechanism         = "kafka-reporter.sasl.mechanism"
+	FlagKafkaReporterSASLUser              = "kafka-reporter.sasl.user"
+	FlagKafkaReporterSASLPassword          = "kafka-reporter.sasl.password"
+
+	FlagOTLP         = "otlp"
+	FlagOTLPProtocol = "otlp.protocol"
+	FlagOTLPPrefix   = "otlp.prefix"
+	FlagOTLPHeaders  = "otlp.headers"
 
 	FlagServer = "server"
+
+	FlagHealthFailOnOfflinePartitions = "health.fail-on-offline-partitions"
 )
 
 var version = "¯\\_(ツ)_/¯"
@@ -39,32 +100,144 @@ var agentCommand = &cli.Command{
 			Usage: "The YAML configuration file to configure ",
 		},
 
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:    FlagCollectInterval,
+			Value:   30 * time.Second,
+			Usage:   "Specify the interval at which the Kafka state is collected",
+			EnvVars: []string{"KAGE_COLLECT_INTERVAL"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:    FlagReportInterval,
+			Value:   60 * time.Second,
+			Usage:   "Specify the interval at which the state is reported",
+			EnvVars: []string{"KAGE_REPORT_INTERVAL"},
+		}),
+
 		&cli.StringSliceFlag{
 			Name:    FlagKafkaBrokers,
 			Usage:   "Specify the Kafka seed brokers",
 			EnvVars: []string{"KAGE_KAFKA_BROKERS"},
 		},
+		&cli.StringFlag{
+			Name:    FlagKafkaVersion,
+			Value:   "0.10.1.0",
+			Usage:   `"Specify the Kafka protocol version (e.g. "2.1.0", or "auto" to negotiate with the brokers)"`,
+			EnvVars: []string{"KAGE_KAFKA_VERSION"},
+		},
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:    FlagKafkaRefreshInterval,
+			Value:   2 * time.Minute,
+			Usage:   "Specify the interval at which the Kafka metadata is refreshed",
+			EnvVars: []string{"KAGE_KAFKA_REFRESH_INTERVAL"},
+		}),
+		&cli.StringFlag{
+			Name:    FlagKafkaOffsetsSource,
+			Value:   "fetch",
+			Usage:   `"Specify the source of the consumer offsets (options: "fetch", "stream")"`,
+			EnvVars: []string{"KAGE_KAFKA_OFFSETS_SOURCE"},
+		},
+		&cli.StringSliceFlag{
+			Name:    FlagKafkaIncludeTopics,
+			Usage:   "Specify the Kafka topic patterns to monitor (may contain wildcards, or be a /regexp/)",
+			EnvVars: []string{"KAGE_KAFKA_INCLUDE_TOPICS"},
+		},
+		&cli.StringSliceFlag{
+			Name:    FlagKafkaIncludeGroups,
+			Usage:   "Specify the Kafka group patterns to monitor (may contain wildcards, or be a /regexp/)",
+			EnvVars: []string{"KAGE_KAFKA_INCLUDE_GROUPS"},
+		},
 		&cli.StringSliceFlag{
 			Name:    FlagKafkaIgnoreTopics,
-			Usage:   "Specify the Kafka topic patterns to ignore (may contain wildcards)",
+			Usage:   "Specify the Kafka topic patterns to ignore (may contain wildcards, or be a /regexp/)",
 			EnvVars: []string{"KAGE_KAFKA_IGNORE_TOPICS"},
 		},
 		&cli.StringSliceFlag{
 			Name:    FlagKafkaIgnoreGroups,
-			Usage:   "Specify the Kafka group patterns to ignore (may contain wildcards)",
+			Usage:   "Specify the Kafka group patterns to ignore (may contain wildcards, or be a /regexp/)",
 			EnvVars: []string{"KAGE_KAFKA_IGNORE_GROUPS"},
 		},
+		&cli.BoolFlag{
+			Name:    FlagKafkaTLS,
+			Usage:   "Connect to the Kafka brokers using TLS",
+			EnvVars: []string{"KAGE_KAFKA_TLS"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaTLSCAFile,
+			Usage:   "Specify the CA certificate file used to verify the Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_TLS_CA_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaTLSCertFile,
+			Usage:   "Specify the client certificate file used to connect to the Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_TLS_CERT_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaTLSKeyFile,
+			Usage:   "Specify the client key file used to connect to the Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_TLS_KEY_FILE"},
+		},
+		&cli.BoolFlag{
+			Name:    FlagKafkaTLSInsecureSkipVerify,
+			Usage:   "Skip the verification of the Kafka broker certificates",
+			EnvVars: []string{"KAGE_KAFKA_TLS_INSECURE_SKIP_VERIFY"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaSASLMechanism,
+			Usage:   `"Specify the SASL mechanism used to authenticate with the Kafka brokers (options: "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512")"`,
+			EnvVars: []string{"KAGE_KAFKA_SASL_MECHANISM"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaSASLUser,
+			Usage:   "Specify the SASL user used to authenticate with the Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_SASL_USER"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaSASLPassword,
+			Usage:   "Specify the SASL password used to authenticate with the Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_SASL_PASSWORD"},
+		},
+
+		&cli.StringFlag{
+			Name:    FlagStore,
+			Value:   "memory",
+			Usage:   `"Specify the store to use (options: "memory", "disk")"`,
+			EnvVars: []string{"KAGE_STORE"},
+		},
+		&cli.StringFlag{
+			Name:    FlagStorePath,
+			Value:   "kage.db",
+			Usage:   "Specify the database file of the disk store",
+			EnvVars: []string{"KAGE_STORE_PATH"},
+		},
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:    FlagStoreCleanupInterval,
+			Value:   time.Hour,
+			Usage:   "Specify the interval at which expired consumer offsets and groups are cleaned from the store",
+			EnvVars: []string{"KAGE_STORE_CLEANUP_INTERVAL"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:    FlagStoreExpiry,
+			Value:   24 * time.Hour,
+			Usage:   "Specify the age after which consumer offsets and groups are removed from the store",
+			EnvVars: []string{"KAGE_STORE_EXPIRY"},
+		}),
+		altsrc.NewFloat64Flag(&cli.Float64Flag{
+			Name:    FlagStoreAtRiskThreshold,
+			Value:   0.1,
+			Usage:   "Specify the fraction of the retained messages of a partition under which a lagging consumer is at risk of data loss",
+			EnvVars: []string{"KAGE_STORE_AT_RISK_THRESHOLD"},
+		}),
 
 		&cli.StringSliceFlag{
 			Name:    FlagReporters,
 			Value:   cli.NewStringSlice("stdout"),
-			Usage:   `"Specify the reporters to use (options: "influx", "stdout")"`,
+			Usage:   `"Specify the reporters to use (options: "graphite", "influx", "kafka", "otlp", "statsd", "stdout")"`,
 			EnvVars: []string{"KAGE_REPORTERS"},
 		},
 
 		&cli.StringFlag{
 			Name:    FlagInflux,
-			Usage:   `"Specify the InfluxDB DSN (e.g. "http://user:pass@ip:port/database")"`,
+			Usage:   `"Specify the InfluxDB DSN (e.g. "http://user:pass@ip:port/database" or "udp://ip:port")"`,
 			EnvVars: []string{"KAGE_INFLUX"},
 		},
 		&cli.StringFlag{
@@ -83,12 +256,185 @@ var agentCommand = &cli.Command{
 			Usage:   `"Specify additions tags to add to all metrics (e.g. "tag1=value")"`,
 			EnvVars: []string{"KAGE_INFLUX_TAGS"},
 		},
+		&cli.StringFlag{
+			Name:    FlagInfluxOrg,
+			Usage:   "Specify the InfluxDB 2.x organization",
+			EnvVars: []string{"KAGE_INFLUX_ORG"},
+		},
+		&cli.StringFlag{
+			Name:    FlagInfluxBucket,
+			Usage:   "Specify the InfluxDB 2.x bucket, defaulting to the DSN database",
+			EnvVars: []string{"KAGE_INFLUX_BUCKET"},
+		},
+		&cli.StringFlag{
+			Name:    FlagInfluxToken,
+			Usage:   "Specify the InfluxDB 2.x API token",
+			EnvVars: []string{"KAGE_INFLUX_TOKEN"},
+		},
+
+		&cli.StringFlag{
+			Name:    FlagStatsD,
+			Usage:   `"Specify the StatsD address (e.g. "127.0.0.1:8125")"`,
+			EnvVars: []string{"KAGE_STATSD"},
+		},
+		&cli.StringFlag{
+			Name:    FlagStatsDFormat,
+			Value:   "statsd",
+			Usage:   `"Specify the StatsD metric format (options: "statsd", "dogstatsd")"`,
+			EnvVars: []string{"KAGE_STATSD_FORMAT"},
+		},
+		&cli.StringFlag{
+			Name:    FlagStatsDPrefix,
+			Value:   "kafka",
+			Usage:   "Specify the StatsD metric name prefix",
+			EnvVars: []string{"KAGE_STATSD_PREFIX"},
+		},
+		&cli.StringSliceFlag{
+			Name:    FlagStatsDTags,
+			Usage:   `"Specify additions tags to add to all metrics (e.g. "tag1=value")"`,
+			EnvVars: []string{"KAGE_STATSD_TAGS"},
+		},
+		&cli.IntFlag{
+			Name:    FlagStatsDPacketSize,
+			Value:   1432,
+			Usage:   "Specify the maximum size of a StatsD packet in bytes",
+			EnvVars: []string{"KAGE_STATSD_MAX_PACKET_SIZE"},
+		},
+
+		&cli.StringFlag{
+			Name:    FlagGraphite,
+			Usage:   `"Specify the Graphite address (e.g. "127.0.0.1:2003")"`,
+			EnvVars: []string{"KAGE_GRAPHITE"},
+		},
+		&cli.StringFlag{
+			Name:    FlagGraphitePrefix,
+			Value:   "kafka",
+			Usage:   "Specify the Graphite metric path prefix",
+			EnvVars: []string{"KAGE_GRAPHITE_PREFIX"},
+		},
+		&cli.StringFlag{
+			Name:    FlagGraphiteProtocol,
+			Value:   "plaintext",
+			Usage:   `"Specify the Graphite protocol (options: "plaintext", "pickle")"`,
+			EnvVars: []string{"KAGE_GRAPHITE_PROTOCOL"},
+		},
+		&cli.IntFlag{
+			Name:    FlagGraphiteBufferSize,
+			Value:   10000,
+			Usage:   "Specify the maximum number of metrics buffered while Graphite is unreachable",
+			EnvVars: []string{"KAGE_GRAPHITE_BUFFER_SIZE"},
+		},
+
+		&cli.StringSliceFlag{
+			Name:    FlagKafkaReporterBrokers,
+			Usage:   "Specify the Kafka seed brokers to produce the reports to",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_BROKERS"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterVersion,
+			Value:   "1.0.0",
+			Usage:   "Specify the Kafka protocol version of the report producer",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_VERSION"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterTopic,
+			Value:   "kage",
+			Usage:   "Specify the Kafka topic to produce the reports to",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_TOPIC"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterFormat,
+			Value:   "json",
+			Usage:   `"Specify the report record format (options: "json", "avro")"`,
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_FORMAT"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterCompression,
+			Value:   "none",
+			Usage:   `"Specify the report compression (options: "none", "gzip", "snappy", "lz4", "zstd")"`,
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_COMPRESSION"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterAcks,
+			Value:   "all",
+			Usage:   `"Specify the acknowledgements required for the reports (options: "none", "leader", "all")"`,
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_ACKS"},
+		},
+		&cli.BoolFlag{
+			Name:    FlagKafkaReporterTLS,
+			Usage:   "Connect to the report Kafka brokers using TLS",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_TLS"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterTLSCAFile,
+			Usage:   "Specify the CA certificate file used to verify the report Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_TLS_CA_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterTLSCertFile,
+			Usage:   "Specify the client certificate file used to connect to the report Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_TLS_CERT_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterTLSKeyFile,
+			Usage:   "Specify the client key file used to connect to the report Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_TLS_KEY_FILE"},
+		},
+		&cli.BoolFlag{
+			Name:    FlagKafkaReporterTLSInsecureSkipVerify,
+			Usage:   "Skip the verification of the report Kafka broker certificates",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_TLS_INSECURE_SKIP_VERIFY"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterSASLMechanism,
+			Usage:   `"Specify the SASL mechanism used to authenticate with the report Kafka brokers (options: "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512")"`,
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_SASL_MECHANISM"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterSASLUser,
+			Usage:   "Specify the SASL user used to authenticate with the report Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_SASL_USER"},
+		},
+		&cli.StringFlag{
+			Name:    FlagKafkaReporterSASLPassword,
+			Usage:   "Specify the SASL password used to authenticate with the report Kafka brokers",
+			EnvVars: []string{"KAGE_KAFKA_REPORTER_SASL_PASSWORD"},
+		},
+
+		&cli.StringFlag{
+			Name:    FlagOTLP,
+			Usage:   `"Specify the OpenTelemetry collector endpoint (e.g. "http://ip:4318")"`,
+			EnvVars: []string{"KAGE_OTLP"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOTLPProtocol,
+			Value:   "http/protobuf",
+			Usage:   `"Specify the OTLP protocol (options: "grpc", "http/protobuf")"`,
+			EnvVars: []string{"KAGE_OTLP_PROTOCOL"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOTLPPrefix,
+			Value:   "kafka",
+			Usage:   "Specify the OTLP metric name prefix",
+			EnvVars: []string{"KAGE_OTLP_PREFIX"},
+		},
+		&cli.StringSliceFlag{
+			Name:    FlagOTLPHeaders,
+			Usage:   `"Specify headers to send with every export (e.g. "Authorization=Bearer token")"`,
+			EnvVars: []string{"KAGE_OTLP_HEADERS"},
+		},
 
 		&cli.BoolFlag{
 			Name:    FlagServer,
 			Usage:   "Start the http server",
 			EnvVars: []string{"KAGE_SERVER"},
 		},
+
+		&cli.BoolFlag{
+			Name:    FlagHealthFailOnOfflinePartitions,
+			Usage:   "Fail the health check when a cluster has offline partitions",
+			EnvVars: []string{"KAGE_HEALTH_FAIL_ON_OFFLINE_PARTITIONS"},
+		},
 	}.Merge(cmd.LogFlags, cmd.ServerFlags),
 	Action: runServer,
 }
@@ -14,6 +14,10 @@ import (
 )
 
 func runServer(c *cli.Context) error {
+	if c.Duration(FlagCollectInterval) <= 0 || c.Duration(FlagReportInterval) <= 0 {
+		return errors.New("collect and report intervals must be positive")
+	}
+
 	ctx, err := cmd.NewContext(c)
 	if err != nil {
 		return err
@@ -25,7 +29,7 @@ func runServer(c *cli.Context) error {
 	}
 	defer app.Close()
 
-	monitorTicker := time.NewTicker(30 * time.Second)
+	monitorTicker := time.NewTicker(c.Duration(FlagCollectInterval))
 	defer monitorTicker.Stop()
 	go func() {
 		for range monitorTicker.C {
@@ -33,7 +37,7 @@ func runServer(c *cli.Context) error {
 		}
 	}()
 
-	reportTicker := time.NewTicker(60 * time.Second)
+	reportTicker := time.NewTicker(c.Duration(FlagReportInterval))
 	defer reportTicker.Stop()
 	go func() {
 		for range reportTicker.C {
@@ -1,6 +1,7 @@
 package kage
 
 import (
+	"github.com/msales/kage/alert"
 	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/store"
 )
@@ -19,6 +20,21 @@ type Store interface {
 	// BrokerMetadata returns a snapshot of the current broker metadata.
 	BrokerMetadata() store.BrokerMetadata
 
+	// ConsumerGroups returns a snapshot of the current consumer group descriptions.
+	ConsumerGroups() store.ConsumerGroups
+
+	// TopicConfigs returns a snapshot of the current topic configurations.
+	TopicConfigs() store.TopicConfigs
+
+	// BrokerLogDirs returns a snapshot of the current broker log directories.
+	BrokerLogDirs() store.BrokerLogDirs
+
+	// BrokerHistory returns the offset history of a topic, indexed by partition.
+	BrokerHistory(topic string, r store.HistoryRange) [][]store.HistoryPoint
+
+	// ConsumerHistory returns the offset and lag history of a consumer group on a topic, indexed by partition.
+	ConsumerHistory(group, topic string, r store.HistoryRange) [][]store.HistoryPoint
+
 	// Channel get the offset channel.
 	Channel() chan interface{}
 
@@ -40,3 +56,12 @@ type Monitor interface {
 	// Close gracefully stops the Monitor client.
 	Close()
 }
+
+// Alerter represents an alert rule evaluator.
+type Alerter interface {
+	// Evaluate evaluates the alert rules against the state of a cluster.
+	Evaluate(s alert.Snapshot)
+
+	// Alerts returns the pending and firing alerts.
+	Alerts() []alert.Alert
+}
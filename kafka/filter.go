@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ryanuber/go-glob"
+)
+
+// pattern represents a glob or regular expression pattern.
+//
+// Patterns wrapped in slashes (e.g. "/^orders-.*$/") are regular expressions,
+// all other patterns are globs that may contain wildcards.
+type pattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// newPattern compiles a pattern.
+func newPattern(p string) (pattern, error) {
+	if len(p) < 2 || !strings.HasPrefix(p, "/") || !strings.HasSuffix(p, "/") {
+		return pattern{glob: p}, nil
+	}
+
+	re, err := regexp.Compile(p[1 : len(p)-1])
+	if err != nil {
+		return pattern{}, fmt.Errorf("kafka: invalid pattern %q: %w", p, err)
+	}
+
+	return pattern{re: re}, nil
+}
+
+// Match determines if the subject matches the pattern.
+func (p pattern) Match(subject string) bool {
+	if p.re != nil {
+		return p.re.MatchString(subject)
+	}
+
+	return glob.Glob(p.glob, subject)
+}
+
+// filter determines which subjects are monitored.
+//
+// When include patterns are given, only subjects matching at least one of them
+// are monitored. Ignore patterns always take precedence over include patterns.
+type filter struct {
+	include []pattern
+	ignore  []pattern
+}
+
+// newFilter compiles a filter from include and ignore patterns.
+func newFilter(include, ignore []string) (*filter, error) {
+	f := &filter{}
+
+	var err error
+	if f.include, err = compilePatterns(include); err != nil {
+		return nil, err
+	}
+	if f.ignore, err = compilePatterns(ignore); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Allow determines if the subject should be monitored.
+func (f *filter) Allow(subject string) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.include) > 0 && !matchAny(f.include, subject) {
+		return false
+	}
+
+	return !matchAny(f.ignore, subject)
+}
+
+// compilePatterns compiles a list of patterns.
+func compilePatterns(patterns []string) ([]pattern, error) {
+	compiled := make([]pattern, 0, len(patterns))
+	for _, p := range patterns {
+		pat, err := newPattern(p)
+		if err != nil {
+			return nil, err
+		}
+
+		compiled = append(compiled, pat)
+	}
+
+	return compiled, nil
+}
+
+// matchAny determines if the subject matches any of the provided patterns.
+func matchAny(patterns []pattern, subject string) bool {
+	for _, p := range patterns {
+		if p.Match(subject) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPattern_InvalidRegexp(t *testing.T) {
+	_, err := newPattern("/foo(/")
+
+	assert.Error(t, err)
+}
+
+func TestPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{pattern: "foo", subject: "foo", want: true},
+		{pattern: "foo", subject: "foobar", want: false},
+		{pattern: "foo*", subject: "foobar", want: true},
+		{pattern: "/^foo-[0-9]+$/", subject: "foo-12", want: true},
+		{pattern: "/^foo-[0-9]+$/", subject: "foo-bar", want: false},
+		{pattern: "/", subject: "/", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.subject, func(t *testing.T) {
+			p, err := newPattern(tt.pattern)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.want, p.Match(tt.subject))
+		})
+	}
+}
+
+func TestNewFilter_InvalidPattern(t *testing.T) {
+	_, err := newFilter([]string{"/foo(/"}, nil)
+	assert.Error(t, err)
+
+	_, err = newFilter(nil, []string{"/foo(/"})
+	assert.Error(t, err)
+}
+
+func TestFilter_Allow(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		ignore  []string
+		subject string
+		want    bool
+	}{
+		{name: "no patterns", subject: "foo", want: true},
+		{name: "included", include: []string{"foo*"}, subject: "foobar", want: true},
+		{name: "not included", include: []string{"foo*"}, subject: "bar", want: false},
+		{name: "ignored", ignore: []string{"/^foo/"}, subject: "foobar", want: false},
+		{name: "not ignored", ignore: []string{"/^foo/"}, subject: "bar", want: true},
+		{name: "ignore takes precedence", include: []string{"foo*"}, ignore: []string{"foobar"}, subject: "foobar", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newFilter(tt.include, tt.ignore)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.want, f.Allow(tt.subject))
+		})
+	}
+}
+
+func TestFilter_AllowNil(t *testing.T) {
+	var f *filter
+
+	assert.True(t, f.Allow("foo"))
+}
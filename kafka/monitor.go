@@ -1,46 +1,123 @@
 package kafka
 
 import (
+	"crypto/tls"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/hamba/pkg/log"
 	"github.com/msales/kage/store"
-	"github.com/ryanuber/go-glob"
 )
 
+// topicConfigNames are the topic configs collected from the cluster.
+var topicConfigNames = []string{
+	store.ConfigCleanupPolicy,
+	store.ConfigRetentionMs,
+	store.ConfigRetentionBytes,
+	store.ConfigMinInsyncReplicas,
+	store.ConfigMaxMessageBytes,
+	store.ConfigSegmentBytes,
+	store.ConfigUncleanLeaderElection,
+}
+
 // Broker represents a Kafka Broker.
 type Broker struct {
-	ID        int32
-	Connected bool
+	ID         int32
+	Connected  bool
+	Address    string
+	Rack       string
+	Controller bool
+	Leaders    int       // The number of partitions led by the broker.
+	Replicas   int       // The number of partition replicas on the broker.
+	LastSeen   time.Time // The last time a collection got a response from the broker.
+}
+
+// brokerMetadata represents the broker state derived from the cluster metadata.
+type brokerMetadata struct {
+	controllerID int32
+	leaders      map[int32]int
+	replicas     map[int32]int
+}
+
+type saslConfig struct {
+	mechanism string
+	user      string
+	password  string
 }
 
 // Monitor represents a Kafka cluster connection.
 type Monitor struct {
 	brokers []string
-
-	client        sarama.Client
-	refreshTicker *time.Ticker
-	stateCh       chan interface{}
-
-	ignoreTopics []string
-	ignoreGroups []string
+	version string
+	tls     *tls.Config
+	sasl    *saslConfig
+
+	client          sarama.Client
+	refreshInterval time.Duration
+	refreshTicker   *time.Ticker
+	stateCh         chan interface{}
+
+	brokersMu sync.Mutex
+	metadata  *brokerMetadata
+	lastSeen  map[int32]time.Time
+
+	offsetsSource     string
+	offsetsConsumer   sarama.Consumer
+	offsetsPartitions []sarama.PartitionConsumer
+
+	includeTopics []string
+	includeGroups []string
+	ignoreTopics  []string
+	ignoreGroups  []string
+	topicFilter   *filter
+	groupFilter   *filter
 
 	log log.Logger
 }
 
 // New creates and returns a new Monitor for a Kafka cluster.
 func New(opts ...MonitorFunc) (*Monitor, error) {
-	monitor := &Monitor{}
+	monitor := &Monitor{
+		refreshInterval: 2 * time.Minute,
+		offsetsSource:   OffsetsSourceFetch,
+	}
 
 	for _, o := range opts {
 		o(monitor)
 	}
 
-	config := sarama.NewConfig()
-	config.Version = sarama.V0_10_1_0
+	if monitor.offsetsSource != OffsetsSourceFetch && monitor.offsetsSource != OffsetsSourceStream {
+		return nil, fmt.Errorf("kafka: unknown offsets source \"%s\"", monitor.offsetsSource)
+	}
+
+	var err error
+	if monitor.topicFilter, err = newFilter(monitor.includeTopics, monitor.ignoreTopics); err != nil {
+		return nil, err
+	}
+	if monitor.groupFilter, err = newFilter(monitor.includeGroups, monitor.ignoreGroups); err != nil {
+		return nil, err
+	}
+
+	config, err := monitor.newConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if monitor.version == VersionAuto {
+		version, err := negotiateVersion(monitor.brokers, config)
+		if err != nil {
+			return nil, err
+		}
+		config.Version = version
+		if config.Net.SASL.Enable {
+			config.Net.SASL.Version = saslHandshakeVersion(config)
+		}
+
+		monitor.log.Info(fmt.Sprintf("monitor: negotiated kafka version %s", version))
+	}
 
 	kafka, err := sarama.NewClient(monitor.brokers, config)
 	if err != nil {
@@ -48,37 +125,114 @@ func New(opts ...MonitorFunc) (*Monitor, error) {
 	}
 	monitor.client = kafka
 
-	monitor.refreshTicker = time.NewTicker(2 * time.Minute)
+	if monitor.offsetsSource == OffsetsSourceStream {
+		if err := monitor.startOffsetsStream(); err != nil {
+			_ = kafka.Close()
+			return nil, err
+		}
+	}
+
+	monitor.refreshTicker = time.NewTicker(monitor.refreshInterval)
 	go func() {
 		for range monitor.refreshTicker.C {
 			monitor.refreshMetadata()
+			monitor.getTopicConfigs()
 		}
 	}()
 
 	// Collect initial information
 	go monitor.Collect()
+	go monitor.getTopicConfigs()
 
 	return monitor, nil
 }
 
-// Brokers returns a list of Kafka brokers.
+// newConfig creates the sarama client configuration.
+func (m *Monitor) newConfig() (*sarama.Config, error) {
+	config := sarama.NewConfig()
+
+	if m.version != VersionAuto {
+		version, err := parseVersion(m.version)
+		if err != nil {
+			return nil, err
+		}
+		config.Version = version
+	}
+
+	var mechanism, user, password string
+	if m.sasl != nil {
+		mechanism, user, password = m.sasl.mechanism, m.sasl.user, m.sasl.password
+	}
+	if err := ConfigureSecurity(config, m.tls, mechanism, user, password); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Brokers returns a list of Kafka brokers ordered by ID.
 func (m *Monitor) Brokers() []Broker {
+	m.brokersMu.Lock()
+	defer m.brokersMu.Unlock()
+
 	brokers := []Broker{}
 	for _, b := range m.client.Brokers() {
 		connected, _ := b.Connected()
-		brokers = append(brokers, Broker{
+
+		broker := Broker{
 			ID:        b.ID(),
 			Connected: connected,
-		})
+			Address:   b.Addr(),
+			Rack:      b.Rack(),
+			LastSeen:  m.lastSeen[b.ID()],
+		}
+		if m.metadata != nil {
+			broker.Controller = m.metadata.controllerID == b.ID()
+			broker.Leaders = m.metadata.leaders[b.ID()]
+			broker.Replicas = m.metadata.replicas[b.ID()]
+		}
+
+		brokers = append(brokers, broker)
 	}
+	sort.Slice(brokers, func(i, j int) bool {
+		return brokers[i].ID < brokers[j].ID
+	})
+
 	return brokers
 }
 
+// markSeen records that the broker responded to a collection request.
+func (m *Monitor) markSeen(id int32) {
+	m.brokersMu.Lock()
+	defer m.brokersMu.Unlock()
+
+	if m.lastSeen == nil {
+		m.lastSeen = make(map[int32]time.Time)
+	}
+
+	m.lastSeen[id] = time.Now()
+}
+
+// ProtocolVersion returns the Kafka protocol version in use.
+func (m *Monitor) ProtocolVersion() sarama.KafkaVersion {
+	return m.client.Config().Version
+}
+
 // Collect collects the state of Kafka.
 func (m *Monitor) Collect() {
 	m.getBrokerOffsets()
 	m.getBrokerMetadata()
-	m.getConsumerOffsets()
+	m.getLogDirs()
+
+	if m.offsetsSource == OffsetsSourceStream {
+		// The consumer offsets and groups are streamed as they are committed.
+		return
+	}
+
+	// The groups are listed once for both their offsets and descriptions.
+	groups := m.getGroups()
+	m.getConsumerOffsets(groups)
+	m.getConsumerGroups(groups)
 }
 
 // IsHealthy checks the health of the Kafka cluster.
@@ -96,6 +250,8 @@ func (m *Monitor) IsHealthy() bool {
 func (m *Monitor) Close() {
 	// Stop the offset ticker
 	m.refreshTicker.Stop()
+
+	m.stopOffsetsStream()
 }
 
 // getTopics gets the topics for the Kafka cluster.
@@ -109,6 +265,10 @@ func (m *Monitor) getTopics() map[string]int {
 
 	topicMap := make(map[string]int)
 	for _, topic := range topics {
+		if !m.topicFilter.Allow(topic) {
+			continue
+		}
+
 		partitions, _ := m.client.Partitions(topic)
 
 		topicMap[topic] = len(partitions)
@@ -132,10 +292,6 @@ func (m *Monitor) getBrokerOffsets() {
 	brokers := make(map[int32]*sarama.Broker)
 
 	for topic, partitions := range topicMap {
-		if containsString(m.ignoreTopics, topic) {
-			continue
-		}
-
 		for i := 0; i < partitions; i++ {
 			broker, err := m.client.Leader(topic, int32(i))
 			if err != nil {
@@ -167,6 +323,7 @@ func (m *Monitor) getBrokerOffsets() {
 
 			return
 		}
+		m.markSeen(brokerID)
 
 		ts := time.Now().Unix() * 1000
 		for topic, partitions := range response.Blocks {
@@ -225,15 +382,23 @@ func (m *Monitor) getBrokerMetadata() {
 		return
 	}
 
-	response, err := broker.GetMetadata(&sarama.MetadataRequest{})
+	request := &sarama.MetadataRequest{}
+	if m.ProtocolVersion().IsAtLeast(sarama.V0_10_0_0) {
+		// The controller is only returned from version 1.
+		request.Version = 1
+	}
+
+	response, err := broker.GetMetadata(request)
 	if err != nil {
 		m.log.Error(fmt.Sprintf("monitor: cannot get metadata: %v", err))
 		return
 	}
 
+	m.setBrokerMetadata(broker.ID(), response)
+
 	ts := time.Now().Unix() * 1000
 	for _, topic := range response.Topics {
-		if containsString(m.ignoreTopics, topic.Name) {
+		if !m.topicFilter.Allow(topic.Name) {
 			continue
 		}
 		if topic.Err != sarama.ErrNoError {
@@ -261,56 +426,213 @@ func (m *Monitor) getBrokerMetadata() {
 	}
 }
 
-// getConsumerOffsets gets all the consumer offsets and send them to the store.
-func (m *Monitor) getConsumerOffsets() {
-	topicMap := m.getTopics()
-	requests := make(map[int32]map[string]*sarama.OffsetFetchRequest)
-	coordinators := make(map[int32]*sarama.Broker)
+// getLogDirs gets the log directories of all brokers and sends them to the store.
+func (m *Monitor) getLogDirs() {
+	if !m.ProtocolVersion().IsAtLeast(sarama.V1_0_0_0) {
+		// Describing log dirs requires Kafka 1.0 or later.
+		return
+	}
 
-	brokers := m.client.Brokers()
-	for _, broker := range brokers {
-		if ok, err := broker.Connected(); !ok {
-			if err != nil {
-				m.log.Error(fmt.Sprintf("monitor: failed to connect to broker broker %v: %v", broker.ID(), err))
-				continue
-			}
+	var wg sync.WaitGroup
+	getLogDirs := func(broker *sarama.Broker) {
+		defer wg.Done()
 
+		if ok, _ := broker.Connected(); !ok {
 			if err := broker.Open(m.client.Config()); err != nil {
 				m.log.Error(fmt.Sprintf("monitor: failed to connect to broker broker %v: %v", broker.ID(), err))
-				continue
+				return
 			}
 		}
 
-		groups, err := broker.ListGroups(&sarama.ListGroupsRequest{})
+		response, err := broker.DescribeLogDirs(&sarama.DescribeLogDirsRequest{})
 		if err != nil {
-			m.log.Error(fmt.Sprintf("monitor: cannot fetch consumer groups on broker %v: %v", broker.ID(), err))
-			continue
+			m.log.Error(fmt.Sprintf("monitor: cannot describe log dirs on broker %v: %v", broker.ID(), err))
+			return
 		}
+		m.markSeen(broker.ID())
 
-		for group := range groups.Groups {
-			if containsString(m.ignoreGroups, group) {
+		desc := &store.BrokerLogDirsDescription{
+			Broker:    broker.ID(),
+			LogDirs:   make([]store.LogDir, 0, len(response.LogDirs)),
+			Timestamp: time.Now().Unix() * 1000,
+		}
+		for _, dir := range response.LogDirs {
+			if dir.ErrorCode != sarama.ErrNoError {
+				m.log.Error(fmt.Sprintf("monitor: cannot describe log dir %s on broker %v: %v", dir.Path, broker.ID(), dir.ErrorCode.Error()))
 				continue
 			}
 
-			coordinator, err := m.client.Coordinator(group)
-			if err != nil {
-				m.log.Error(fmt.Sprintf("monitor: cannot fetch co-ordinator for group %s: %v", group, err))
+			logDir := store.LogDir{Path: dir.Path}
+			for _, topic := range dir.Topics {
+				if !m.topicFilter.Allow(topic.Topic) {
+					continue
+				}
+
+				for _, partition := range topic.Partitions {
+					logDir.Replicas = append(logDir.Replicas, store.LogDirReplica{
+						Topic:     topic.Topic,
+						Partition: partition.PartitionID,
+						Size:      partition.Size,
+						OffsetLag: partition.OffsetLag,
+						Temporary: partition.IsTemporary,
+					})
+				}
+			}
+			sort.Slice(logDir.Replicas, func(i, j int) bool {
+				if logDir.Replicas[i].Topic != logDir.Replicas[j].Topic {
+					return logDir.Replicas[i].Topic < logDir.Replicas[j].Topic
+				}
+				return logDir.Replicas[i].Partition < logDir.Replicas[j].Partition
+			})
+
+			desc.LogDirs = append(desc.LogDirs, logDir)
+		}
+
+		m.stateCh <- desc
+	}
+
+	for _, broker := range m.client.Brokers() {
+		wg.Add(1)
+
+		go getLogDirs(broker)
+	}
+
+	wg.Wait()
+}
+
+// getTopicConfigs gets the topic configurations and sends them to the store.
+func (m *Monitor) getTopicConfigs() {
+	if !m.ProtocolVersion().IsAtLeast(sarama.V0_11_0_0) {
+		// Describing configs requires Kafka 0.11 or later.
+		return
+	}
+
+	var broker *sarama.Broker
+	for _, b := range m.client.Brokers() {
+		if ok, _ := b.Connected(); ok {
+			broker = b
+			break
+		}
+	}
+
+	if broker == nil {
+		m.log.Error("monitor: no connected brokers found to collect topic configs")
+		return
+	}
+
+	request := &sarama.DescribeConfigsRequest{}
+	for topic := range m.getTopics() {
+		request.Resources = append(request.Resources, &sarama.ConfigResource{
+			Type:        sarama.TopicResource,
+			Name:        topic,
+			ConfigNames: topicConfigNames,
+		})
+	}
+
+	if len(request.Resources) == 0 {
+		return
+	}
+
+	response, err := broker.DescribeConfigs(request)
+	if err != nil {
+		m.log.Error(fmt.Sprintf("monitor: cannot describe topic configs: %v", err))
+		return
+	}
+
+	ts := time.Now().Unix() * 1000
+	for _, resource := range response.Resources {
+		if resource.ErrorCode != int16(sarama.ErrNoError) {
+			m.log.Error(fmt.Sprintf("monitor: cannot describe topic config %s: %v", resource.Name, sarama.KError(resource.ErrorCode).Error()))
+			continue
+		}
+
+		configs := make(map[string]string, len(topicConfigNames))
+		for _, entry := range resource.Configs {
+			if !isTopicConfigName(entry.Name) {
 				continue
 			}
 
-			if _, ok := requests[coordinator.ID()]; !ok {
-				coordinators[coordinator.ID()] = coordinator
-				requests[coordinator.ID()] = make(map[string]*sarama.OffsetFetchRequest)
+			configs[entry.Name] = entry.Value
+		}
+
+		m.stateCh <- &store.TopicConfigDescription{
+			Topic:     resource.Name,
+			Configs:   configs,
+			Timestamp: ts,
+		}
+	}
+}
+
+// isTopicConfigName determines if the config is one of the collected topic configs.
+func isTopicConfigName(name string) bool {
+	for _, n := range topicConfigNames {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setBrokerMetadata derives the broker state from the metadata of the cluster.
+func (m *Monitor) setBrokerMetadata(id int32, response *sarama.MetadataResponse) {
+	md := &brokerMetadata{
+		controllerID: response.ControllerID,
+		leaders:      make(map[int32]int),
+		replicas:     make(map[int32]int),
+	}
+	for _, topic := range response.Topics {
+		if topic.Err != sarama.ErrNoError {
+			continue
+		}
+
+		for _, partition := range topic.Partitions {
+			if partition.Leader >= 0 {
+				md.leaders[partition.Leader]++
 			}
 
-			if _, ok := requests[coordinator.ID()][group]; !ok {
-				requests[coordinator.ID()][group] = &sarama.OffsetFetchRequest{ConsumerGroup: group, Version: 1}
+			for _, replica := range partition.Replicas {
+				md.replicas[replica]++
 			}
+		}
+	}
 
-			for topic, partitions := range topicMap {
-				for i := 0; i < partitions; i++ {
-					requests[coordinator.ID()][group].AddPartition(topic, int32(i))
-				}
+	m.brokersMu.Lock()
+	m.metadata = md
+	m.brokersMu.Unlock()
+
+	m.markSeen(id)
+}
+
+// getConsumerOffsets gets all the consumer offsets of the groups and send them to the store.
+func (m *Monitor) getConsumerOffsets(groups map[string]*sarama.Broker) {
+	requests := make(map[int32]map[string]*sarama.OffsetFetchRequest)
+	coordinators := make(map[int32]*sarama.Broker)
+	fetchAll := m.ProtocolVersion().IsAtLeast(sarama.V0_10_2_0)
+
+	var topicMap map[string]int
+	if !fetchAll {
+		topicMap = m.getTopics()
+	}
+
+	for group, coordinator := range groups {
+		if _, ok := requests[coordinator.ID()]; !ok {
+			coordinators[coordinator.ID()] = coordinator
+			requests[coordinator.ID()] = make(map[string]*sarama.OffsetFetchRequest)
+		}
+
+		request := &sarama.OffsetFetchRequest{ConsumerGroup: group, Version: 1}
+		requests[coordinator.ID()][group] = request
+
+		if fetchAll {
+			// Without partitions, all committed offsets of the group are fetched.
+			request.Version = 2
+			continue
+		}
+
+		for topic, partitions := range topicMap {
+			for i := 0; i < partitions; i++ {
+				request.AddPartition(topic, int32(i))
 			}
 		}
 	}
@@ -327,9 +649,14 @@ func (m *Monitor) getConsumerOffsets() {
 
 			return
 		}
+		m.markSeen(brokerID)
 
 		ts := time.Now().Unix() * 1000
 		for topic, partitions := range offsets.Blocks {
+			if !m.topicFilter.Allow(topic) {
+				continue
+			}
+
 			for partition, block := range partitions {
 				if block.Err != sarama.ErrNoError {
 					m.log.Error(fmt.Sprintf("monitor: cannot get group topic offsets %v: %v", brokerID, block.Err.Error()))
@@ -365,13 +692,125 @@ func (m *Monitor) getConsumerOffsets() {
 	wg.Wait()
 }
 
-// containsString determines if the string matches any of the provided patterns.
-func containsString(patterns []string, subject string) bool {
-	for _, pattern := range patterns {
-		if glob.Glob(pattern, subject) {
-			return true
+// getGroups gets the consumer groups with their co-ordinators.
+func (m *Monitor) getGroups() map[string]*sarama.Broker {
+	coordinators := make(map[string]*sarama.Broker)
+
+	brokers := m.client.Brokers()
+	for _, broker := range brokers {
+		if ok, err := broker.Connected(); !ok {
+			if err != nil {
+				m.log.Error(fmt.Sprintf("monitor: failed to connect to broker broker %v: %v", broker.ID(), err))
+				continue
+			}
+
+			if err := broker.Open(m.client.Config()); err != nil {
+				m.log.Error(fmt.Sprintf("monitor: failed to connect to broker broker %v: %v", broker.ID(), err))
+				continue
+			}
+		}
+
+		groups, err := broker.ListGroups(&sarama.ListGroupsRequest{})
+		if err != nil {
+			m.log.Error(fmt.Sprintf("monitor: cannot fetch consumer groups on broker %v: %v", broker.ID(), err))
+			continue
+		}
+		m.markSeen(broker.ID())
+
+		for group := range groups.Groups {
+			if !m.groupFilter.Allow(group) {
+				continue
+			}
+
+			coordinator, err := m.client.Coordinator(group)
+			if err != nil {
+				m.log.Error(fmt.Sprintf("monitor: cannot fetch co-ordinator for group %s: %v", group, err))
+				continue
+			}
+
+			coordinators[group] = coordinator
 		}
 	}
 
-	return false
+	return coordinators
+}
+
+// getConsumerGroups gets the state and membership of the consumer groups.
+func (m *Monitor) getConsumerGroups(groups map[string]*sarama.Broker) {
+	requests := make(map[int32]*sarama.DescribeGroupsRequest)
+	coordinators := make(map[int32]*sarama.Broker)
+
+	for group, coordinator := range groups {
+		if _, ok := requests[coordinator.ID()]; !ok {
+			coordinators[coordinator.ID()] = coordinator
+			requests[coordinator.ID()] = &sarama.DescribeGroupsRequest{}
+		}
+
+		requests[coordinator.ID()].AddGroup(group)
+	}
+
+	var wg sync.WaitGroup
+	getConsumerGroups := func(brokerID int32, request *sarama.DescribeGroupsRequest) {
+		defer wg.Done()
+
+		coordinator := coordinators[brokerID]
+
+		response, err := coordinator.DescribeGroups(request)
+		if err != nil {
+			m.log.Error(fmt.Sprintf("monitor: cannot describe consumer groups on broker %v: %v", brokerID, err))
+
+			return
+		}
+		m.markSeen(brokerID)
+
+		ts := time.Now().Unix() * 1000
+		for _, desc := range response.Groups {
+			if desc.Err != sarama.ErrNoError {
+				m.log.Error(fmt.Sprintf("monitor: cannot describe consumer group %s: %v", desc.GroupId, desc.Err.Error()))
+				continue
+			}
+
+			group := &store.ConsumerGroupDescription{
+				Group:        desc.GroupId,
+				State:        desc.State,
+				ProtocolType: desc.ProtocolType,
+				Protocol:     desc.Protocol,
+				Members:      make([]store.ConsumerGroupMember, 0, len(desc.Members)),
+				Timestamp:    ts,
+			}
+
+			for memberID, member := range desc.Members {
+				groupMember := store.ConsumerGroupMember{
+					MemberID:   memberID,
+					ClientID:   member.ClientId,
+					ClientHost: member.ClientHost,
+				}
+
+				// Only consumer groups use the consumer protocol assignment format.
+				if desc.ProtocolType == "consumer" && len(member.MemberAssignment) > 0 {
+					assignment, err := member.GetMemberAssignment()
+					if err != nil {
+						m.log.Error(fmt.Sprintf("monitor: cannot decode assignment of member %s in group %s: %v", memberID, desc.GroupId, err))
+					} else {
+						groupMember.Assignment = assignment.Topics
+					}
+				}
+
+				group.Members = append(group.Members, groupMember)
+			}
+			sort.Slice(group.Members, func(i, j int) bool {
+				return group.Members[i].MemberID < group.Members[j].MemberID
+			})
+
+			m.stateCh <- group
+		}
+	}
+
+	for brokerID, request := range requests {
+		wg.Add(1)
+
+		go getConsumerGroups(brokerID, request)
+	}
+
+	wg.Wait()
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/Shopify/sarama"
+	"github.com/msales/kage/store"
 	"github.com/msales/kage/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -25,7 +26,65 @@ func TestMonitor_Brokers(t *testing.T) {
 
 	c := &Monitor{client: kafka}
 
-	assert.Len(t, c.Brokers(), 2)
+	brokers := c.Brokers()
+	assert.Len(t, brokers, 2)
+	assert.Equal(t, int32(0), brokers[0].ID)
+	assert.Equal(t, broker0.Addr(), brokers[0].Address)
+	assert.Equal(t, int32(1), brokers[1].ID)
+	// Listing the brokers does not mark them as seen.
+	assert.True(t, brokers[0].LastSeen.IsZero())
+	assert.True(t, brokers[1].LastSeen.IsZero())
+
+	broker0.Close()
+	broker1.Close()
+}
+
+func TestMonitor_BrokersMetadata(t *testing.T) {
+	broker0 := sarama.NewMockBroker(t, 0)
+	broker1 := sarama.NewMockBroker(t, 1)
+	// Either connected broker may be asked for the metadata.
+	handlers := map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker0.Addr(), broker0.BrokerID()).
+			SetBroker(broker1.Addr(), broker1.BrokerID()).
+			SetController(broker1.BrokerID()).
+			SetLeader("foo", 0, broker1.BrokerID()).
+			SetLeader("foo", 1, broker1.BrokerID()).
+			SetLeader("bar", 0, broker0.BrokerID()),
+	}
+	broker0.SetHandlerByMap(handlers)
+	broker1.SetHandlerByMap(handlers)
+
+	kafka, err := sarama.NewClient([]string{broker0.Addr()}, sarama.NewConfig())
+	assert.NoError(t, err)
+	for _, b := range kafka.Brokers() {
+		b.Open(kafka.Config())
+	}
+
+	c := &Monitor{
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		log:         testutil.Logger,
+		topicFilter: &filter{},
+	}
+
+	c.getBrokerMetadata()
+
+	brokers := c.Brokers()
+	assert.Len(t, brokers, 2)
+	assert.False(t, brokers[0].Controller)
+	assert.Equal(t, 1, brokers[0].Leaders)
+	assert.True(t, brokers[1].Controller)
+	assert.Equal(t, 2, brokers[1].Leaders)
+	assert.Equal(t, 3, brokers[1].Replicas)
+	// Only the broker that returned the metadata is seen.
+	seen := 0
+	for _, b := range brokers {
+		if !b.LastSeen.IsZero() {
+			seen++
+		}
+	}
+	assert.Equal(t, 1, seen)
 
 	broker0.Close()
 	broker1.Close()
@@ -95,15 +154,16 @@ func TestMonitor_getBrokerOffsets(t *testing.T) {
 	assert.NoError(t, err)
 
 	c := &Monitor{
-		client:       kafka,
-		stateCh:      make(chan interface{}, 100),
-		log:          testutil.Logger,
-		ignoreTopics: []string{"ignore"},
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		log:         testutil.Logger,
+		topicFilter: &filter{ignore: []pattern{{glob: "ignore"}}},
 	}
 
 	c.getBrokerOffsets()
 
 	assert.Len(t, c.stateCh, 2)
+	assert.False(t, c.Brokers()[0].LastSeen.IsZero())
 
 	broker.Close()
 }
@@ -124,10 +184,10 @@ func TestMonitor_getBrokerMetadata(t *testing.T) {
 	}
 
 	c := &Monitor{
-		client:       kafka,
-		stateCh:      make(chan interface{}, 100),
-		log:          testutil.Logger,
-		ignoreTopics: []string{"ignore"},
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		log:         testutil.Logger,
+		topicFilter: &filter{ignore: []pattern{{glob: "ignore"}}},
 	}
 
 	c.getBrokerMetadata()
@@ -137,6 +197,164 @@ func TestMonitor_getBrokerMetadata(t *testing.T) {
 	broker.Close()
 }
 
+func TestMonitor_getLogDirs(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()),
+		"DescribeLogDirsRequest": sarama.NewMockDescribeLogDirsResponse(t).
+			SetLogDirs("/data", map[string]int{"foo": 2, "ignore": 1}),
+	})
+
+	conf := sarama.NewConfig()
+	conf.Version = sarama.V1_0_0_0
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, conf)
+	assert.NoError(t, err)
+
+	c := &Monitor{
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		log:         testutil.Logger,
+		topicFilter: &filter{ignore: []pattern{{glob: "ignore"}}},
+	}
+
+	c.getLogDirs()
+
+	assert.Len(t, c.stateCh, 1)
+	desc := (<-c.stateCh).(*store.BrokerLogDirsDescription)
+	assert.Equal(t, int32(0), desc.Broker)
+	assert.Len(t, desc.LogDirs, 1)
+	assert.Equal(t, "/data", desc.LogDirs[0].Path)
+	assert.Len(t, desc.LogDirs[0].Replicas, 2)
+	assert.Equal(t, "foo", desc.LogDirs[0].Replicas[1].Topic)
+	assert.Equal(t, int32(1), desc.LogDirs[0].Replicas[1].Partition)
+
+	broker.Close()
+}
+
+func TestMonitor_getTopicConfigs(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()).
+			SetLeader("ignore", 0, broker.BrokerID()),
+		"DescribeConfigsRequest": sarama.NewMockWrapper(&sarama.DescribeConfigsResponse{
+			Resources: []*sarama.ResourceResponse{
+				{
+					Type: sarama.TopicResource,
+					Name: "foo",
+					Configs: []*sarama.ConfigEntry{
+						{Name: "retention.ms", Value: "604800000"},
+						{Name: "cleanup.policy", Value: "delete"},
+						{Name: "min.insync.replicas", Value: "2"},
+						{Name: "password", Value: "secret"},
+					},
+				},
+			},
+		}),
+	})
+
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, sarama.NewConfig())
+	assert.NoError(t, err)
+	for _, b := range kafka.Brokers() {
+		b.Open(kafka.Config())
+	}
+
+	c := &Monitor{
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		log:         testutil.Logger,
+		topicFilter: &filter{ignore: []pattern{{glob: "ignore"}}},
+	}
+
+	c.getTopicConfigs()
+
+	assert.Len(t, c.stateCh, 1)
+	config := (<-c.stateCh).(*store.TopicConfigDescription)
+	assert.Equal(t, "foo", config.Topic)
+	assert.Equal(t, map[string]string{
+		"retention.ms":        "604800000",
+		"cleanup.policy":      "delete",
+		"min.insync.replicas": "2",
+	}, config.Configs)
+
+	broker.Close()
+}
+
+func TestMonitor_getTopicConfigsUnsupportedVersion(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()),
+	})
+
+	conf := sarama.NewConfig()
+	conf.Version = sarama.V0_10_2_0
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, conf)
+	assert.NoError(t, err)
+
+	c := &Monitor{
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		log:         testutil.Logger,
+		topicFilter: &filter{},
+	}
+
+	c.getTopicConfigs()
+
+	assert.Len(t, c.stateCh, 0)
+
+	broker.Close()
+}
+
+func TestMonitor_CollectListsGroupsOnce(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("foo", 0, sarama.OffsetOldest, 0).
+			SetOffset("foo", 0, sarama.OffsetNewest, 123),
+		"ListGroupsRequest": sarama.NewMockWrapper(&sarama.ListGroupsResponse{
+			Err:    sarama.ErrNoError,
+			Groups: map[string]string{"test": "consumer"},
+		}),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "test", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("test", "foo", 0, 100, "", sarama.ErrNoError),
+		"DescribeGroupsRequest": sarama.NewMockDescribeGroupsResponse(t).
+			AddGroupDescription("test", &sarama.GroupDescription{GroupId: "test", State: "Empty"}),
+	})
+
+	conf := sarama.NewConfig()
+	conf.Version = sarama.V0_10_1_0
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, conf)
+	assert.NoError(t, err)
+
+	c := &Monitor{
+		client:  kafka,
+		stateCh: make(chan interface{}, 100),
+		log:     testutil.Logger,
+	}
+
+	c.Collect()
+
+	var listGroups int
+	for _, rr := range broker.History() {
+		if _, ok := rr.Request.(*sarama.ListGroupsRequest); ok {
+			listGroups++
+		}
+	}
+	assert.Equal(t, 1, listGroups)
+
+	broker.Close()
+}
+
 func TestMonitor_getConsumerOffsets(t *testing.T) {
 	broker := sarama.NewMockBroker(t, 0)
 	broker.SetHandlerByMap(map[string]sarama.MockResponse{
@@ -164,15 +382,169 @@ func TestMonitor_getConsumerOffsets(t *testing.T) {
 	assert.NoError(t, err)
 
 	c := &Monitor{
-		client:       kafka,
-		stateCh:      make(chan interface{}, 100),
-		log:          testutil.Logger,
-		ignoreGroups: []string{"ignore"},
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		log:         testutil.Logger,
+		groupFilter: &filter{ignore: []pattern{{glob: "ignore"}}},
+	}
+
+	c.getConsumerOffsets(c.getGroups())
+
+	assert.Len(t, c.stateCh, 1)
+
+	broker.Close()
+}
+
+func TestMonitor_getConsumerOffsetsFetchAll(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()),
+		"ListGroupsRequest": sarama.NewMockWrapper(&sarama.ListGroupsResponse{
+			Err:    sarama.ErrNoError,
+			Groups: map[string]string{"test": "consumer"},
+		}),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "test", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("test", "foo", 0, 123, "", sarama.ErrNoError).
+			SetOffset("test", "bar", 0, 456, "", sarama.ErrNoError).
+			SetOffset("test", "ignore", 0, 789, "", sarama.ErrNoError),
+	})
+
+	conf := sarama.NewConfig()
+	conf.Version = sarama.V0_10_2_0
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, conf)
+	assert.NoError(t, err)
+
+	c := &Monitor{
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		log:         testutil.Logger,
+		topicFilter: &filter{ignore: []pattern{{glob: "ignore"}}},
+	}
+
+	c.getConsumerOffsets(c.getGroups())
+
+	assert.Len(t, c.stateCh, 2)
+
+	broker.Close()
+}
+
+func TestMonitor_getConsumerOffsetsFetchAllFiltered(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()),
+		"ListGroupsRequest": sarama.NewMockWrapper(&sarama.ListGroupsResponse{
+			Err:    sarama.ErrNoError,
+			Groups: map[string]string{"test": "consumer", "ignored": "consumer"},
+		}),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "test", broker).
+			SetCoordinator(sarama.CoordinatorGroup, "ignored", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("test", "foo", 0, 123, "", sarama.ErrNoError).
+			SetOffset("test", "bar", 0, 456, "", sarama.ErrNoError),
+	})
+
+	conf := sarama.NewConfig()
+	conf.Version = sarama.V0_10_2_0
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, conf)
+	assert.NoError(t, err)
+
+	topicFilter, err := newFilter([]string{"/^fo+$/"}, nil)
+	assert.NoError(t, err)
+	groupFilter, err := newFilter(nil, []string{"ignored"})
+	assert.NoError(t, err)
+
+	c := &Monitor{
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		topicFilter: topicFilter,
+		groupFilter: groupFilter,
+		log:         testutil.Logger,
+	}
+
+	c.getConsumerOffsets(c.getGroups())
+
+	assert.Len(t, c.stateCh, 1)
+	offset := (<-c.stateCh).(*store.ConsumerPartitionOffset)
+	assert.Equal(t, "test", offset.Group)
+	assert.Equal(t, "foo", offset.Topic)
+
+	broker.Close()
+}
+
+func TestMonitor_getConsumerGroups(t *testing.T) {
+	// Consumer protocol assignment of topic "foo" partitions 0 and 1.
+	assignment := []byte{
+		0x00, 0x00, // Version
+		0x00, 0x00, 0x00, 0x01, // Topic count
+		0x00, 0x03, 'f', 'o', 'o', // Topic
+		0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // Partitions
+		0xff, 0xff, 0xff, 0xff, // User data
+	}
+
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()),
+		"ListGroupsRequest": sarama.NewMockWrapper(&sarama.ListGroupsResponse{
+			Err:    sarama.ErrNoError,
+			Groups: map[string]string{"test": "consumer"},
+		}),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "test", broker),
+		"DescribeGroupsRequest": sarama.NewMockDescribeGroupsResponse(t).
+			AddGroupDescription("test", &sarama.GroupDescription{
+				GroupId:      "test",
+				State:        "Stable",
+				ProtocolType: "consumer",
+				Protocol:     "range",
+				Members: map[string]*sarama.GroupMemberDescription{
+					"consumer-1-abc": {
+						ClientId:         "consumer-1",
+						ClientHost:       "/127.0.0.1",
+						MemberAssignment: assignment,
+					},
+				},
+			}),
+	})
+
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, nil)
+	assert.NoError(t, err)
+
+	c := &Monitor{
+		client:  kafka,
+		stateCh: make(chan interface{}, 100),
+		log:     testutil.Logger,
 	}
 
-	c.getConsumerOffsets()
+	c.getConsumerGroups(c.getGroups())
 
 	assert.Len(t, c.stateCh, 1)
+	group := (<-c.stateCh).(*store.ConsumerGroupDescription)
+	assert.Equal(t, "test", group.Group)
+	assert.Equal(t, "Stable", group.State)
+	assert.Equal(t, "range", group.Protocol)
+	assert.Equal(t, []store.ConsumerGroupMember{
+		{
+			MemberID:   "consumer-1-abc",
+			ClientID:   "consumer-1",
+			ClientHost: "/127.0.0.1",
+			Assignment: map[string][]int32{"foo": {0, 1}},
+		},
+	}, group.Members)
 
 	broker.Close()
 }
+
+func TestNew_UnknownOffsetsSource(t *testing.T) {
+	_, err := New(OffsetsSource("foo"))
+
+	assert.Error(t, err)
+}
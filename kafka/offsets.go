@@ -0,0 +1,370 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/msales/kage/store"
+)
+
+// Consumer offsets sources.
+const (
+	// OffsetsSourceFetch fetches the consumer offsets from the group co-ordinators.
+	OffsetsSourceFetch = "fetch"
+	// OffsetsSourceStream consumes the consumer offsets from the consumer offsets topic.
+	OffsetsSourceStream = "stream"
+)
+
+// consumerOffsetsTopic is the internal topic the consumer offsets are committed to.
+const consumerOffsetsTopic = "__consumer_offsets"
+
+var errMalformedRecord = errors.New("kafka: malformed consumer offsets record")
+
+// startOffsetsStream starts consuming the consumer offsets topic.
+func (m *Monitor) startOffsetsStream() error {
+	consumer, err := sarama.NewConsumerFromClient(m.client)
+	if err != nil {
+		return err
+	}
+
+	partitions, err := consumer.Partitions(consumerOffsetsTopic)
+	if err != nil {
+		_ = consumer.Close()
+		return fmt.Errorf("kafka: cannot get consumer offsets partitions: %w", err)
+	}
+
+	for _, partition := range partitions {
+		pc, err := consumer.ConsumePartition(consumerOffsetsTopic, partition, sarama.OffsetOldest)
+		if err != nil {
+			for _, pc := range m.offsetsPartitions {
+				pc.AsyncClose()
+			}
+			_ = consumer.Close()
+			return fmt.Errorf("kafka: cannot consume consumer offsets partition %d: %w", partition, err)
+		}
+
+		m.offsetsPartitions = append(m.offsetsPartitions, pc)
+	}
+	m.offsetsConsumer = consumer
+
+	for _, pc := range m.offsetsPartitions {
+		go m.consumeOffsets(pc)
+	}
+
+	return nil
+}
+
+// stopOffsetsStream stops consuming the consumer offsets topic.
+func (m *Monitor) stopOffsetsStream() {
+	if m.offsetsConsumer == nil {
+		return
+	}
+
+	for _, pc := range m.offsetsPartitions {
+		pc.AsyncClose()
+	}
+	_ = m.offsetsConsumer.Close()
+}
+
+// consumeOffsets sends the states decoded from the consumer offsets partition to the store.
+func (m *Monitor) consumeOffsets(pc sarama.PartitionConsumer) {
+	for msg := range pc.Messages() {
+		v, err := decodeOffsetsRecord(msg.Key, msg.Value, msg.Timestamp.UnixNano()/int64(time.Millisecond))
+		if err != nil {
+			m.log.Error(fmt.Sprintf("monitor: cannot decode consumer offsets record at %d:%d: %v", msg.Partition, msg.Offset, err))
+			continue
+		}
+
+		switch val := v.(type) {
+		case *store.ConsumerPartitionOffset:
+			if !m.groupFilter.Allow(val.Group) || !m.topicFilter.Allow(val.Topic) {
+				continue
+			}
+
+		case *store.ConsumerGroupDescription:
+			if !m.groupFilter.Allow(val.Group) {
+				continue
+			}
+
+		default:
+			continue
+		}
+
+		m.stateCh <- v
+	}
+}
+
+// decodeOffsetsRecord decodes a consumer offsets topic record.
+//
+// Offset commit records are decoded into a ConsumerPartitionOffset and group metadata
+// records into a ConsumerGroupDescription. Deleted offsets are decoded into a deleted
+// ConsumerPartitionOffset.
+// The timestamp of the record is used when the value has no timestamp.
+func decodeOffsetsRecord(key, value []byte, ts int64) (interface{}, error) {
+	kd := &recordDecoder{b: key}
+	version := kd.int16()
+	switch version {
+	case 0, 1:
+		group := kd.string()
+		topic := kd.string()
+		partition := kd.int32()
+		if kd.err != nil {
+			return nil, kd.err
+		}
+
+		if value == nil {
+			return &store.ConsumerPartitionOffset{
+				Group:     group,
+				Topic:     topic,
+				Partition: partition,
+				Timestamp: ts,
+				Deleted:   true,
+			}, nil
+		}
+
+		offset, commitTs, err := decodeOffsetValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if commitTs <= 0 {
+			commitTs = ts
+		}
+
+		return &store.ConsumerPartitionOffset{
+			Group:     group,
+			Topic:     topic,
+			Partition: partition,
+			Offset:    offset,
+			Timestamp: commitTs,
+		}, nil
+
+	case 2:
+		group := kd.string()
+		if kd.err != nil {
+			return nil, kd.err
+		}
+
+		if value == nil {
+			return &store.ConsumerGroupDescription{Group: group, State: "Dead", Timestamp: ts}, nil
+		}
+
+		desc, err := decodeGroupMetadataValue(value)
+		if err != nil {
+			return nil, err
+		}
+		desc.Group = group
+		if desc.Timestamp <= 0 {
+			desc.Timestamp = ts
+		}
+
+		return desc, nil
+
+	default:
+		if kd.err != nil {
+			return nil, kd.err
+		}
+		return nil, fmt.Errorf("kafka: unknown consumer offsets key version %d", version)
+	}
+}
+
+// decodeOffsetValue decodes the offset and commit timestamp of an offset commit value.
+func decodeOffsetValue(value []byte) (int64, int64, error) {
+	d := &recordDecoder{b: value}
+
+	version := d.int16()
+	if version < 0 || version > 4 {
+		if d.err != nil {
+			return 0, 0, d.err
+		}
+		return 0, 0, fmt.Errorf("kafka: unknown offset commit value version %d", version)
+	}
+	d.compact = version >= 4
+
+	offset := d.int64()
+	if version >= 3 {
+		d.int32() // Leader epoch
+	}
+	d.string() // Metadata
+	commitTs := d.int64()
+	if version == 1 {
+		d.int64() // Expire timestamp
+	}
+	d.taggedFields()
+
+	return offset, commitTs, d.err
+}
+
+// decodeGroupMetadataValue decodes a group metadata value.
+func decodeGroupMetadataValue(value []byte) (*store.ConsumerGroupDescription, error) {
+	d := &recordDecoder{b: value}
+
+	version := d.int16()
+	if version < 0 || version > 4 {
+		if d.err != nil {
+			return nil, d.err
+		}
+		return nil, fmt.Errorf("kafka: unknown group metadata value version %d", version)
+	}
+	d.compact = version >= 4
+
+	desc := &store.ConsumerGroupDescription{}
+	desc.ProtocolType = d.string()
+	d.int32() // Generation
+	desc.Protocol = d.string()
+	d.string() // Leader
+	if version >= 2 {
+		desc.Timestamp = d.int64()
+	}
+
+	n := d.arrayLen()
+	for i := 0; i < n && d.err == nil; i++ {
+		member := store.ConsumerGroupMember{}
+		member.MemberID = d.string()
+		if version >= 3 {
+			d.string() // Group instance ID
+		}
+		member.ClientID = d.string()
+		member.ClientHost = d.string()
+		if version >= 1 {
+			d.int32() // Rebalance timeout
+		}
+		d.int32() // Session timeout
+		d.bytes() // Subscription
+		assignment := d.bytes()
+		d.taggedFields()
+
+		// Only consumer groups use the consumer protocol assignment format.
+		if desc.ProtocolType == "consumer" && len(assignment) > 0 {
+			gmd := &sarama.GroupMemberDescription{MemberAssignment: assignment}
+			if a, err := gmd.GetMemberAssignment(); err == nil {
+				member.Assignment = a.Topics
+			}
+		}
+
+		desc.Members = append(desc.Members, member)
+	}
+	d.taggedFields()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	desc.State = "Empty"
+	if len(desc.Members) > 0 {
+		desc.State = "Stable"
+	}
+
+	return desc, nil
+}
+
+// recordDecoder decodes the Kafka protocol primitives of a record.
+//
+// Once an error occurs all further reads return zero values.
+type recordDecoder struct {
+	b       []byte
+	off     int
+	compact bool
+	err     error
+}
+
+func (d *recordDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.off+n > len(d.b) {
+		d.err = errMalformedRecord
+		return nil
+	}
+
+	b := d.b[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *recordDecoder) int16() int16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *recordDecoder) int32() int32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (d *recordDecoder) int64() int64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func (d *recordDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(d.b[d.off:])
+	if n <= 0 {
+		d.err = errMalformedRecord
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// length reads the length of a string, bytes or array, where -1 is null.
+func (d *recordDecoder) length(short bool) int {
+	if d.compact {
+		return int(d.uvarint()) - 1
+	}
+	if short {
+		return int(d.int16())
+	}
+	return int(d.int32())
+}
+
+func (d *recordDecoder) string() string {
+	n := d.length(true)
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(n))
+}
+
+func (d *recordDecoder) bytes() []byte {
+	n := d.length(false)
+	if n < 0 {
+		return nil
+	}
+	return d.next(n)
+}
+
+func (d *recordDecoder) arrayLen() int {
+	n := d.length(false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// taggedFields skips the tagged fields of a flexible version.
+func (d *recordDecoder) taggedFields() {
+	if !d.compact {
+		return
+	}
+
+	n := d.uvarint()
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		d.uvarint() // Tag
+		d.next(int(d.uvarint()))
+	}
+}
@@ -0,0 +1,304 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordEncoder encodes consumer offsets fixture records.
+type recordEncoder struct {
+	b       []byte
+	compact bool
+}
+
+func (e *recordEncoder) int16(v int16) *recordEncoder {
+	e.b = append(e.b, byte(v>>8), byte(v))
+	return e
+}
+
+func (e *recordEncoder) int32(v int32) *recordEncoder {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	e.b = append(e.b, b...)
+	return e
+}
+
+func (e *recordEncoder) int64(v int64) *recordEncoder {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	e.b = append(e.b, b...)
+	return e
+}
+
+func (e *recordEncoder) uvarint(v uint64) *recordEncoder {
+	b := make([]byte, binary.MaxVarintLen64)
+	e.b = append(e.b, b[:binary.PutUvarint(b, v)]...)
+	return e
+}
+
+func (e *recordEncoder) string(v string) *recordEncoder {
+	if e.compact {
+		e.uvarint(uint64(len(v) + 1))
+	} else {
+		e.int16(int16(len(v)))
+	}
+	e.b = append(e.b, v...)
+	return e
+}
+
+func (e *recordEncoder) nullString() *recordEncoder {
+	if e.compact {
+		return e.uvarint(0)
+	}
+	return e.int16(-1)
+}
+
+func (e *recordEncoder) bytes(v []byte) *recordEncoder {
+	if e.compact {
+		e.uvarint(uint64(len(v) + 1))
+	} else {
+		e.int32(int32(len(v)))
+	}
+	e.b = append(e.b, v...)
+	return e
+}
+
+func (e *recordEncoder) arrayLen(n int) *recordEncoder {
+	if e.compact {
+		return e.uvarint(uint64(n + 1))
+	}
+	return e.int32(int32(n))
+}
+
+func (e *recordEncoder) taggedFields() *recordEncoder {
+	if e.compact {
+		// A single unknown tagged field, which must be skipped.
+		e.uvarint(1).uvarint(10).uvarint(2)
+		e.b = append(e.b, 0xca, 0xfe)
+	}
+	return e
+}
+
+func offsetKey(version int16, group, topic string, partition int32) []byte {
+	return (&recordEncoder{}).int16(version).string(group).string(topic).int32(partition).b
+}
+
+func groupKey(group string) []byte {
+	return (&recordEncoder{}).int16(2).string(group).b
+}
+
+func offsetValue(version int16, offset, ts int64) []byte {
+	e := &recordEncoder{compact: version >= 4}
+	e.int16(version).int64(offset)
+	if version >= 3 {
+		e.int32(5)
+	}
+	e.string("metadata").int64(ts)
+	if version == 1 {
+		e.int64(ts + 1000)
+	}
+	return e.taggedFields().b
+}
+
+// Consumer protocol assignment of topic "foo" partitions 0 and 1.
+var fixtureAssignment = []byte{
+	0x00, 0x00, // Version
+	0x00, 0x00, 0x00, 0x01, // Topic count
+	0x00, 0x03, 'f', 'o', 'o', // Topic
+	0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // Partitions
+	0xff, 0xff, 0xff, 0xff, // User data
+}
+
+func groupValue(version int16, ts int64, members int) []byte {
+	e := &recordEncoder{compact: version >= 4}
+	e.int16(version).string("consumer").int32(3).string("range").string("consumer-1-abc")
+	if version >= 2 {
+		e.int64(ts)
+	}
+	e.arrayLen(members)
+	for i := 0; i < members; i++ {
+		e.string("consumer-1-abc")
+		if version >= 3 {
+			e.nullString()
+		}
+		e.string("consumer-1").string("/127.0.0.1")
+		if version >= 1 {
+			e.int32(60000)
+		}
+		e.int32(10000).bytes([]byte{0x00}).bytes(fixtureAssignment).taggedFields()
+	}
+	return e.taggedFields().b
+}
+
+func TestDecodeOffsetsRecord_OffsetCommit(t *testing.T) {
+	for _, keyVersion := range []int16{0, 1} {
+		for _, valueVersion := range []int16{0, 1, 2, 3, 4} {
+			key := offsetKey(keyVersion, "group", "test", 2)
+			value := offsetValue(valueVersion, 1234, 1600000000000)
+
+			v, err := decodeOffsetsRecord(key, value, 1)
+
+			assert.NoError(t, err, "key v%d value v%d", keyVersion, valueVersion)
+			assert.Equal(t, &store.ConsumerPartitionOffset{
+				Group:     "group",
+				Topic:     "test",
+				Partition: 2,
+				Offset:    1234,
+				Timestamp: 1600000000000,
+			}, v, "key v%d value v%d", keyVersion, valueVersion)
+		}
+	}
+}
+
+func TestDecodeOffsetsRecord_OffsetDeleted(t *testing.T) {
+	v, err := decodeOffsetsRecord(offsetKey(1, "group", "test", 2), nil, 1600000000000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &store.ConsumerPartitionOffset{
+		Group:     "group",
+		Topic:     "test",
+		Partition: 2,
+		Timestamp: 1600000000000,
+		Deleted:   true,
+	}, v)
+}
+
+func TestDecodeOffsetsRecord_GroupMetadata(t *testing.T) {
+	for _, version := range []int16{0, 1, 2, 3, 4} {
+		v, err := decodeOffsetsRecord(groupKey("group"), groupValue(version, 1600000000000, 1), 1500000000000)
+
+		want := int64(1500000000000)
+		if version >= 2 {
+			want = 1600000000000
+		}
+		assert.NoError(t, err, "value v%d", version)
+		assert.Equal(t, &store.ConsumerGroupDescription{
+			Group:        "group",
+			State:        "Stable",
+			ProtocolType: "consumer",
+			Protocol:     "range",
+			Members: []store.ConsumerGroupMember{
+				{
+					MemberID:   "consumer-1-abc",
+					ClientID:   "consumer-1",
+					ClientHost: "/127.0.0.1",
+					Assignment: map[string][]int32{"foo": {0, 1}},
+				},
+			},
+			Timestamp: want,
+		}, v, "value v%d", version)
+	}
+}
+
+func TestDecodeOffsetsRecord_GroupEmpty(t *testing.T) {
+	v, err := decodeOffsetsRecord(groupKey("group"), groupValue(3, 1600000000000, 0), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Empty", v.(*store.ConsumerGroupDescription).State)
+	assert.Len(t, v.(*store.ConsumerGroupDescription).Members, 0)
+}
+
+func TestDecodeOffsetsRecord_GroupDeleted(t *testing.T) {
+	v, err := decodeOffsetsRecord(groupKey("group"), nil, 1600000000000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &store.ConsumerGroupDescription{Group: "group", State: "Dead", Timestamp: 1600000000000}, v)
+}
+
+func TestDecodeOffsetsRecord_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   []byte
+		value []byte
+	}{
+		{name: "empty key", key: []byte{}, value: offsetValue(1, 1, 1)},
+		{name: "unknown key version", key: (&recordEncoder{}).int16(3).string("group").b, value: offsetValue(1, 1, 1)},
+		{name: "truncated key", key: offsetKey(1, "group", "test", 2)[:8], value: offsetValue(1, 1, 1)},
+		{name: "unknown offset version", key: offsetKey(1, "group", "test", 2), value: (&recordEncoder{}).int16(5).int64(1).b},
+		{name: "truncated offset", key: offsetKey(1, "group", "test", 2), value: offsetValue(3, 1, 1)[:10]},
+		{name: "unknown group version", key: groupKey("group"), value: (&recordEncoder{}).int16(5).b},
+		{name: "truncated group", key: groupKey("group"), value: groupValue(3, 1, 1)[:40]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeOffsetsRecord(tt.key, tt.value, 1)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMonitor_OffsetsStream(t *testing.T) {
+	fetch := &sarama.FetchResponse{Version: 4}
+	fetch.AddRecord(consumerOffsetsTopic, 0, sarama.ByteEncoder(offsetKey(1, "test", "foo", 0)), sarama.ByteEncoder(offsetValue(3, 123, 1600000000000)), 0)
+	fetch.AddRecord(consumerOffsetsTopic, 0, sarama.ByteEncoder(offsetKey(1, "ignored", "foo", 0)), sarama.ByteEncoder(offsetValue(3, 456, 1600000000000)), 1)
+	fetch.AddRecord(consumerOffsetsTopic, 0, sarama.ByteEncoder(groupKey("test")), sarama.ByteEncoder(groupValue(3, 1600000000000, 1)), 2)
+	fetch.AddRecord(consumerOffsetsTopic, 0, sarama.ByteEncoder(offsetKey(1, "test", "foo", 0)), nil, 3)
+	fetch.SetLastOffsetDelta(consumerOffsetsTopic, 0, 3)
+	fetch.SetLastStableOffset(consumerOffsetsTopic, 0, 4)
+
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(consumerOffsetsTopic, 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetVersion(1).
+			SetOffset(consumerOffsetsTopic, 0, sarama.OffsetOldest, 0).
+			SetOffset(consumerOffsetsTopic, 0, sarama.OffsetNewest, 4),
+		"FetchRequest": sarama.NewMockWrapper(fetch),
+	})
+	defer broker.Close()
+
+	conf := sarama.NewConfig()
+	conf.Version = sarama.V0_11_0_0
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, conf)
+	assert.NoError(t, err)
+	defer kafka.Close()
+
+	groupFilter, err := newFilter(nil, []string{"ignored"})
+	assert.NoError(t, err)
+
+	c := &Monitor{
+		client:      kafka,
+		stateCh:     make(chan interface{}, 100),
+		groupFilter: groupFilter,
+		log:         testutil.Logger,
+	}
+
+	err = c.startOffsetsStream()
+	assert.NoError(t, err)
+	defer c.stopOffsetsStream()
+
+	var states []interface{}
+	timeout := time.After(5 * time.Second)
+	for len(states) < 3 {
+		select {
+		case v := <-c.stateCh:
+			states = append(states, v)
+		case <-timeout:
+			t.Fatal("timed out waiting for states")
+		}
+	}
+
+	assert.Equal(t, &store.ConsumerPartitionOffset{
+		Group:     "test",
+		Topic:     "foo",
+		Partition: 0,
+		Offset:    123,
+		Timestamp: 1600000000000,
+	}, states[0])
+	assert.Equal(t, "test", states[1].(*store.ConsumerGroupDescription).Group)
+	assert.Equal(t, "Stable", states[1].(*store.ConsumerGroupDescription).State)
+	deleted := states[2].(*store.ConsumerPartitionOffset)
+	assert.Equal(t, "test", deleted.Group)
+	assert.Equal(t, "foo", deleted.Topic)
+	assert.True(t, deleted.Deleted)
+}
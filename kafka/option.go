@@ -1,6 +1,9 @@
 package kafka
 
 import (
+	"crypto/tls"
+	"time"
+
 	"github.com/hamba/pkg/log"
 )
 
@@ -21,6 +24,56 @@ func Brokers(brokers []string) MonitorFunc {
 	}
 }
 
+// Version configures the Kafka protocol version on the Monitor.
+//
+// The version can either be a Kafka version (e.g. "2.1.0") or
+// VersionAuto to negotiate the version with the brokers.
+func Version(version string) MonitorFunc {
+	return func(c *Monitor) {
+		c.version = version
+	}
+}
+
+// RefreshInterval configures the interval at which the metadata is refreshed on the Monitor.
+func RefreshInterval(d time.Duration) MonitorFunc {
+	return func(c *Monitor) {
+		if d > 0 {
+			c.refreshInterval = d
+		}
+	}
+}
+
+// OffsetsSource configures the source of the consumer offsets on the Monitor.
+//
+// The source can either be OffsetsSourceFetch to fetch the offsets from the group
+// co-ordinators on every collection, or OffsetsSourceStream to consume them from
+// the consumer offsets topic as they are committed.
+func OffsetsSource(source string) MonitorFunc {
+	return func(c *Monitor) {
+		c.offsetsSource = source
+	}
+}
+
+// IncludeTopics configures the topic patterns to be monitored on the Monitor.
+//
+// Patterns may contain wildcards, or be regular expressions when wrapped
+// in slashes. Ignored topics take precedence over included topics.
+func IncludeTopics(topics []string) MonitorFunc {
+	return func(c *Monitor) {
+		c.includeTopics = topics
+	}
+}
+
+// IncludeGroups configures the group patterns to be monitored on the Monitor.
+//
+// Patterns may contain wildcards, or be regular expressions when wrapped
+// in slashes. Ignored groups take precedence over included groups.
+func IncludeGroups(groups []string) MonitorFunc {
+	return func(c *Monitor) {
+		c.includeGroups = groups
+	}
+}
+
 // IgnoreTopics configures the topic patterns to be ignored on the Monitor.
 func IgnoreTopics(topics []string) MonitorFunc {
 	return func(c *Monitor) {
@@ -41,3 +94,21 @@ func StateChannel(ch chan interface{}) MonitorFunc {
 		c.stateCh = ch
 	}
 }
+
+// TLS configures the TLS connection to the brokers on the Monitor.
+func TLS(cfg *tls.Config) MonitorFunc {
+	return func(c *Monitor) {
+		c.tls = cfg
+	}
+}
+
+// SASL configures the SASL mechanism and credentials on the Monitor.
+func SASL(mechanism, user, password string) MonitorFunc {
+	return func(c *Monitor) {
+		c.sasl = &saslConfig{
+			mechanism: mechanism,
+			user:      user,
+			password:  password,
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package kafka
 
 import (
+	"crypto/tls"
 	"testing"
+	"time"
 
 	"github.com/hamba/logger"
 	"github.com/stretchr/testify/assert"
@@ -16,6 +18,42 @@ func TestBrokers(t *testing.T) {
 	assert.Equal(t, brokers, c.brokers)
 }
 
+func TestRefreshInterval(t *testing.T) {
+	c := &Monitor{refreshInterval: time.Minute}
+
+	RefreshInterval(0)(c)
+	assert.Equal(t, time.Minute, c.refreshInterval)
+
+	RefreshInterval(5 * time.Second)(c)
+	assert.Equal(t, 5*time.Second, c.refreshInterval)
+}
+
+func TestOffsetsSource(t *testing.T) {
+	c := &Monitor{}
+
+	OffsetsSource(OffsetsSourceStream)(c)
+
+	assert.Equal(t, OffsetsSourceStream, c.offsetsSource)
+}
+
+func TestIncludeGroups(t *testing.T) {
+	i := []string{"test"}
+	c := &Monitor{}
+
+	IncludeGroups(i)(c)
+
+	assert.Equal(t, i, c.includeGroups)
+}
+
+func TestIncludeTopics(t *testing.T) {
+	i := []string{"test"}
+	c := &Monitor{}
+
+	IncludeTopics(i)(c)
+
+	assert.Equal(t, i, c.includeTopics)
+}
+
 func TestIgnoreGroups(t *testing.T) {
 	i := []string{"test"}
 	c := &Monitor{}
@@ -51,3 +89,28 @@ func TestOffsetChannel(t *testing.T) {
 
 	assert.Equal(t, ch, c.stateCh)
 }
+
+func TestTLS(t *testing.T) {
+	cfg := &tls.Config{}
+	c := &Monitor{}
+
+	TLS(cfg)(c)
+
+	assert.Equal(t, cfg, c.tls)
+}
+
+func TestSASL(t *testing.T) {
+	c := &Monitor{}
+
+	SASL(SASLPlain, "user", "pass")(c)
+
+	assert.Equal(t, &saslConfig{mechanism: SASLPlain, user: "user", password: "pass"}, c.sasl)
+}
+
+func TestVersion(t *testing.T) {
+	c := &Monitor{}
+
+	Version("2.1.0")(c)
+
+	assert.Equal(t, "2.1.0", c.version)
+}
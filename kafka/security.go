@@ -0,0 +1,149 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg/scram"
+)
+
+// SASL mechanisms supported by the Monitor.
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// NewTLSConfig creates a TLS configuration from the given CA, certificate and key files.
+//
+// The CA file is optional, when empty the system root CAs are used. The certificate and
+// key files are optional, but must be given together.
+func NewTLSConfig(caFile, certFile, keyFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: insecureSkipVerify, // nolint:gosec
+	}
+
+	if caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: cannot read ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("kafka: no certificates found in ca file")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("kafka: both a certificate and key file must be given")
+		}
+
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: cannot load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// ConfigureSecurity configures TLS and SASL authentication on the sarama config.
+//
+// TLS is enabled when the TLS config is not nil, and SASL when the mechanism is not
+// empty. The Kafka version must be set on the config beforehand, as it determines
+// the SASL handshake version.
+func ConfigureSecurity(config *sarama.Config, tlsConfig *tls.Config, mechanism, user, password string) error {
+	if tlsConfig != nil {
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	if mechanism != "" {
+		return configureSASL(config, mechanism, user, password)
+	}
+
+	return nil
+}
+
+// configureSASL configures the SASL authentication on the sarama config.
+func configureSASL(config *sarama.Config, mechanism, user, password string) error {
+	config.Net.SASL.Enable = true
+	config.Net.SASL.Handshake = true
+	config.Net.SASL.User = user
+	config.Net.SASL.Password = password
+
+	switch mechanism {
+	case SASLPlain:
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+
+	case SASLScramSHA256:
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hashFn: sha256.New}
+		}
+
+	case SASLScramSHA512:
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hashFn: sha512.New}
+		}
+
+	default:
+		return fmt.Errorf("kafka: unknown sasl mechanism \"%s\"", mechanism)
+	}
+
+	config.Net.SASL.Version = saslHandshakeVersion(config)
+
+	return nil
+}
+
+// saslHandshakeVersion determines the SASL handshake version to use.
+//
+// SCRAM is only supported wrapped in Kafka protocol requests, which
+// requires handshake v1. Plain authentication uses v1 when the broker
+// version supports it.
+func saslHandshakeVersion(config *sarama.Config) int16 {
+	if config.Net.SASL.Mechanism != sarama.SASLTypePlaintext || config.Version.IsAtLeast(sarama.V1_0_0_0) {
+		return sarama.SASLHandshakeV1
+	}
+
+	return sarama.SASLHandshakeV0
+}
+
+// scramClient is a SCRAM client for sarama.
+type scramClient struct {
+	hashFn scram.HashGeneratorFcn
+
+	conv *scram.ClientConversation
+}
+
+// Begin prepares the client for the SCRAM exchange.
+func (c *scramClient) Begin(user, password, authzID string) error {
+	client, err := c.hashFn.NewClient(user, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conv = client.NewConversation()
+
+	return nil
+}
+
+// Step steps the client through the SCRAM exchange.
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conv.Step(challenge)
+}
+
+// Done determines if the SCRAM exchange is complete.
+func (c *scramClient) Done() bool {
+	return c.conv.Done()
+}
@@ -0,0 +1,316 @@
+package kafka
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+
+	cfg, err := NewTLSConfig(certFile, certFile, keyFile, false)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg.RootCAs)
+	assert.Len(t, cfg.Certificates, 1)
+	assert.False(t, cfg.InsecureSkipVerify)
+}
+
+func TestNewTLSConfig_Insecure(t *testing.T) {
+	cfg, err := NewTLSConfig("", "", "", true)
+
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.RootCAs)
+	assert.Len(t, cfg.Certificates, 0)
+	assert.True(t, cfg.InsecureSkipVerify)
+}
+
+func TestNewTLSConfig_MissingCAFile(t *testing.T) {
+	_, err := NewTLSConfig("missing.pem", "", "", false)
+
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfig_InvalidCAFile(t *testing.T) {
+	_, keyFile := writeTestCertificate(t)
+
+	_, err := NewTLSConfig(keyFile, "", "", false)
+
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfig_MissingKeyFile(t *testing.T) {
+	certFile, _ := writeTestCertificate(t)
+
+	_, err := NewTLSConfig("", certFile, "", false)
+
+	assert.Error(t, err)
+}
+
+func TestMonitor_newConfig(t *testing.T) {
+	tlsCfg := &tls.Config{}
+	c := &Monitor{tls: tlsCfg}
+
+	config, err := c.newConfig()
+
+	assert.NoError(t, err)
+	assert.True(t, config.Net.TLS.Enable)
+	assert.Equal(t, tlsCfg, config.Net.TLS.Config)
+	assert.False(t, config.Net.SASL.Enable)
+}
+
+func TestMonitor_newConfigSASL(t *testing.T) {
+	tests := []struct {
+		mechanism string
+		want      sarama.SASLMechanism
+		version   int16
+		scram     bool
+	}{
+		{mechanism: SASLPlain, want: sarama.SASLTypePlaintext, version: sarama.SASLHandshakeV0},
+		{mechanism: SASLScramSHA256, want: sarama.SASLTypeSCRAMSHA256, version: sarama.SASLHandshakeV1, scram: true},
+		{mechanism: SASLScramSHA512, want: sarama.SASLTypeSCRAMSHA512, version: sarama.SASLHandshakeV1, scram: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mechanism, func(t *testing.T) {
+			c := &Monitor{sasl: &saslConfig{mechanism: tt.mechanism, user: "user", password: "pass"}}
+
+			config, err := c.newConfig()
+
+			assert.NoError(t, err)
+			assert.NoError(t, config.Validate())
+			assert.True(t, config.Net.SASL.Enable)
+			assert.Equal(t, tt.want, config.Net.SASL.Mechanism)
+			assert.Equal(t, tt.version, config.Net.SASL.Version)
+			assert.Equal(t, "user", config.Net.SASL.User)
+			assert.Equal(t, "pass", config.Net.SASL.Password)
+			assert.Equal(t, tt.scram, config.Net.SASL.SCRAMClientGeneratorFunc != nil)
+		})
+	}
+}
+
+func TestMonitor_newConfigUnknownSASLMechanism(t *testing.T) {
+	c := &Monitor{sasl: &saslConfig{mechanism: "GSSAPI"}}
+
+	_, err := c.newConfig()
+
+	assert.Error(t, err)
+}
+
+func TestConfigureSecurity(t *testing.T) {
+	tlsCfg := &tls.Config{}
+	config := sarama.NewConfig()
+	config.Version = sarama.V1_0_0_0
+
+	err := ConfigureSecurity(config, tlsCfg, SASLScramSHA256, "user", "pass")
+
+	assert.NoError(t, err)
+	assert.NoError(t, config.Validate())
+	assert.True(t, config.Net.TLS.Enable)
+	assert.Equal(t, tlsCfg, config.Net.TLS.Config)
+	assert.True(t, config.Net.SASL.Enable)
+	assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA256), config.Net.SASL.Mechanism)
+	assert.Equal(t, "user", config.Net.SASL.User)
+}
+
+func TestConfigureSecurity_None(t *testing.T) {
+	config := sarama.NewConfig()
+
+	err := ConfigureSecurity(config, nil, "", "", "")
+
+	assert.NoError(t, err)
+	assert.False(t, config.Net.TLS.Enable)
+	assert.False(t, config.Net.SASL.Enable)
+}
+
+func TestConfigureSecurity_ProducerConnectsWithSASLPlain(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"SaslHandshakeRequest": sarama.NewMockSaslHandshakeResponse(t).
+			SetEnabledMechanisms([]string{sarama.SASLTypePlaintext}),
+		"SaslAuthenticateRequest": sarama.NewMockSaslAuthenticateResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("kage", 0, broker.BrokerID()),
+	})
+	defer broker.Close()
+
+	config := sarama.NewConfig()
+	config.Version = sarama.V1_0_0_0
+	config.Producer.Return.Successes = true
+	err := ConfigureSecurity(config, nil, SASLPlain, "user", "pass")
+	assert.NoError(t, err)
+
+	// Creating the producer authenticates to fetch the metadata.
+	producer, err := sarama.NewSyncProducer([]string{broker.Addr()}, config)
+	assert.NoError(t, err)
+	defer producer.Close()
+
+	var authenticated bool
+	for _, rr := range broker.History() {
+		if _, ok := rr.Request.(*sarama.SaslAuthenticateRequest); ok {
+			authenticated = true
+		}
+	}
+	assert.True(t, authenticated)
+}
+
+func TestScramClient(t *testing.T) {
+	c := &scramClient{hashFn: sha256.New}
+
+	err := c.Begin("user", "pass", "")
+	assert.NoError(t, err)
+
+	resp, err := c.Step("")
+	assert.NoError(t, err)
+	assert.Contains(t, resp, "n=user")
+	assert.False(t, c.Done())
+}
+
+func TestMonitor_ConnectsWithTLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := sarama.NewMockBrokerListener(t, 0, ln)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()),
+	})
+	defer broker.Close()
+
+	tlsCfg, err := NewTLSConfig(certFile, "", "", false)
+	assert.NoError(t, err)
+
+	c := &Monitor{tls: tlsCfg}
+	config, err := c.newConfig()
+	assert.NoError(t, err)
+
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, config)
+	assert.NoError(t, err)
+	defer kafka.Close()
+
+	topics, err := kafka.Topics()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, topics)
+}
+
+func TestMonitor_ConnectsWithSASLPlain(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"SaslHandshakeRequest": sarama.NewMockSaslHandshakeResponse(t).
+			SetEnabledMechanisms([]string{sarama.SASLTypePlaintext}),
+		"SaslAuthenticateRequest": sarama.NewMockSaslAuthenticateResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo", 0, broker.BrokerID()),
+	})
+	defer broker.Close()
+
+	c := &Monitor{sasl: &saslConfig{mechanism: SASLPlain, user: "user", password: "pass"}}
+	config, err := c.newConfig()
+	assert.NoError(t, err)
+
+	// The mock broker only understands SASL wrapped in Kafka protocol requests.
+	config.Version = sarama.V1_0_0_0
+	config.Net.SASL.Version = saslHandshakeVersion(config)
+
+	kafka, err := sarama.NewClient([]string{broker.Addr()}, config)
+	assert.NoError(t, err)
+	defer kafka.Close()
+
+	topics, err := kafka.Topics()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, topics)
+}
+
+func TestMonitor_ConnectsWithSASLPlainFailure(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"SaslHandshakeRequest": sarama.NewMockSaslHandshakeResponse(t).
+			SetEnabledMechanisms([]string{sarama.SASLTypePlaintext}),
+		"SaslAuthenticateRequest": sarama.NewMockSaslAuthenticateResponse(t).
+			SetError(sarama.ErrSASLAuthenticationFailed),
+	})
+	defer broker.Close()
+
+	c := &Monitor{sasl: &saslConfig{mechanism: SASLPlain, user: "user", password: "wrong"}}
+	config, err := c.newConfig()
+	assert.NoError(t, err)
+
+	config.Version = sarama.V1_0_0_0
+	config.Net.SASL.Version = saslHandshakeVersion(config)
+	config.Metadata.Retry.Max = 0
+
+	_, err = sarama.NewClient([]string{broker.Addr()}, config)
+
+	assert.Error(t, err)
+}
+
+// writeTestCertificate writes a self signed certificate and key to temporary files.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kage"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certFile, keyFile
+}
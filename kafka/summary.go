@@ -0,0 +1,28 @@
+package kafka
+
+// ClusterSummary represents a summary of the brokers of a Kafka cluster.
+type ClusterSummary struct {
+	ControllerID     int32 // The ID of the controller broker, or -1 if unknown.
+	Brokers          int
+	ConnectedBrokers int
+}
+
+// Summarize summarizes the brokers of a Kafka cluster.
+func Summarize(brokers []Broker) ClusterSummary {
+	s := ClusterSummary{
+		ControllerID: -1,
+		Brokers:      len(brokers),
+	}
+
+	for _, b := range brokers {
+		if b.Connected {
+			s.ConnectedBrokers++
+		}
+
+		if b.Controller {
+			s.ControllerID = b.ID
+		}
+	}
+
+	return s
+}
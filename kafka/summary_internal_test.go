@@ -0,0 +1,25 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	brokers := []Broker{
+		{ID: 1, Connected: true},
+		{ID: 2, Connected: true, Controller: true},
+		{ID: 3, Connected: false},
+	}
+
+	s := Summarize(brokers)
+
+	assert.Equal(t, ClusterSummary{ControllerID: 2, Brokers: 3, ConnectedBrokers: 2}, s)
+}
+
+func TestSummarize_NoController(t *testing.T) {
+	s := Summarize(nil)
+
+	assert.Equal(t, ClusterSummary{ControllerID: -1}, s)
+}
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
+
+// VersionAuto negotiates the Kafka protocol version with the brokers.
+const VersionAuto = "auto"
+
+// defaultVersion is the Kafka protocol version used when none is configured.
+var defaultVersion = sarama.V0_10_1_0
+
+// versionRequirement represents the api support a broker must have for a Kafka version.
+type versionRequirement struct {
+	version    sarama.KafkaVersion
+	apiKey     int16
+	minVersion int16
+}
+
+// versionRequirements are the requirements of known Kafka versions, newest first.
+var versionRequirements = []versionRequirement{
+	{version: sarama.V2_6_0_0, apiKey: 48, minVersion: 0},  // DescribeClientQuotas
+	{version: sarama.V2_5_0_0, apiKey: 9, minVersion: 7},   // OffsetFetch
+	{version: sarama.V2_4_0_0, apiKey: 45, minVersion: 0},  // AlterPartitionReassignments
+	{version: sarama.V2_3_0_0, apiKey: 44, minVersion: 0},  // IncrementalAlterConfigs
+	{version: sarama.V2_2_0_0, apiKey: 43, minVersion: 0},  // ElectLeaders
+	{version: sarama.V2_1_0_0, apiKey: 2, minVersion: 4},   // ListOffsets
+	{version: sarama.V2_0_0_0, apiKey: 2, minVersion: 3},   // ListOffsets
+	{version: sarama.V1_1_0_0, apiKey: 42, minVersion: 0},  // DeleteGroups
+	{version: sarama.V1_0_0_0, apiKey: 36, minVersion: 0},  // SaslAuthenticate
+	{version: sarama.V0_11_0_0, apiKey: 22, minVersion: 0}, // InitProducerId
+	{version: sarama.V0_10_2_0, apiKey: 9, minVersion: 2},  // OffsetFetch
+	{version: sarama.V0_10_1_0, apiKey: 19, minVersion: 0}, // CreateTopics
+}
+
+// parseVersion parses a Kafka protocol version.
+func parseVersion(version string) (sarama.KafkaVersion, error) {
+	if version == "" {
+		return defaultVersion, nil
+	}
+
+	v, err := sarama.ParseKafkaVersion(version)
+	if err != nil {
+		return v, fmt.Errorf("kafka: invalid version \"%s\"", version)
+	}
+
+	return v, nil
+}
+
+// negotiateVersion determines the newest Kafka protocol version supported
+// by the first reachable broker.
+func negotiateVersion(addrs []string, config *sarama.Config) (sarama.KafkaVersion, error) {
+	if len(addrs) == 0 {
+		return sarama.KafkaVersion{}, errors.New("kafka: no brokers to negotiate the version with")
+	}
+
+	// ApiVersions was introduced in 0.10.0, older brokers cannot be negotiated with.
+	config.Version = sarama.V0_10_0_0
+
+	var lastErr error
+	for _, addr := range addrs {
+		broker := sarama.NewBroker(addr)
+		if err := broker.Open(config); err != nil {
+			lastErr = err
+			continue
+		}
+
+		resp, err := broker.ApiVersions(&sarama.ApiVersionsRequest{})
+		_ = broker.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.Err != sarama.ErrNoError {
+			lastErr = resp.Err
+			continue
+		}
+
+		return versionFromAPIs(resp.ApiVersions), nil
+	}
+
+	return sarama.KafkaVersion{}, fmt.Errorf("kafka: cannot negotiate version: %w", lastErr)
+}
+
+// versionFromAPIs determines the newest Kafka version matching the supported apis.
+func versionFromAPIs(apis []*sarama.ApiVersionsResponseBlock) sarama.KafkaVersion {
+	maxVersions := make(map[int16]int16, len(apis))
+	for _, api := range apis {
+		maxVersions[api.ApiKey] = api.MaxVersion
+	}
+
+	for _, req := range versionRequirements {
+		if v, ok := maxVersions[req.apiKey]; ok && v >= req.minVersion {
+			return req.version
+		}
+	}
+
+	return sarama.V0_10_0_0
+}
@@ -0,0 +1,138 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/msales/kage/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := parseVersion("")
+	assert.NoError(t, err)
+	assert.Equal(t, sarama.V0_10_1_0, v)
+
+	v, err = parseVersion("2.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, sarama.V2_1_0_0, v)
+
+	_, err = parseVersion("foo")
+	assert.Error(t, err)
+}
+
+func TestVersionFromAPIs(t *testing.T) {
+	tests := []struct {
+		name string
+		apis []*sarama.ApiVersionsResponseBlock
+		want sarama.KafkaVersion
+	}{
+		{
+			name: "none",
+			apis: []*sarama.ApiVersionsResponseBlock{},
+			want: sarama.V0_10_0_0,
+		},
+		{
+			name: "0.10.2",
+			apis: []*sarama.ApiVersionsResponseBlock{{ApiKey: 9, MaxVersion: 2}, {ApiKey: 19, MaxVersion: 1}},
+			want: sarama.V0_10_2_0,
+		},
+		{
+			name: "1.0",
+			apis: []*sarama.ApiVersionsResponseBlock{{ApiKey: 9, MaxVersion: 3}, {ApiKey: 22}, {ApiKey: 36}},
+			want: sarama.V1_0_0_0,
+		},
+		{
+			name: "2.1",
+			apis: []*sarama.ApiVersionsResponseBlock{{ApiKey: 2, MaxVersion: 4}, {ApiKey: 42}},
+			want: sarama.V2_1_0_0,
+		},
+		{
+			name: "2.6",
+			apis: []*sarama.ApiVersionsResponseBlock{{ApiKey: 48}, {ApiKey: 9, MaxVersion: 7}},
+			want: sarama.V2_6_0_0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, versionFromAPIs(tt.apis))
+		})
+	}
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockWrapper(&sarama.ApiVersionsResponse{
+			Err: sarama.ErrNoError,
+			ApiVersions: []*sarama.ApiVersionsResponseBlock{
+				{ApiKey: 2, MaxVersion: 3},
+				{ApiKey: 36, MaxVersion: 1},
+			},
+		}),
+	})
+	defer broker.Close()
+
+	v, err := negotiateVersion([]string{"127.0.0.1:1", broker.Addr()}, sarama.NewConfig())
+
+	assert.NoError(t, err)
+	assert.Equal(t, sarama.V2_0_0_0, v)
+}
+
+func TestNegotiateVersion_NoBrokers(t *testing.T) {
+	_, err := negotiateVersion([]string{}, sarama.NewConfig())
+
+	assert.Error(t, err)
+}
+
+func TestNegotiateVersion_Unreachable(t *testing.T) {
+	config := sarama.NewConfig()
+	config.Net.DialTimeout = 100 * time.Millisecond
+
+	_, err := negotiateVersion([]string{"127.0.0.1:1"}, config)
+
+	assert.Error(t, err)
+}
+
+func TestMonitor_newConfigVersion(t *testing.T) {
+	c := &Monitor{version: "2.1.0"}
+
+	config, err := c.newConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, sarama.V2_1_0_0, config.Version)
+}
+
+func TestMonitor_newConfigInvalidVersion(t *testing.T) {
+	c := &Monitor{version: "foo"}
+
+	_, err := c.newConfig()
+
+	assert.Error(t, err)
+}
+
+func TestNew_AutoVersion(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockWrapper(&sarama.ApiVersionsResponse{
+			Err:         sarama.ErrNoError,
+			ApiVersions: []*sarama.ApiVersionsResponseBlock{{ApiKey: 36, MaxVersion: 1}},
+		}),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()),
+	})
+	defer broker.Close()
+
+	c, err := New(
+		Brokers([]string{broker.Addr()}),
+		Version(VersionAuto),
+		StateChannel(make(chan interface{}, 100)),
+		Log(testutil.Logger),
+	)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, sarama.V1_0_0_0, c.ProtocolVersion())
+}
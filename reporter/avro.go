@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// avroEncoder encodes values in the Avro binary encoding.
+type avroEncoder struct {
+	buf bytes.Buffer
+}
+
+// writeLong writes a zig-zag encoded long.
+func (e *avroEncoder) writeLong(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], v)
+	e.buf.Write(b[:n])
+}
+
+// writeInt writes a zig-zag encoded int.
+func (e *avroEncoder) writeInt(v int32) {
+	e.writeLong(int64(v))
+}
+
+// writeDouble writes a little endian double.
+func (e *avroEncoder) writeDouble(v float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+	e.buf.Write(b[:])
+}
+
+// writeBool writes a boolean.
+func (e *avroEncoder) writeBool(v bool) {
+	if v {
+		e.buf.WriteByte(1)
+		return
+	}
+	e.buf.WriteByte(0)
+}
+
+// writeString writes a length prefixed string.
+func (e *avroEncoder) writeString(v string) {
+	e.writeLong(int64(len(v)))
+	e.buf.WriteString(v)
+}
+
+// writeIntArray writes an array of ints in a single block.
+func (e *avroEncoder) writeIntArray(v []int32) {
+	if len(v) > 0 {
+		e.writeLong(int64(len(v)))
+		for _, i := range v {
+			e.writeInt(i)
+		}
+	}
+	e.writeLong(0)
+}
+
+// Bytes returns the encoded bytes.
+func (e *avroEncoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
@@ -4,20 +4,39 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/store"
 )
 
+// ConsoleReporterFunc represents a configuration function for ConsoleReporter.
+type ConsoleReporterFunc func(c *ConsoleReporter)
+
+// Prefix configures the prefix written before every line on a ConsoleReporter.
+func Prefix(prefix string) ConsoleReporterFunc {
+	return func(c *ConsoleReporter) {
+		c.prefix = prefix
+	}
+}
+
 // ConsoleReporter represents a console reporter.
 type ConsoleReporter struct {
-	w io.Writer
+	w      io.Writer
+	prefix string
 }
 
 // NewConsoleReporter creates and returns a new ConsoleReporter.
-func NewConsoleReporter(w io.Writer) *ConsoleReporter {
-	return &ConsoleReporter{
+func NewConsoleReporter(w io.Writer, opts ...ConsoleReporterFunc) *ConsoleReporter {
+	r := &ConsoleReporter{
 		w: w,
 	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r
 }
 
 // ReportBrokerOffsets reports a snapshot of the broker offsets.
@@ -30,13 +49,14 @@ func (r ConsoleReporter) ReportBrokerOffsets(o *store.BrokerOffsets) {
 
 			_, _ = io.WriteString(
 				r.w,
-				fmt.Sprintf(
-					"%s:%d oldest:%d newest:%d available:%d \n",
+				r.prefix+fmt.Sprintf(
+					"%s:%d oldest:%d newest:%d available:%d produce_rate:%.2f \n",
 					topic,
 					partition,
 					offset.OldestOffset,
 					offset.NewestOffset,
 					offset.NewestOffset-offset.OldestOffset,
+					offset.ProduceRate,
 				),
 			)
 		}
@@ -53,7 +73,7 @@ func (r ConsoleReporter) ReportBrokerMetadata(m *store.BrokerMetadata) {
 
 			_, _ = io.WriteString(
 				r.w,
-				fmt.Sprintf(
+				r.prefix+fmt.Sprintf(
 					"%s:%d leader:%d replicas:%s isr:%s \n",
 					topic,
 					partition,
@@ -77,16 +97,110 @@ func (r ConsoleReporter) ReportConsumerOffsets(o *store.ConsumerOffsets) {
 
 				_, _ = io.WriteString(
 					r.w,
-					fmt.Sprintf(
-						"%s %s:%d offset:%d lag:%d \n",
+					r.prefix+fmt.Sprintf(
+						"%s %s:%d offset:%d lag:%d time_lag:%s consume_rate:%.2f distance_to_oldest:%d data_loss:%t at_risk:%t status:%s \n",
 						group,
 						topic,
 						partition,
 						offset.Offset,
 						offset.Lag,
+						time.Duration(offset.TimeLag)*time.Millisecond,
+						offset.ConsumeRate,
+						offset.DistanceToOldest,
+						offset.DataLoss,
+						offset.AtRisk,
+						offset.Status,
+					),
+				)
+			}
+
+			if !hasOffsets(partitions) {
+				continue
+			}
+
+			eta := "never"
+			if secs, ok := store.EstimateCatchUp(partitions); ok {
+				eta = (time.Duration(secs) * time.Second).String()
+			}
+
+			_, _ = io.WriteString(r.w, r.prefix+fmt.Sprintf("%s %s eta:%s \n", group, topic, eta))
+		}
+	}
+}
+
+// ReportClusterHealth reports a snapshot of the cluster partition health.
+func (r ConsoleReporter) ReportClusterHealth(h *store.ClusterHealth) {
+	_, _ = io.WriteString(
+		r.w,
+		r.prefix+fmt.Sprintf(
+			"cluster under_replicated:%d under_min_isr:%d offline:%d non_preferred_leader:%d \n",
+			h.UnderReplicated,
+			h.UnderMinIsr,
+			h.Offline,
+			h.NonPreferredLeader,
+		),
+	)
+
+	for topic, th := range h.Topics {
+		_, _ = io.WriteString(
+			r.w,
+			r.prefix+fmt.Sprintf(
+				"%s partitions:%d under_replicated:%d under_min_isr:%d offline:%d non_preferred_leader:%d \n",
+				topic,
+				th.Partitions,
+				len(th.UnderReplicated),
+				len(th.UnderMinIsr),
+				len(th.Offline),
+				len(th.NonPreferredLeader),
+			),
+		)
+	}
+}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+func (r ConsoleReporter) ReportBrokerLogDirs(l *store.BrokerLogDirs) {
+	for broker, dirs := range *l {
+		for _, dir := range dirs.LogDirs {
+			_, _ = io.WriteString(r.w, r.prefix+fmt.Sprintf("broker:%d %s size:%d \n", broker, dir.Path, dir.Size()))
+
+			for _, replica := range dir.Replicas {
+				_, _ = io.WriteString(
+					r.w,
+					r.prefix+fmt.Sprintf(
+						"broker:%d %s %s:%d size:%d offset_lag:%d \n",
+						broker,
+						dir.Path,
+						replica.Topic,
+						replica.Partition,
+						replica.Size,
+						replica.OffsetLag,
 					),
 				)
 			}
 		}
 	}
 }
+
+// ReportClusterSummary reports a summary of the cluster brokers.
+func (r ConsoleReporter) ReportClusterSummary(s *kafka.ClusterSummary) {
+	_, _ = io.WriteString(
+		r.w,
+		r.prefix+fmt.Sprintf(
+			"cluster controller:%d brokers:%d connected_brokers:%d \n",
+			s.ControllerID,
+			s.Brokers,
+			s.ConnectedBrokers,
+		),
+	)
+}
+
+// hasOffsets determines if any of the partitions has an offset.
+func hasOffsets(partitions []*store.ConsumerOffset) bool {
+	for _, offset := range partitions {
+		if offset != nil {
+			return true
+		}
+	}
+
+	return false
+}
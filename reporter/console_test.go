@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/reporter"
 	"github.com/msales/kage/store"
 	"github.com/stretchr/testify/assert"
@@ -20,12 +21,13 @@ func TestConsoleReporter_ReportBrokerOffsets(t *testing.T) {
 				OldestOffset: 0,
 				NewestOffset: 1000,
 				Timestamp:    time.Now().Unix() * 1000,
+				ProduceRate:  12.5,
 			},
 		},
 	}
 	r.ReportBrokerOffsets(offsets)
 
-	assert.Equal(t, "test:0 oldest:0 newest:1000 available:1000 \n", buf.String())
+	assert.Equal(t, "test:0 oldest:0 newest:1000 available:1000 produce_rate:12.50 \n", buf.String())
 }
 
 func TestConsoleReporter_ReportBrokerMetadata(t *testing.T) {
@@ -47,6 +49,47 @@ func TestConsoleReporter_ReportBrokerMetadata(t *testing.T) {
 	assert.Equal(t, "test:0 leader:1 replicas:1,2 isr:1,2 \n", buf.String())
 }
 
+func TestConsoleReporter_ReportClusterHealth(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	r := reporter.NewConsoleReporter(buf)
+
+	health := &store.ClusterHealth{
+		UnderReplicated: 1,
+		Offline:         1,
+		Topics: map[string]*store.TopicHealth{
+			"test": {Partitions: 2, UnderReplicated: []int32{1}, Offline: []int32{1}},
+		},
+	}
+	r.ReportClusterHealth(health)
+
+	want := "cluster under_replicated:1 under_min_isr:0 offline:1 non_preferred_leader:0 \n" +
+		"test partitions:2 under_replicated:1 under_min_isr:0 offline:1 non_preferred_leader:0 \n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestConsoleReporter_ReportBrokerLogDirs(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	r := reporter.NewConsoleReporter(buf)
+
+	logDirs := &store.BrokerLogDirs{
+		1: {LogDirs: []store.LogDir{{Path: "/data", Replicas: []store.LogDirReplica{{Topic: "test", Partition: 0, Size: 1024, OffsetLag: 2}}}}},
+	}
+	r.ReportBrokerLogDirs(logDirs)
+
+	want := "broker:1 /data size:1024 \n" +
+		"broker:1 /data test:0 size:1024 offset_lag:2 \n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestConsoleReporter_ReportClusterSummary(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	r := reporter.NewConsoleReporter(buf)
+
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: 2, Brokers: 3, ConnectedBrokers: 2})
+
+	assert.Equal(t, "cluster controller:2 brokers:3 connected_brokers:2 \n", buf.String())
+}
+
 func TestConsoleReporter_ReportConsumerOffsets(t *testing.T) {
 	buf := bytes.NewBuffer([]byte{})
 	r := reporter.NewConsoleReporter(buf)
@@ -55,14 +98,37 @@ func TestConsoleReporter_ReportConsumerOffsets(t *testing.T) {
 		"foo": map[string][]*store.ConsumerOffset{
 			"test": {
 				{
-					Offset:    1000,
-					Lag:       100,
-					Timestamp: time.Now().Unix() * 1000,
+					Offset:      1000,
+					Lag:         100,
+					TimeLag:     1500,
+					ConsumeRate: 2,
+					DrainRate:   10,
+					Timestamp:   time.Now().Unix() * 1000,
+
+					DistanceToOldest: 800,
 				},
 			},
 		},
 	}
 	r.ReportConsumerOffsets(offsets)
 
-	assert.Equal(t, "foo test:0 offset:1000 lag:100 \n", buf.String())
+	assert.Equal(t, "foo test:0 offset:1000 lag:100 time_lag:1.5s consume_rate:2.00 distance_to_oldest:800 data_loss:false at_risk:false status:OK \nfoo test eta:10s \n", buf.String())
+}
+
+func TestConsoleReporter_Prefix(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	r := reporter.NewConsoleReporter(buf, reporter.Prefix("cluster:eu "))
+
+	offsets := &store.BrokerOffsets{
+		"test": []*store.BrokerOffset{
+			{
+				OldestOffset: 0,
+				NewestOffset: 1000,
+				Timestamp:    time.Now().Unix() * 1000,
+			},
+		},
+	}
+	r.ReportBrokerOffsets(offsets)
+
+	assert.Equal(t, "cluster:eu test:0 oldest:0 newest:1000 available:1000 produce_rate:0.00 \n", buf.String())
 }
@@ -0,0 +1,134 @@
+package reporter
+
+import (
+	"strconv"
+
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+)
+
+// gaugeFunc receives a gauge of a snapshot.
+//
+// The scope groups the gauges of the same entity, the tags are key value pairs.
+type gaugeFunc func(scope, field string, value float64, tags []string)
+
+// brokerOffsetGauges emits the gauges of the broker offsets.
+func brokerOffsetGauges(o *store.BrokerOffsets, fn gaugeFunc) {
+	for topic, partitions := range *o {
+		for partition, offset := range partitions {
+			if offset == nil {
+				continue
+			}
+
+			tags := []string{"topic", topic, "partition", strconv.Itoa(partition)}
+			fn("broker_offset", "oldest", float64(offset.OldestOffset), tags)
+			fn("broker_offset", "newest", float64(offset.NewestOffset), tags)
+			fn("broker_offset", "available", float64(offset.NewestOffset-offset.OldestOffset), tags)
+			fn("broker_offset", "produce_rate", offset.ProduceRate, tags)
+		}
+	}
+}
+
+// brokerMetadataGauges emits the gauges of the broker metadata.
+func brokerMetadataGauges(m *store.BrokerMetadata, fn gaugeFunc) {
+	for topic, partitions := range *m {
+		for partition, metadata := range partitions {
+			if metadata == nil {
+				continue
+			}
+
+			leaders := 1
+			if metadata.Leader < 0 {
+				leaders = 0
+			}
+
+			tags := []string{"topic", topic, "partition", strconv.Itoa(partition)}
+			fn("broker_metadata", "leaders", float64(leaders), tags)
+			fn("broker_metadata", "replicas", float64(len(metadata.Replicas)), tags)
+			fn("broker_metadata", "isr", float64(len(metadata.Isr)), tags)
+		}
+	}
+}
+
+// consumerOffsetGauges emits the gauges of the consumer group offsets.
+func consumerOffsetGauges(o *store.ConsumerOffsets, fn gaugeFunc) {
+	for group, topics := range *o {
+		for topic, partitions := range topics {
+			for partition, offset := range partitions {
+				if offset == nil {
+					continue
+				}
+
+				tags := []string{"group", group, "topic", topic, "partition", strconv.Itoa(partition)}
+				fn("consumer_offset", "offset", float64(offset.Offset), tags)
+				fn("consumer_offset", "lag", float64(offset.Lag), tags)
+				fn("consumer_offset", "time_lag", float64(offset.TimeLag)/1000, tags)
+				fn("consumer_offset", "consume_rate", offset.ConsumeRate, tags)
+				fn("consumer_offset", "distance_to_oldest", float64(offset.DistanceToOldest), tags)
+				fn("consumer_offset", "data_loss", boolValue(offset.DataLoss), tags)
+				fn("consumer_offset", "at_risk", boolValue(offset.AtRisk), tags)
+				fn("consumer_offset", "status", float64(offset.Status), tags)
+			}
+
+			if !hasOffsets(partitions) {
+				continue
+			}
+
+			tags := []string{"group", group, "topic", topic}
+			eta, ok := store.EstimateCatchUp(partitions)
+			fn("consumer_catch_up", "catching_up", boolValue(ok), tags)
+			if ok {
+				fn("consumer_catch_up", "eta", eta, tags)
+			}
+		}
+	}
+}
+
+// clusterHealthGauges emits the gauges of the cluster partition health.
+func clusterHealthGauges(h *store.ClusterHealth, fn gaugeFunc) {
+	fn("cluster_health", "under_replicated", float64(h.UnderReplicated), nil)
+	fn("cluster_health", "under_min_isr", float64(h.UnderMinIsr), nil)
+	fn("cluster_health", "offline", float64(h.Offline), nil)
+	fn("cluster_health", "non_preferred_leader", float64(h.NonPreferredLeader), nil)
+
+	for topic, th := range h.Topics {
+		tags := []string{"topic", topic}
+		fn("topic_health", "partitions", float64(th.Partitions), tags)
+		fn("topic_health", "under_replicated", float64(len(th.UnderReplicated)), tags)
+		fn("topic_health", "under_min_isr", float64(len(th.UnderMinIsr)), tags)
+		fn("topic_health", "offline", float64(len(th.Offline)), tags)
+		fn("topic_health", "non_preferred_leader", float64(len(th.NonPreferredLeader)), tags)
+	}
+}
+
+// brokerLogDirGauges emits the gauges of the broker log directories.
+func brokerLogDirGauges(l *store.BrokerLogDirs, fn gaugeFunc) {
+	for broker, dirs := range *l {
+		id := strconv.Itoa(int(broker))
+		for _, dir := range dirs.LogDirs {
+			fn("log_dir", "size", float64(dir.Size()), []string{"broker", id, "path", dir.Path})
+
+			for _, replica := range dir.Replicas {
+				tags := []string{"broker", id, "topic", replica.Topic, "partition", strconv.Itoa(int(replica.Partition))}
+				fn("log_dir_replica", "size", float64(replica.Size), tags)
+				fn("log_dir_replica", "offset_lag", float64(replica.OffsetLag), tags)
+			}
+		}
+	}
+}
+
+// clusterSummaryGauges emits the gauges of the cluster summary.
+func clusterSummaryGauges(s *kafka.ClusterSummary, fn gaugeFunc) {
+	fn("cluster_summary", "controller", float64(s.ControllerID), nil)
+	fn("cluster_summary", "brokers", float64(s.Brokers), nil)
+	fn("cluster_summary", "connected_brokers", float64(s.ConnectedBrokers), nil)
+}
+
+// boolValue returns the gauge value of a boolean.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
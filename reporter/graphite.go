@@ -0,0 +1,327 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamba/pkg/log"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+)
+
+// Graphite protocols.
+const (
+	// GraphiteProtocolPlaintext is the Graphite plaintext protocol, one metric per line.
+	GraphiteProtocolPlaintext = "plaintext"
+	// GraphiteProtocolPickle is the Graphite pickle protocol, batching metrics in pickled lists.
+	GraphiteProtocolPickle = "pickle"
+)
+
+const (
+	defaultGraphiteBufferSize = 10000
+	defaultGraphiteTimeout    = 5 * time.Second
+)
+
+// GraphiteReporterFunc represents a configuration function for GraphiteReporter.
+type GraphiteReporterFunc func(r *GraphiteReporter)
+
+// GraphiteProtocol configures the protocol on a GraphiteReporter.
+func GraphiteProtocol(protocol string) GraphiteReporterFunc {
+	return func(r *GraphiteReporter) {
+		r.protocol = protocol
+	}
+}
+
+// GraphitePrefix configures the metric path prefix on a GraphiteReporter.
+func GraphitePrefix(prefix string) GraphiteReporterFunc {
+	return func(r *GraphiteReporter) {
+		r.prefix = prefix
+	}
+}
+
+// GraphiteBufferSize configures the maximum number of metrics kept while
+// Graphite is unreachable on a GraphiteReporter.
+func GraphiteBufferSize(size int) GraphiteReporterFunc {
+	return func(r *GraphiteReporter) {
+		r.bufSize = size
+	}
+}
+
+// GraphiteTimeout configures the connect and write timeout on a GraphiteReporter.
+func GraphiteTimeout(timeout time.Duration) GraphiteReporterFunc {
+	return func(r *GraphiteReporter) {
+		r.timeout = timeout
+	}
+}
+
+// GraphiteLog configures the logger on a GraphiteReporter.
+func GraphiteLog(log log.Logger) GraphiteReporterFunc {
+	return func(r *GraphiteReporter) {
+		r.log = log
+	}
+}
+
+// graphiteMetric represents a buffered Graphite metric.
+type graphiteMetric struct {
+	path      string
+	value     float64
+	timestamp int64
+}
+
+// GraphiteReporter represents a Graphite reporter.
+//
+// Metrics are buffered until they are sent, and the connection
+// is reopened on the next report when it fails.
+type GraphiteReporter struct {
+	addr     string
+	protocol string
+	prefix   string
+	bufSize  int
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	buf  []graphiteMetric
+
+	log log.Logger
+}
+
+// NewGraphiteReporter creates and returns a new GraphiteReporter sending to the given TCP address.
+func NewGraphiteReporter(addr string, opts ...GraphiteReporterFunc) (*GraphiteReporter, error) {
+	r := &GraphiteReporter{
+		addr:     addr,
+		protocol: GraphiteProtocolPlaintext,
+		bufSize:  defaultGraphiteBufferSize,
+		timeout:  defaultGraphiteTimeout,
+		log:      log.Null,
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if r.protocol != GraphiteProtocolPlaintext && r.protocol != GraphiteProtocolPickle {
+		return nil, fmt.Errorf("graphite: unknown protocol \"%s\"", r.protocol)
+	}
+
+	if r.bufSize <= 0 {
+		return nil, fmt.Errorf("graphite: buffer size must be positive")
+	}
+
+	return r, nil
+}
+
+// ReportBrokerOffsets reports a snapshot of the broker offsets.
+func (r *GraphiteReporter) ReportBrokerOffsets(o *store.BrokerOffsets) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	brokerOffsetGauges(o, r.gauge)
+	r.flush()
+}
+
+// ReportBrokerMetadata reports a snapshot of the broker metadata.
+func (r *GraphiteReporter) ReportBrokerMetadata(m *store.BrokerMetadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	brokerMetadataGauges(m, r.gauge)
+	r.flush()
+}
+
+// ReportConsumerOffsets reports a snapshot of the consumer group offsets.
+func (r *GraphiteReporter) ReportConsumerOffsets(o *store.ConsumerOffsets) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	consumerOffsetGauges(o, r.gauge)
+	r.flush()
+}
+
+// ReportClusterHealth reports a snapshot of the cluster partition health.
+func (r *GraphiteReporter) ReportClusterHealth(h *store.ClusterHealth) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clusterHealthGauges(h, r.gauge)
+	r.flush()
+}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+func (r *GraphiteReporter) ReportBrokerLogDirs(l *store.BrokerLogDirs) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	brokerLogDirGauges(l, r.gauge)
+	r.flush()
+}
+
+// ReportClusterSummary reports a summary of the cluster brokers.
+func (r *GraphiteReporter) ReportClusterSummary(s *kafka.ClusterSummary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clusterSummaryGauges(s, r.gauge)
+	r.flush()
+}
+
+// Close closes the connection to Graphite.
+func (r *GraphiteReporter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		return nil
+	}
+
+	err := r.conn.Close()
+	r.conn = nil
+
+	return err
+}
+
+// gauge buffers a gauge, sending the buffer early when it is full.
+//
+// The path is made of the tag values, as Graphite has no tags.
+func (r *GraphiteReporter) gauge(scope, field string, value float64, tags []string) {
+	if len(r.buf) >= r.bufSize && r.conn != nil {
+		r.flush()
+	}
+
+	parts := []string{scope}
+	for i := 1; i < len(tags); i += 2 {
+		parts = append(parts, graphiteReplacer.Replace(tags[i]))
+	}
+	parts = append(parts, field)
+
+	r.buf = append(r.buf, graphiteMetric{
+		path:      metricName(r.prefix, parts...),
+		value:     value,
+		timestamp: time.Now().Unix(),
+	})
+}
+
+// flush sends the buffered metrics, connecting to Graphite if needed.
+//
+// The metrics are kept in the buffer when they cannot be sent,
+// dropping the oldest ones beyond the buffer size.
+func (r *GraphiteReporter) flush() {
+	if len(r.buf) == 0 {
+		return
+	}
+
+	if r.conn == nil {
+		conn, err := net.DialTimeout("tcp", r.addr, r.timeout)
+		if err != nil {
+			r.log.Error("graphite: " + err.Error())
+			r.trim()
+			return
+		}
+		r.conn = conn
+	}
+
+	var data []byte
+	switch r.protocol {
+	case GraphiteProtocolPickle:
+		data = encodeGraphitePickle(r.buf)
+
+	default:
+		data = encodeGraphitePlaintext(r.buf)
+	}
+
+	_ = r.conn.SetWriteDeadline(time.Now().Add(r.timeout))
+	if _, err := r.conn.Write(data); err != nil {
+		r.log.Error("graphite: " + err.Error())
+
+		_ = r.conn.Close()
+		r.conn = nil
+		r.trim()
+		return
+	}
+
+	r.buf = r.buf[:0]
+}
+
+// trim drops the oldest buffered metrics beyond the buffer size.
+func (r *GraphiteReporter) trim() {
+	n := len(r.buf) - r.bufSize
+	if n <= 0 {
+		return
+	}
+
+	r.log.Error(fmt.Sprintf("graphite: buffer full, dropped %d metrics", n))
+	r.buf = append(r.buf[:0], r.buf[n:]...)
+}
+
+var graphiteReplacer = strings.NewReplacer(".", "_", " ", "_", "\t", "_", "\n", "_", "\r", "_", "/", "_")
+
+// encodeGraphitePlaintext encodes the metrics in the plaintext protocol.
+func encodeGraphitePlaintext(metrics []graphiteMetric) []byte {
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		buf.WriteString(m.path)
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatFloat(m.value, 'f', -1, 64))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(m.timestamp, 10))
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+// Pickle protocol 2 opcodes.
+const (
+	pickleProto     = 0x80
+	pickleEmptyList = ']'
+	pickleMark      = '('
+	pickleAppends   = 'e'
+	pickleUnicode   = 'X'
+	pickleInt       = 'J'
+	pickleLong      = 0x8a
+	pickleFloat     = 'G'
+	pickleTuple2    = 0x86
+	pickleStop      = '.'
+)
+
+// encodeGraphitePickle encodes the metrics in the pickle protocol.
+//
+// The payload is a pickled list of (path, (timestamp, value)) tuples,
+// preceded by its length.
+func encodeGraphitePickle(metrics []graphiteMetric) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, 0, 0})
+
+	buf.Write([]byte{pickleProto, 2, pickleEmptyList, pickleMark})
+	for _, m := range metrics {
+		buf.WriteByte(pickleUnicode)
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(m.path)))
+		buf.WriteString(m.path)
+
+		if m.timestamp >= math.MinInt32 && m.timestamp <= math.MaxInt32 {
+			buf.WriteByte(pickleInt)
+			_ = binary.Write(&buf, binary.LittleEndian, int32(m.timestamp))
+		} else {
+			buf.Write([]byte{pickleLong, 8})
+			_ = binary.Write(&buf, binary.LittleEndian, m.timestamp)
+		}
+
+		buf.WriteByte(pickleFloat)
+		_ = binary.Write(&buf, binary.BigEndian, m.value)
+
+		buf.Write([]byte{pickleTuple2, pickleTuple2})
+	}
+	buf.Write([]byte{pickleAppends, pickleStop})
+
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data, uint32(len(data)-4))
+
+	return data
+}
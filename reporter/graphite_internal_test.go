@@ -0,0 +1,55 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphiteProtocol(t *testing.T) {
+	r := &GraphiteReporter{}
+
+	GraphiteProtocol(GraphiteProtocolPickle)(r)
+
+	assert.Equal(t, r.protocol, GraphiteProtocolPickle)
+}
+
+func TestGraphitePrefix(t *testing.T) {
+	r := &GraphiteReporter{}
+
+	GraphitePrefix("kafka")(r)
+
+	assert.Equal(t, r.prefix, "kafka")
+}
+
+func TestGraphiteBufferSize(t *testing.T) {
+	r := &GraphiteReporter{}
+
+	GraphiteBufferSize(100)(r)
+
+	assert.Equal(t, r.bufSize, 100)
+}
+
+func TestGraphiteTimeout(t *testing.T) {
+	r := &GraphiteReporter{}
+
+	GraphiteTimeout(time.Second)(r)
+
+	assert.Equal(t, r.timeout, time.Second)
+}
+
+func TestEncodeGraphitePickle(t *testing.T) {
+	data := encodeGraphitePickle([]graphiteMetric{
+		{path: "a.b", value: 1.5, timestamp: 1600000000},
+		{path: "c", value: 2, timestamp: 5000000000},
+	})
+
+	want := []byte{
+		0, 0, 0, 0x39, 0x80, 2, ']', '(',
+		'X', 3, 0, 0, 0, 'a', '.', 'b', 'J', 0, 0x10, 0x5e, 0x5f, 'G', 0x3f, 0xf8, 0, 0, 0, 0, 0, 0, 0x86, 0x86,
+		'X', 1, 0, 0, 0, 'c', 0x8a, 8, 0, 0xf2, 0x05, 0x2a, 1, 0, 0, 0, 'G', 0x40, 0, 0, 0, 0, 0, 0, 0, 0x86, 0x86,
+		'e', '.',
+	}
+	assert.Equal(t, want, data)
+}
@@ -0,0 +1,152 @@
+package reporter_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/reporter"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newGraphiteListener(t *testing.T, addr string) net.Listener {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ln
+}
+
+func acceptGraphite(t *testing.T, ln net.Listener) net.Conn {
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	return conn
+}
+
+func readGraphiteLines(t *testing.T, r *bufio.Reader, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Strip the timestamp, which cannot be compared.
+		line = strings.TrimSuffix(line, "\n")
+		lines[i] = line[:strings.LastIndex(line, " ")]
+	}
+
+	return lines
+}
+
+func TestNewGraphiteReporter_UnknownProtocol(t *testing.T) {
+	_, err := reporter.NewGraphiteReporter("127.0.0.1:2003", reporter.GraphiteProtocol("foo"))
+
+	assert.Error(t, err)
+}
+
+func TestGraphiteReporter_ReportBrokerOffsets(t *testing.T) {
+	ln := newGraphiteListener(t, "127.0.0.1:0")
+	defer ln.Close()
+
+	r, err := reporter.NewGraphiteReporter(ln.Addr().String(),
+		reporter.GraphitePrefix("kafka"),
+		reporter.GraphiteLog(testutil.Logger),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	offsets := &store.BrokerOffsets{
+		"test.topic": []*store.BrokerOffset{
+			{
+				OldestOffset: 0,
+				NewestOffset: 1000,
+				Timestamp:    time.Now().Unix() * 1000,
+				ProduceRate:  12.5,
+			},
+		},
+		"nil": []*store.BrokerOffset{nil},
+	}
+	r.ReportBrokerOffsets(offsets)
+
+	conn := acceptGraphite(t, ln)
+	defer conn.Close()
+
+	lines := readGraphiteLines(t, bufio.NewReader(conn), 4)
+	assert.Equal(t, []string{
+		"kafka.broker_offset.test_topic.0.oldest 0",
+		"kafka.broker_offset.test_topic.0.newest 1000",
+		"kafka.broker_offset.test_topic.0.available 1000",
+		"kafka.broker_offset.test_topic.0.produce_rate 12.5",
+	}, lines)
+}
+
+func TestGraphiteReporter_ReportClusterSummaryPickle(t *testing.T) {
+	ln := newGraphiteListener(t, "127.0.0.1:0")
+	defer ln.Close()
+
+	r, err := reporter.NewGraphiteReporter(ln.Addr().String(),
+		reporter.GraphiteProtocol(reporter.GraphiteProtocolPickle),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: 1, Brokers: 3, ConnectedBrokers: 2})
+
+	conn := acceptGraphite(t, ln)
+	defer conn.Close()
+
+	var size uint32
+	if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []byte{0x80, 2, ']', '('}, payload[:4])
+	assert.Equal(t, []byte{'e', '.'}, payload[len(payload)-2:])
+	assert.Contains(t, string(payload), "cluster_summary.connected_brokers")
+}
+
+func TestGraphiteReporter_Reconnects(t *testing.T) {
+	ln := newGraphiteListener(t, "127.0.0.1:0")
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	r, err := reporter.NewGraphiteReporter(addr,
+		reporter.GraphiteBufferSize(2),
+		reporter.GraphiteTimeout(100*time.Millisecond),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: 1, Brokers: 3, ConnectedBrokers: 2})
+
+	ln = newGraphiteListener(t, addr)
+	defer ln.Close()
+
+	r.ReportBrokerOffsets(&store.BrokerOffsets{})
+
+	conn := acceptGraphite(t, ln)
+	defer conn.Close()
+
+	lines := readGraphiteLines(t, bufio.NewReader(conn), 2)
+	assert.Equal(t, []string{
+		"cluster_summary.brokers 3",
+		"cluster_summary.connected_brokers 2",
+	}, lines)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/hamba/pkg/log"
 	"github.com/influxdata/influxdb/client/v2"
+	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/store"
 )
 
@@ -49,6 +50,9 @@ func Tags(tags []string) InfluxReporterFunc {
 }
 
 // InfluxReporter represents an InfluxDB reporter.
+//
+// Deprecated: InfluxReporter is built on the InfluxDB 1.x client, use
+// a LineProtocolReporter with an InfluxV1Writer instead.
 type InfluxReporter struct {
 	database string
 
@@ -102,9 +106,10 @@ func (r InfluxReporter) ReportBrokerOffsets(o *store.BrokerOffsets) {
 				r.metric,
 				tags,
 				map[string]interface{}{
-					"oldest":    offset.OldestOffset,
-					"newest":    offset.NewestOffset,
-					"available": offset.NewestOffset - offset.OldestOffset,
+					"oldest":       offset.OldestOffset,
+					"newest":       offset.NewestOffset,
+					"available":    offset.NewestOffset - offset.OldestOffset,
+					"produce_rate": offset.ProduceRate,
 				},
 				time.Now(),
 			)
@@ -197,14 +202,24 @@ func (r InfluxReporter) ReportConsumerOffsets(o *store.ConsumerOffsets) {
 					r.metric,
 					tags,
 					map[string]interface{}{
-						"offset": offset.Offset,
-						"lag":    offset.Lag,
+						"offset":             offset.Offset,
+						"lag":                offset.Lag,
+						"time_lag":           float64(offset.TimeLag) / 1000,
+						"consume_rate":       offset.ConsumeRate,
+						"status":             offset.Status.String(),
+						"distance_to_oldest": offset.DistanceToOldest,
+						"data_loss":          offset.DataLoss,
+						"at_risk":            offset.AtRisk,
 					},
 					time.Now(),
 				)
 
 				pts.AddPoint(pt)
 			}
+
+			if pt := r.catchUpPoint(group, topic, partitions); pt != nil {
+				pts.AddPoint(pt)
+			}
 		}
 	}
 
@@ -212,3 +227,187 @@ func (r InfluxReporter) ReportConsumerOffsets(o *store.ConsumerOffsets) {
 		r.log.Error("influx: consumer-offsets:" + err.Error())
 	}
 }
+
+// ReportClusterHealth reports a snapshot of the cluster partition health.
+func (r InfluxReporter) ReportClusterHealth(h *store.ClusterHealth) {
+	pts, _ := client.NewBatchPoints(client.BatchPointsConfig{
+		Database:        r.database,
+		Precision:       "s",
+		RetentionPolicy: r.policy,
+	})
+
+	tags := map[string]string{
+		"type": "ClusterHealth",
+	}
+
+	for i := 0; i < len(r.tags); i += 2 {
+		tags[r.tags[i]] = r.tags[i+1]
+	}
+
+	pt, _ := client.NewPoint(
+		r.metric,
+		tags,
+		map[string]interface{}{
+			"under_replicated":     h.UnderReplicated,
+			"under_min_isr":        h.UnderMinIsr,
+			"offline":              h.Offline,
+			"non_preferred_leader": h.NonPreferredLeader,
+		},
+		time.Now(),
+	)
+	pts.AddPoint(pt)
+
+	for topic, th := range h.Topics {
+		tags := map[string]string{
+			"type":  "TopicHealth",
+			"topic": topic,
+		}
+
+		for i := 0; i < len(r.tags); i += 2 {
+			tags[r.tags[i]] = r.tags[i+1]
+		}
+
+		pt, _ := client.NewPoint(
+			r.metric,
+			tags,
+			map[string]interface{}{
+				"partitions":           th.Partitions,
+				"under_replicated":     len(th.UnderReplicated),
+				"under_min_isr":        len(th.UnderMinIsr),
+				"offline":              len(th.Offline),
+				"non_preferred_leader": len(th.NonPreferredLeader),
+			},
+			time.Now(),
+		)
+		pts.AddPoint(pt)
+	}
+
+	if err := r.client.Write(pts); err != nil {
+		r.log.Error("influx: cluster health:" + err.Error())
+	}
+}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+func (r InfluxReporter) ReportBrokerLogDirs(l *store.BrokerLogDirs) {
+	pts, _ := client.NewBatchPoints(client.BatchPointsConfig{
+		Database:        r.database,
+		Precision:       "s",
+		RetentionPolicy: r.policy,
+	})
+
+	for broker, dirs := range *l {
+		for _, dir := range dirs.LogDirs {
+			tags := map[string]string{
+				"type":   "LogDir",
+				"broker": fmt.Sprint(broker),
+				"path":   dir.Path,
+			}
+
+			for i := 0; i < len(r.tags); i += 2 {
+				tags[r.tags[i]] = r.tags[i+1]
+			}
+
+			pt, _ := client.NewPoint(
+				r.metric,
+				tags,
+				map[string]interface{}{
+					"size": dir.Size(),
+				},
+				time.Now(),
+			)
+			pts.AddPoint(pt)
+
+			for _, replica := range dir.Replicas {
+				tags := map[string]string{
+					"type":      "LogDirReplica",
+					"broker":    fmt.Sprint(broker),
+					"path":      dir.Path,
+					"topic":     replica.Topic,
+					"partition": fmt.Sprint(replica.Partition),
+				}
+
+				for i := 0; i < len(r.tags); i += 2 {
+					tags[r.tags[i]] = r.tags[i+1]
+				}
+
+				pt, _ := client.NewPoint(
+					r.metric,
+					tags,
+					map[string]interface{}{
+						"size":       replica.Size,
+						"offset_lag": replica.OffsetLag,
+						"temporary":  replica.Temporary,
+					},
+					time.Now(),
+				)
+				pts.AddPoint(pt)
+			}
+		}
+	}
+
+	if err := r.client.Write(pts); err != nil {
+		r.log.Error("influx: log dirs:" + err.Error())
+	}
+}
+
+// ReportClusterSummary reports a summary of the cluster brokers.
+func (r InfluxReporter) ReportClusterSummary(s *kafka.ClusterSummary) {
+	pts, _ := client.NewBatchPoints(client.BatchPointsConfig{
+		Database:        r.database,
+		Precision:       "s",
+		RetentionPolicy: r.policy,
+	})
+
+	tags := map[string]string{
+		"type": "ClusterSummary",
+	}
+
+	for i := 0; i < len(r.tags); i += 2 {
+		tags[r.tags[i]] = r.tags[i+1]
+	}
+
+	pt, _ := client.NewPoint(
+		r.metric,
+		tags,
+		map[string]interface{}{
+			"controller":        s.ControllerID,
+			"brokers":           s.Brokers,
+			"connected_brokers": s.ConnectedBrokers,
+		},
+		time.Now(),
+	)
+	pts.AddPoint(pt)
+
+	if err := r.client.Write(pts); err != nil {
+		r.log.Error("influx: cluster summary:" + err.Error())
+	}
+}
+
+// catchUpPoint creates the catch up estimate point of a consumer group on a topic.
+func (r InfluxReporter) catchUpPoint(group, topic string, partitions []*store.ConsumerOffset) *client.Point {
+	if !hasOffsets(partitions) {
+		return nil
+	}
+
+	tags := map[string]string{
+		"type":  "ConsumerCatchUp",
+		"group": group,
+		"topic": topic,
+	}
+
+	for i := 0; i < len(r.tags); i += 2 {
+		tags[r.tags[i]] = r.tags[i+1]
+	}
+
+	eta, ok := store.EstimateCatchUp(partitions)
+	fields := map[string]interface{}{
+		"catching_up": ok,
+	}
+	if ok {
+		fields["eta"] = eta
+	}
+
+	pt, _ := client.NewPoint(r.metric, tags, fields, time.Now())
+
+	return pt
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/client/v2"
+	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/reporter"
 	"github.com/msales/kage/store"
 	"github.com/msales/kage/testutil"
@@ -18,6 +19,9 @@ func TestInfluxReporter_ReportBrokerOffsets(t *testing.T) {
 	c.On("Write", mock.AnythingOfType("*client.batchpoints")).Return(nil).Run(func(args mock.Arguments) {
 		bp := args.Get(0).(client.BatchPoints)
 		assert.Len(t, bp.Points(), 1)
+
+		fields, _ := bp.Points()[0].Fields()
+		assert.Equal(t, 12.5, fields["produce_rate"])
 	})
 
 	r := reporter.NewInfluxReporter(c,
@@ -31,6 +35,7 @@ func TestInfluxReporter_ReportBrokerOffsets(t *testing.T) {
 				OldestOffset: 0,
 				NewestOffset: 1000,
 				Timestamp:    time.Now().Unix() * 1000,
+				ProduceRate:  12.5,
 			},
 		},
 		"nil": []*store.BrokerOffset{nil},
@@ -66,11 +71,102 @@ func TestInfluxReporter_ReportBrokerMetadata(t *testing.T) {
 
 }
 
-func TestInfluxReporter_ReportConsumerOffsets(t *testing.T) {
+func TestInfluxReporter_ReportClusterHealth(t *testing.T) {
+	c := new(mocks.MockInfluxClient)
+	c.On("Write", mock.AnythingOfType("*client.batchpoints")).Return(nil).Run(func(args mock.Arguments) {
+		bp := args.Get(0).(client.BatchPoints)
+		assert.Len(t, bp.Points(), 2)
+
+		fields, _ := bp.Points()[0].Fields()
+		assert.Equal(t, map[string]interface{}{
+			"under_replicated":     int64(1),
+			"under_min_isr":        int64(0),
+			"offline":              int64(1),
+			"non_preferred_leader": int64(0),
+		}, fields)
+
+		fields, _ = bp.Points()[1].Fields()
+		assert.Equal(t, int64(2), fields["partitions"])
+		assert.Equal(t, int64(1), fields["offline"])
+	})
+
+	r := reporter.NewInfluxReporter(c,
+		reporter.Tags([]string{"test", "test"}),
+		reporter.Log(testutil.Logger),
+	)
+
+	health := &store.ClusterHealth{
+		UnderReplicated: 1,
+		Offline:         1,
+		Topics: map[string]*store.TopicHealth{
+			"test": {Partitions: 2, UnderReplicated: []int32{1}, Offline: []int32{1}},
+		},
+	}
+	r.ReportClusterHealth(health)
+}
+
+func TestInfluxReporter_ReportBrokerLogDirs(t *testing.T) {
+	c := new(mocks.MockInfluxClient)
+	c.On("Write", mock.AnythingOfType("*client.batchpoints")).Return(nil).Run(func(args mock.Arguments) {
+		bp := args.Get(0).(client.BatchPoints)
+		assert.Len(t, bp.Points(), 3)
+
+		fields, _ := bp.Points()[0].Fields()
+		assert.Equal(t, map[string]interface{}{"size": int64(1536)}, fields)
+
+		fields, _ = bp.Points()[1].Fields()
+		assert.Equal(t, map[string]interface{}{"size": int64(1024), "offset_lag": int64(2), "temporary": false}, fields)
+	})
+
+	r := reporter.NewInfluxReporter(c,
+		reporter.Tags([]string{"test", "test"}),
+		reporter.Log(testutil.Logger),
+	)
+
+	logDirs := &store.BrokerLogDirs{
+		1: {LogDirs: []store.LogDir{{Path: "/data", Replicas: []store.LogDirReplica{
+			{Topic: "test", Partition: 0, Size: 1024, OffsetLag: 2},
+			{Topic: "test", Partition: 1, Size: 512},
+		}}}},
+	}
+	r.ReportBrokerLogDirs(logDirs)
+}
+
+func TestInfluxReporter_ReportClusterSummary(t *testing.T) {
 	c := new(mocks.MockInfluxClient)
 	c.On("Write", mock.AnythingOfType("*client.batchpoints")).Return(nil).Run(func(args mock.Arguments) {
 		bp := args.Get(0).(client.BatchPoints)
 		assert.Len(t, bp.Points(), 1)
+
+		fields, _ := bp.Points()[0].Fields()
+		assert.Equal(t, map[string]interface{}{
+			"controller":        int64(2),
+			"brokers":           int64(3),
+			"connected_brokers": int64(2),
+		}, fields)
+	})
+
+	r := reporter.NewInfluxReporter(c,
+		reporter.Tags([]string{"test", "test"}),
+		reporter.Log(testutil.Logger),
+	)
+
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: 2, Brokers: 3, ConnectedBrokers: 2})
+}
+
+func TestInfluxReporter_ReportConsumerOffsets(t *testing.T) {
+	c := new(mocks.MockInfluxClient)
+	c.On("Write", mock.AnythingOfType("*client.batchpoints")).Return(nil).Run(func(args mock.Arguments) {
+		bp := args.Get(0).(client.BatchPoints)
+		assert.Len(t, bp.Points(), 2)
+
+		fields, _ := bp.Points()[0].Fields()
+		assert.Equal(t, 2.5, fields["consume_rate"])
+		assert.Equal(t, int64(-10), fields["distance_to_oldest"])
+		assert.Equal(t, true, fields["data_loss"])
+
+		fields, _ = bp.Points()[1].Fields()
+		assert.Equal(t, map[string]interface{}{"catching_up": true, "eta": float64(20)}, fields)
 	})
 
 	r := reporter.NewInfluxReporter(c,
@@ -82,9 +178,14 @@ func TestInfluxReporter_ReportConsumerOffsets(t *testing.T) {
 		"foo": map[string][]*store.ConsumerOffset{
 			"test": {
 				{
-					Offset:    1000,
-					Lag:       100,
-					Timestamp: time.Now().Unix() * 1000,
+					Offset:      1000,
+					Lag:         100,
+					ConsumeRate: 2.5,
+					DrainRate:   5,
+					Timestamp:   time.Now().Unix() * 1000,
+
+					DistanceToOldest: -10,
+					DataLoss:         true,
 				},
 			},
 			"nil": {nil},
@@ -0,0 +1,384 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/hamba/pkg/log"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+)
+
+// Kafka record formats.
+const (
+	// KafkaFormatJSON encodes the records as JSON objects.
+	KafkaFormatJSON = "json"
+	// KafkaFormatAvro encodes the records in the Avro binary encoding of KafkaAvroSchema.
+	KafkaFormatAvro = "avro"
+)
+
+// KafkaSchemaVersion is the version of the record schema, set on every record.
+const KafkaSchemaVersion = 1
+
+// KafkaAvroSchema is the Avro schema of the records.
+//
+// The schema is a union of the record types, so a record
+// starts with the index of its type.
+const KafkaAvroSchema = `[
+  {"type": "record", "name": "BrokerOffset", "namespace": "kage", "fields": [
+    {"name": "schema_version", "type": "int"},
+    {"name": "cluster", "type": "string"},
+    {"name": "topic", "type": "string"},
+    {"name": "partition", "type": "int"},
+    {"name": "oldest", "type": "long"},
+    {"name": "newest", "type": "long"},
+    {"name": "produce_rate", "type": "double"},
+    {"name": "timestamp", "type": "long"}
+  ]},
+  {"type": "record", "name": "BrokerMetadata", "namespace": "kage", "fields": [
+    {"name": "schema_version", "type": "int"},
+    {"name": "cluster", "type": "string"},
+    {"name": "topic", "type": "string"},
+    {"name": "partition", "type": "int"},
+    {"name": "leader", "type": "int"},
+    {"name": "replicas", "type": {"type": "array", "items": "int"}},
+    {"name": "isr", "type": {"type": "array", "items": "int"}},
+    {"name": "timestamp", "type": "long"}
+  ]},
+  {"type": "record", "name": "ConsumerOffset", "namespace": "kage", "fields": [
+    {"name": "schema_version", "type": "int"},
+    {"name": "cluster", "type": "string"},
+    {"name": "group", "type": "string"},
+    {"name": "topic", "type": "string"},
+    {"name": "partition", "type": "int"},
+    {"name": "offset", "type": "long"},
+    {"name": "lag", "type": "long"},
+    {"name": "time_lag", "type": "long"},
+    {"name": "consume_rate", "type": "double"},
+    {"name": "distance_to_oldest", "type": "long"},
+    {"name": "data_loss", "type": "boolean"},
+    {"name": "at_risk", "type": "boolean"},
+    {"name": "status", "type": "string"},
+    {"name": "timestamp", "type": "long"}
+  ]}
+]`
+
+// KafkaReporterFunc represents a configuration function for KafkaReporter.
+type KafkaReporterFunc func(r *KafkaReporter)
+
+// KafkaFormat configures the record format on a KafkaReporter.
+func KafkaFormat(format string) KafkaReporterFunc {
+	return func(r *KafkaReporter) {
+		r.format = format
+	}
+}
+
+// KafkaCluster configures the cluster name set on the records of a KafkaReporter.
+func KafkaCluster(cluster string) KafkaReporterFunc {
+	return func(r *KafkaReporter) {
+		r.cluster = cluster
+	}
+}
+
+// KafkaLog configures the logger on a KafkaReporter.
+func KafkaLog(log log.Logger) KafkaReporterFunc {
+	return func(r *KafkaReporter) {
+		r.log = log
+	}
+}
+
+// KafkaReporter represents a reporter producing the snapshots to a Kafka topic.
+//
+// Every partition of a snapshot is produced as a record, keyed by topic
+// for the broker offsets and metadata, and by group for the consumer offsets.
+type KafkaReporter struct {
+	producer sarama.SyncProducer
+	topic    string
+
+	format  string
+	cluster string
+
+	log log.Logger
+}
+
+// NewKafkaReporter creates and returns a new KafkaReporter.
+func NewKafkaReporter(producer sarama.SyncProducer, topic string, opts ...KafkaReporterFunc) (*KafkaReporter, error) {
+	r := &KafkaReporter{
+		producer: producer,
+		topic:    topic,
+		format:   KafkaFormatJSON,
+		log:      log.Null,
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if r.format != KafkaFormatJSON && r.format != KafkaFormatAvro {
+		return nil, fmt.Errorf("kafka-reporter: unknown format \"%s\"", r.format)
+	}
+
+	if r.topic == "" {
+		return nil, fmt.Errorf("kafka-reporter: topic must be set")
+	}
+
+	return r, nil
+}
+
+// ReportBrokerOffsets reports a snapshot of the broker offsets.
+func (r KafkaReporter) ReportBrokerOffsets(o *store.BrokerOffsets) {
+	var records []kafkaRecord
+	for topic, partitions := range *o {
+		for partition, offset := range partitions {
+			if offset == nil {
+				continue
+			}
+
+			records = append(records, &kafkaBrokerOffset{
+				Type:          "BrokerOffset",
+				SchemaVersion: KafkaSchemaVersion,
+				Cluster:       r.cluster,
+				Topic:         topic,
+				Partition:     int32(partition),
+				Oldest:        offset.OldestOffset,
+				Newest:        offset.NewestOffset,
+				ProduceRate:   offset.ProduceRate,
+				Timestamp:     offset.Timestamp,
+			})
+		}
+	}
+
+	r.send("broker-offsets", records)
+}
+
+// ReportBrokerMetadata reports a snapshot of the broker metadata.
+func (r KafkaReporter) ReportBrokerMetadata(m *store.BrokerMetadata) {
+	var records []kafkaRecord
+	for topic, partitions := range *m {
+		for partition, metadata := range partitions {
+			if metadata == nil {
+				continue
+			}
+
+			records = append(records, &kafkaBrokerMetadata{
+				Type:          "BrokerMetadata",
+				SchemaVersion: KafkaSchemaVersion,
+				Cluster:       r.cluster,
+				Topic:         topic,
+				Partition:     int32(partition),
+				Leader:        metadata.Leader,
+				Replicas:      int32Slice(metadata.Replicas),
+				Isr:           int32Slice(metadata.Isr),
+				Timestamp:     metadata.Timestamp,
+			})
+		}
+	}
+
+	r.send("broker-metadata", records)
+}
+
+// ReportConsumerOffsets reports a snapshot of the consumer group offsets.
+func (r KafkaReporter) ReportConsumerOffsets(o *store.ConsumerOffsets) {
+	var records []kafkaRecord
+	for group, topics := range *o {
+		for topic, partitions := range topics {
+			for partition, offset := range partitions {
+				if offset == nil {
+					continue
+				}
+
+				records = append(records, &kafkaConsumerOffset{
+					Type:             "ConsumerOffset",
+					SchemaVersion:    KafkaSchemaVersion,
+					Cluster:          r.cluster,
+					Group:            group,
+					Topic:            topic,
+					Partition:        int32(partition),
+					Offset:           offset.Offset,
+					Lag:              offset.Lag,
+					TimeLag:          offset.TimeLag,
+					ConsumeRate:      offset.ConsumeRate,
+					DistanceToOldest: offset.DistanceToOldest,
+					DataLoss:         offset.DataLoss,
+					AtRisk:           offset.AtRisk,
+					Status:           offset.Status.String(),
+					Timestamp:        offset.Timestamp,
+				})
+			}
+		}
+	}
+
+	r.send("consumer-offsets", records)
+}
+
+// ReportClusterHealth reports a snapshot of the cluster partition health.
+//
+// The cluster health is derived from the broker metadata, so it is not produced.
+func (r KafkaReporter) ReportClusterHealth(h *store.ClusterHealth) {}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+//
+// The log directories are not produced.
+func (r KafkaReporter) ReportBrokerLogDirs(l *store.BrokerLogDirs) {}
+
+// ReportClusterSummary reports a summary of the cluster brokers.
+//
+// The cluster summary is not produced.
+func (r KafkaReporter) ReportClusterSummary(s *kafka.ClusterSummary) {}
+
+// Close closes the producer.
+func (r KafkaReporter) Close() error {
+	return r.producer.Close()
+}
+
+// send encodes and produces the records.
+func (r KafkaReporter) send(name string, records []kafkaRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	msgs := make([]*sarama.ProducerMessage, 0, len(records))
+	for _, rec := range records {
+		value, err := r.encode(rec)
+		if err != nil {
+			r.log.Error("kafka-reporter: " + name + ": " + err.Error())
+			continue
+		}
+
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic: r.topic,
+			Key:   sarama.StringEncoder(rec.key()),
+			Value: sarama.ByteEncoder(value),
+		})
+	}
+
+	if err := r.producer.SendMessages(msgs); err != nil {
+		r.log.Error("kafka-reporter: " + name + ": " + err.Error())
+	}
+}
+
+// encode encodes a record in the reporter format.
+func (r KafkaReporter) encode(rec kafkaRecord) ([]byte, error) {
+	if r.format == KafkaFormatAvro {
+		e := &avroEncoder{}
+		rec.encodeAvro(e)
+		return e.Bytes(), nil
+	}
+
+	return json.Marshal(rec)
+}
+
+// kafkaRecord represents a record produced by the KafkaReporter.
+type kafkaRecord interface {
+	// key returns the record key.
+	key() string
+
+	// encodeAvro encodes the record as a branch of KafkaAvroSchema.
+	encodeAvro(e *avroEncoder)
+}
+
+type kafkaBrokerOffset struct {
+	SchemaVersion int32   `json:"schema_version"`
+	Type          string  `json:"type"`
+	Cluster       string  `json:"cluster"`
+	Topic         string  `json:"topic"`
+	Partition     int32   `json:"partition"`
+	Oldest        int64   `json:"oldest"`
+	Newest        int64   `json:"newest"`
+	ProduceRate   float64 `json:"produce_rate"`
+	Timestamp     int64   `json:"timestamp"`
+}
+
+func (r *kafkaBrokerOffset) key() string {
+	return r.Topic
+}
+
+func (r *kafkaBrokerOffset) encodeAvro(e *avroEncoder) {
+	e.writeLong(0)
+	e.writeInt(r.SchemaVersion)
+	e.writeString(r.Cluster)
+	e.writeString(r.Topic)
+	e.writeInt(r.Partition)
+	e.writeLong(r.Oldest)
+	e.writeLong(r.Newest)
+	e.writeDouble(r.ProduceRate)
+	e.writeLong(r.Timestamp)
+}
+
+type kafkaBrokerMetadata struct {
+	SchemaVersion int32   `json:"schema_version"`
+	Type          string  `json:"type"`
+	Cluster       string  `json:"cluster"`
+	Topic         string  `json:"topic"`
+	Partition     int32   `json:"partition"`
+	Leader        int32   `json:"leader"`
+	Replicas      []int32 `json:"replicas"`
+	Isr           []int32 `json:"isr"`
+	Timestamp     int64   `json:"timestamp"`
+}
+
+func (r *kafkaBrokerMetadata) key() string {
+	return r.Topic
+}
+
+func (r *kafkaBrokerMetadata) encodeAvro(e *avroEncoder) {
+	e.writeLong(1)
+	e.writeInt(r.SchemaVersion)
+	e.writeString(r.Cluster)
+	e.writeString(r.Topic)
+	e.writeInt(r.Partition)
+	e.writeInt(r.Leader)
+	e.writeIntArray(r.Replicas)
+	e.writeIntArray(r.Isr)
+	e.writeLong(r.Timestamp)
+}
+
+type kafkaConsumerOffset struct {
+	SchemaVersion    int32   `json:"schema_version"`
+	Type             string  `json:"type"`
+	Cluster          string  `json:"cluster"`
+	Group            string  `json:"group"`
+	Topic            string  `json:"topic"`
+	Partition        int32   `json:"partition"`
+	Offset           int64   `json:"offset"`
+	Lag              int64   `json:"lag"`
+	TimeLag          int64   `json:"time_lag"`
+	ConsumeRate      float64 `json:"consume_rate"`
+	DistanceToOldest int64   `json:"distance_to_oldest"`
+	DataLoss         bool    `json:"data_loss"`
+	AtRisk           bool    `json:"at_risk"`
+	Status           string  `json:"status"`
+	Timestamp        int64   `json:"timestamp"`
+}
+
+func (r *kafkaConsumerOffset) key() string {
+	return r.Group
+}
+
+func (r *kafkaConsumerOffset) encodeAvro(e *avroEncoder) {
+	e.writeLong(2)
+	e.writeInt(r.SchemaVersion)
+	e.writeString(r.Cluster)
+	e.writeString(r.Group)
+	e.writeString(r.Topic)
+	e.writeInt(r.Partition)
+	e.writeLong(r.Offset)
+	e.writeLong(r.Lag)
+	e.writeLong(r.TimeLag)
+	e.writeDouble(r.ConsumeRate)
+	e.writeLong(r.DistanceToOldest)
+	e.writeBool(r.DataLoss)
+	e.writeBool(r.AtRisk)
+	e.writeString(r.Status)
+	e.writeLong(r.Timestamp)
+}
+
+// int32Slice returns the slice, or an empty slice when nil.
+func int32Slice(s []int32) []int32 {
+	if s == nil {
+		return []int32{}
+	}
+
+	return s
+}
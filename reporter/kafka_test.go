@@ -0,0 +1,152 @@
+package reporter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Shopify/sarama/mocks"
+	"github.com/msales/kage/reporter"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKafkaReporter_Errors(t *testing.T) {
+	p := mocks.NewSyncProducer(t, nil)
+	defer p.Close()
+
+	_, err := reporter.NewKafkaReporter(p, "kage", reporter.KafkaFormat("foo"))
+	assert.Error(t, err)
+
+	_, err = reporter.NewKafkaReporter(p, "")
+	assert.Error(t, err)
+}
+
+func TestKafkaReporter_ReportBrokerOffsets(t *testing.T) {
+	p := mocks.NewSyncProducer(t, nil)
+	p.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+		var rec map[string]interface{}
+		if err := json.Unmarshal(val, &rec); err != nil {
+			return err
+		}
+
+		assert.Equal(t, map[string]interface{}{
+			"schema_version": float64(reporter.KafkaSchemaVersion),
+			"type":           "BrokerOffset",
+			"cluster":        "eu",
+			"topic":          "test",
+			"partition":      float64(0),
+			"oldest":         float64(10),
+			"newest":         float64(1000),
+			"produce_rate":   12.5,
+			"timestamp":      float64(1000),
+		}, rec)
+		return nil
+	})
+
+	r, err := reporter.NewKafkaReporter(p, "kage",
+		reporter.KafkaCluster("eu"),
+		reporter.KafkaLog(testutil.Logger),
+	)
+	assert.NoError(t, err)
+
+	offsets := &store.BrokerOffsets{
+		"test": []*store.BrokerOffset{
+			{
+				OldestOffset: 10,
+				NewestOffset: 1000,
+				Timestamp:    1000,
+				ProduceRate:  12.5,
+			},
+		},
+		"nil": []*store.BrokerOffset{nil},
+	}
+	r.ReportBrokerOffsets(offsets)
+
+	assert.NoError(t, r.Close())
+}
+
+func TestKafkaReporter_ReportBrokerMetadata(t *testing.T) {
+	p := mocks.NewSyncProducer(t, nil)
+	p.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+		assert.JSONEq(t, `{"schema_version":1,"type":"BrokerMetadata","cluster":"","topic":"test","partition":0,"leader":1,"replicas":[1,2],"isr":[],"timestamp":1000}`, string(val))
+		return nil
+	})
+
+	r, err := reporter.NewKafkaReporter(p, "kage")
+	assert.NoError(t, err)
+
+	metadata := &store.BrokerMetadata{
+		"test": []*store.Metadata{
+			{
+				Leader:    1,
+				Replicas:  []int32{1, 2},
+				Timestamp: 1000,
+			},
+		},
+	}
+	r.ReportBrokerMetadata(metadata)
+
+	assert.NoError(t, r.Close())
+}
+
+func TestKafkaReporter_ReportConsumerOffsetsAvro(t *testing.T) {
+	p := mocks.NewSyncProducer(t, nil)
+	p.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+		want := []byte{
+			0x04,           // union branch 2, ConsumerOffset
+			0x02,           // schema_version 1
+			0x04, 'e', 'u', // cluster
+			0x06, 'f', 'o', 'o', // group
+			0x08, 't', 'e', 's', 't', // topic
+			0x00,       // partition 0
+			0xd0, 0x0f, // offset 1000
+			0xc8, 0x01, // lag 100
+			0xd0, 0x0f, // time_lag 1000
+			0, 0, 0, 0, 0, 0, 0x24, 0x40, // consume_rate 10
+			0x00,           // distance_to_oldest 0
+			0x00,           // data_loss false
+			0x00,           // at_risk false
+			0x04, 'O', 'K', // status
+			0xd0, 0x0f, // timestamp 1000
+		}
+		assert.Equal(t, want, val)
+		return nil
+	})
+
+	r, err := reporter.NewKafkaReporter(p, "kage",
+		reporter.KafkaFormat(reporter.KafkaFormatAvro),
+		reporter.KafkaCluster("eu"),
+	)
+	assert.NoError(t, err)
+
+	offsets := &store.ConsumerOffsets{
+		"foo": map[string][]*store.ConsumerOffset{
+			"test": {
+				{
+					Offset:      1000,
+					Lag:         100,
+					TimeLag:     1000,
+					ConsumeRate: 10,
+					Status:      store.ConsumerStatusOK,
+					Timestamp:   1000,
+				},
+			},
+		},
+	}
+	r.ReportConsumerOffsets(offsets)
+
+	assert.NoError(t, r.Close())
+}
+
+func TestKafkaReporter_ReportEmpty(t *testing.T) {
+	p := mocks.NewSyncProducer(t, nil)
+
+	r, err := reporter.NewKafkaReporter(p, "kage")
+	assert.NoError(t, err)
+
+	r.ReportBrokerOffsets(&store.BrokerOffsets{})
+	r.ReportClusterHealth(&store.ClusterHealth{})
+
+	assert.NoError(t, r.Close())
+}
@@ -0,0 +1,602 @@
+package reporter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hamba/pkg/log"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+)
+
+// defaultUDPPayloadSize is the default maximum size of a UDP packet, fitting an
+// ethernet MTU without fragmentation.
+const defaultUDPPayloadSize = 1432
+
+// LineWriter represents a writer of InfluxDB line protocol.
+type LineWriter interface {
+	// WriteLines writes a batch of lines with nanosecond timestamps.
+	WriteLines(lines []byte) error
+}
+
+// InfluxV2Writer writes line protocol to the InfluxDB 2.x write API.
+type InfluxV2Writer struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewInfluxV2Writer creates and returns a new InfluxV2Writer writing to the bucket of the org.
+func NewInfluxV2Writer(addr, org, bucket, token string, client *http.Client) *InfluxV2Writer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	q := url.Values{}
+	q.Set("org", org)
+	q.Set("bucket", bucket)
+	q.Set("precision", "ns")
+
+	return &InfluxV2Writer{
+		url:    strings.TrimRight(addr, "/") + "/api/v2/write?" + q.Encode(),
+		token:  token,
+		client: client,
+	}
+}
+
+// WriteLines writes a batch of lines.
+func (w *InfluxV2Writer) WriteLines(lines []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(lines))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+
+	return doLineRequest(w.client, req)
+}
+
+// InfluxV1Writer writes line protocol to the InfluxDB 1.x write API.
+type InfluxV1Writer struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewInfluxV1Writer creates and returns a new InfluxV1Writer writing to the database and retention policy.
+func NewInfluxV1Writer(addr, database, policy, username, password string, client *http.Client) *InfluxV1Writer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	q := url.Values{}
+	q.Set("db", database)
+	if policy != "" {
+		q.Set("rp", policy)
+	}
+	q.Set("precision", "ns")
+
+	return &InfluxV1Writer{
+		url:      strings.TrimRight(addr, "/") + "/write?" + q.Encode(),
+		username: username,
+		password: password,
+		client:   client,
+	}
+}
+
+// WriteLines writes a batch of lines.
+func (w *InfluxV1Writer) WriteLines(lines []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(lines))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	return doLineRequest(w.client, req)
+}
+
+// doLineRequest sends a write request, returning the error message of the server on failure.
+func doLineRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// UDPLineWriter writes line protocol over UDP, e.g. to InfluxDB 1.x or a Telegraf listener.
+type UDPLineWriter struct {
+	conn        net.Conn
+	payloadSize int
+}
+
+// NewUDPLineWriter creates and returns a new UDPLineWriter sending packets of
+// at most payloadSize bytes to the address.
+func NewUDPLineWriter(addr string, payloadSize int) (*UDPLineWriter, error) {
+	if payloadSize <= 0 {
+		payloadSize = defaultUDPPayloadSize
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UDPLineWriter{
+		conn:        conn,
+		payloadSize: payloadSize,
+	}, nil
+}
+
+// WriteLines writes a batch of lines, splitting it into packets on line boundaries.
+//
+// A line larger than the payload size is sent in its own packet.
+func (w *UDPLineWriter) WriteLines(lines []byte) error {
+	for len(lines) > 0 {
+		n := len(lines)
+		if n > w.payloadSize {
+			n = bytes.LastIndexByte(lines[:w.payloadSize], '\n') + 1
+			if n == 0 {
+				n = bytes.IndexByte(lines, '\n') + 1
+			}
+			if n == 0 {
+				n = len(lines)
+			}
+		}
+
+		if _, err := w.conn.Write(lines[:n]); err != nil {
+			return err
+		}
+		lines = lines[n:]
+	}
+
+	return nil
+}
+
+// Close closes the connection.
+func (w *UDPLineWriter) Close() error {
+	return w.conn.Close()
+}
+
+// LineProtocolReporterFunc represents a configuration function for LineProtocolReporter.
+type LineProtocolReporterFunc func(r *LineProtocolReporter)
+
+// LineMetric configures the measurement name on a LineProtocolReporter.
+func LineMetric(metric string) LineProtocolReporterFunc {
+	return func(r *LineProtocolReporter) {
+		r.metric = metric
+	}
+}
+
+// LineTags configures the additional tags on a LineProtocolReporter.
+func LineTags(tags []string) LineProtocolReporterFunc {
+	return func(r *LineProtocolReporter) {
+		r.tags = tags
+	}
+}
+
+// LineLog configures the logger on a LineProtocolReporter.
+func LineLog(log log.Logger) LineProtocolReporterFunc {
+	return func(r *LineProtocolReporter) {
+		r.log = log
+	}
+}
+
+// LineProtocolReporter represents an InfluxDB line protocol reporter.
+//
+// It writes the same points as the InfluxReporter, timestamped with the
+// time the state was recorded in the store when known.
+type LineProtocolReporter struct {
+	w LineWriter
+
+	metric string
+	tags   []string
+
+	log log.Logger
+}
+
+// NewLineProtocolReporter creates and returns a new LineProtocolReporter.
+func NewLineProtocolReporter(w LineWriter, opts ...LineProtocolReporterFunc) *LineProtocolReporter {
+	r := &LineProtocolReporter{
+		w:      w,
+		metric: "kafka",
+		log:    log.Null,
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r
+}
+
+// ReportBrokerOffsets reports a snapshot of the broker offsets.
+func (r LineProtocolReporter) ReportBrokerOffsets(o *store.BrokerOffsets) {
+	b := r.newBatch()
+
+	for topic, partitions := range *o {
+		for partition, offset := range partitions {
+			if offset == nil {
+				continue
+			}
+
+			b.point(
+				map[string]string{
+					"type":      "BrokerOffset",
+					"topic":     topic,
+					"partition": strconv.Itoa(partition),
+				},
+				map[string]interface{}{
+					"oldest":       offset.OldestOffset,
+					"newest":       offset.NewestOffset,
+					"available":    offset.NewestOffset - offset.OldestOffset,
+					"produce_rate": offset.ProduceRate,
+				},
+				offset.Timestamp,
+			)
+		}
+	}
+
+	r.write("offsets", b)
+}
+
+// ReportBrokerMetadata reports a snapshot of the broker metadata.
+func (r LineProtocolReporter) ReportBrokerMetadata(m *store.BrokerMetadata) {
+	b := r.newBatch()
+
+	for topic, partitions := range *m {
+		for partition, metadata := range partitions {
+			if metadata == nil {
+				continue
+			}
+
+			leaders := 1
+			if metadata.Leader < 0 {
+				leaders = 0
+			}
+
+			b.point(
+				map[string]string{
+					"type":      "BrokerMetadata",
+					"topic":     topic,
+					"partition": strconv.Itoa(partition),
+				},
+				map[string]interface{}{
+					"leaders":  leaders,
+					"replicas": len(metadata.Replicas),
+					"isr":      len(metadata.Isr),
+					"isr_diff": math.Abs(float64(len(metadata.Isr) - len(metadata.Replicas))),
+				},
+				metadata.Timestamp,
+			)
+		}
+	}
+
+	r.write("metadata", b)
+}
+
+// ReportConsumerOffsets reports a snapshot of the consumer group offsets.
+func (r LineProtocolReporter) ReportConsumerOffsets(o *store.ConsumerOffsets) {
+	b := r.newBatch()
+
+	for group, topics := range *o {
+		for topic, partitions := range topics {
+			var latest int64
+			for partition, offset := range partitions {
+				if offset == nil {
+					continue
+				}
+
+				if offset.Timestamp > latest {
+					latest = offset.Timestamp
+				}
+
+				b.point(
+					map[string]string{
+						"type":      "ConsumerOffset",
+						"group":     group,
+						"topic":     topic,
+						"partition": strconv.Itoa(partition),
+					},
+					map[string]interface{}{
+						"offset":             offset.Offset,
+						"lag":                offset.Lag,
+						"time_lag":           float64(offset.TimeLag) / 1000,
+						"consume_rate":       offset.ConsumeRate,
+						"status":             offset.Status.String(),
+						"distance_to_oldest": offset.DistanceToOldest,
+						"data_loss":          offset.DataLoss,
+						"at_risk":            offset.AtRisk,
+					},
+					offset.Timestamp,
+				)
+			}
+
+			if !hasOffsets(partitions) {
+				continue
+			}
+
+			eta, ok := store.EstimateCatchUp(partitions)
+			fields := map[string]interface{}{
+				"catching_up": ok,
+			}
+			if ok {
+				fields["eta"] = eta
+			}
+
+			b.point(
+				map[string]string{
+					"type":  "ConsumerCatchUp",
+					"group": group,
+					"topic": topic,
+				},
+				fields,
+				latest,
+			)
+		}
+	}
+
+	r.write("consumer-offsets", b)
+}
+
+// ReportClusterHealth reports a snapshot of the cluster partition health.
+//
+// The health is evaluated when it is reported, so its points are stamped with the report time.
+func (r LineProtocolReporter) ReportClusterHealth(h *store.ClusterHealth) {
+	b := r.newBatch()
+
+	b.point(
+		map[string]string{
+			"type": "ClusterHealth",
+		},
+		map[string]interface{}{
+			"under_replicated":     h.UnderReplicated,
+			"under_min_isr":        h.UnderMinIsr,
+			"offline":              h.Offline,
+			"non_preferred_leader": h.NonPreferredLeader,
+		},
+		0,
+	)
+
+	for topic, th := range h.Topics {
+		b.point(
+			map[string]string{
+				"type":  "TopicHealth",
+				"topic": topic,
+			},
+			map[string]interface{}{
+				"partitions":           th.Partitions,
+				"under_replicated":     len(th.UnderReplicated),
+				"under_min_isr":        len(th.UnderMinIsr),
+				"offline":              len(th.Offline),
+				"non_preferred_leader": len(th.NonPreferredLeader),
+			},
+			0,
+		)
+	}
+
+	r.write("cluster health", b)
+}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+func (r LineProtocolReporter) ReportBrokerLogDirs(l *store.BrokerLogDirs) {
+	b := r.newBatch()
+
+	for broker, dirs := range *l {
+		for _, dir := range dirs.LogDirs {
+			b.point(
+				map[string]string{
+					"type":   "LogDir",
+					"broker": strconv.Itoa(int(broker)),
+					"path":   dir.Path,
+				},
+				map[string]interface{}{
+					"size": dir.Size(),
+				},
+				dirs.Timestamp,
+			)
+
+			for _, replica := range dir.Replicas {
+				b.point(
+					map[string]string{
+						"type":      "LogDirReplica",
+						"broker":    strconv.Itoa(int(broker)),
+						"path":      dir.Path,
+						"topic":     replica.Topic,
+						"partition": strconv.Itoa(int(replica.Partition)),
+					},
+					map[string]interface{}{
+						"size":       replica.Size,
+						"offset_lag": replica.OffsetLag,
+						"temporary":  replica.Temporary,
+					},
+					dirs.Timestamp,
+				)
+			}
+		}
+	}
+
+	r.write("log dirs", b)
+}
+
+// ReportClusterSummary reports a summary of the cluster brokers.
+//
+// The summary is read from the live connections, so its point is stamped with the report time.
+func (r LineProtocolReporter) ReportClusterSummary(s *kafka.ClusterSummary) {
+	b := r.newBatch()
+
+	b.point(
+		map[string]string{
+			"type": "ClusterSummary",
+		},
+		map[string]interface{}{
+			"controller":        s.ControllerID,
+			"brokers":           s.Brokers,
+			"connected_brokers": s.ConnectedBrokers,
+		},
+		0,
+	)
+
+	r.write("cluster summary", b)
+}
+
+// Close closes the writer when it holds a connection.
+func (r LineProtocolReporter) Close() error {
+	if c, ok := r.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// newBatch creates a batch of lines written by the reporter.
+func (r LineProtocolReporter) newBatch() *lineBatch {
+	return &lineBatch{r: r, now: time.Now()}
+}
+
+// write writes the batch, logging any error.
+func (r LineProtocolReporter) write(name string, b *lineBatch) {
+	if b.buf.Len() == 0 {
+		return
+	}
+
+	if err := r.w.WriteLines(b.buf.Bytes()); err != nil {
+		r.log.Error("influx: " + name + ": " + err.Error())
+	}
+}
+
+// lineBatch batches line protocol points.
+type lineBatch struct {
+	r   LineProtocolReporter
+	now time.Time
+	buf bytes.Buffer
+}
+
+// point adds a point to the batch.
+//
+// The timestamp is in milliseconds, the report time of the batch is used when it is 0.
+func (b *lineBatch) point(tags map[string]string, fields map[string]interface{}, timestamp int64) {
+	for i := 0; i < len(b.r.tags); i += 2 {
+		tags[b.r.tags[i]] = b.r.tags[i+1]
+	}
+
+	t := b.now
+	if timestamp > 0 {
+		t = time.Unix(0, timestamp*int64(time.Millisecond))
+	}
+
+	line, err := encodeLine(b.r.metric, tags, fields, t)
+	if err != nil {
+		b.r.log.Error("influx: " + err.Error())
+		return
+	}
+
+	b.buf.WriteString(line)
+	b.buf.WriteByte('\n')
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringFieldEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// encodeLine encodes a point in the line protocol, with the tags and fields sorted by key.
+//
+// Tags with an empty value are omitted, as the line protocol does not allow them.
+func encodeLine(measurement string, tags map[string]string, fields map[string]interface{}, t time.Time) (string, error) {
+	if len(fields) == 0 {
+		return "", errors.New("point without fields")
+	}
+
+	tagKeys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if k != "" && v != "" {
+			tagKeys = append(tagKeys, k)
+		}
+	}
+	sort.Strings(tagKeys)
+
+	fieldKeys := make([]string, 0, len(fields))
+	for k := range fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+
+	var sb strings.Builder
+	sb.WriteString(measurementEscaper.Replace(measurement))
+	for _, k := range tagKeys {
+		sb.WriteByte(',')
+		sb.WriteString(tagEscaper.Replace(k))
+		sb.WriteByte('=')
+		sb.WriteString(tagEscaper.Replace(tags[k]))
+	}
+
+	for i, k := range fieldKeys {
+		v, err := encodeFieldValue(fields[k])
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", k, err)
+		}
+
+		sep := byte(',')
+		if i == 0 {
+			sep = ' '
+		}
+		sb.WriteByte(sep)
+		sb.WriteString(tagEscaper.Replace(k))
+		sb.WriteByte('=')
+		sb.WriteString(v)
+	}
+
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(t.UnixNano(), 10))
+
+	return sb.String(), nil
+}
+
+// encodeFieldValue encodes a line protocol field value.
+func encodeFieldValue(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case int:
+		return strconv.FormatInt(int64(val), 10) + "i", nil
+	case int32:
+		return strconv.FormatInt(int64(val), 10) + "i", nil
+	case int64:
+		return strconv.FormatInt(val, 10) + "i", nil
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return "", fmt.Errorf("unsupported value %v", val)
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case string:
+		return `"` + stringFieldEscaper.Replace(val) + `"`, nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+}
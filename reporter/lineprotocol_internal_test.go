@@ -0,0 +1,56 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineMetric(t *testing.T) {
+	r := &LineProtocolReporter{}
+
+	LineMetric("kafka")(r)
+
+	assert.Equal(t, r.metric, "kafka")
+}
+
+func TestLineTags(t *testing.T) {
+	r := &LineProtocolReporter{}
+
+	LineTags([]string{"foo", "bar"})(r)
+
+	assert.Equal(t, r.tags[1], "bar")
+}
+
+func TestEncodeLine(t *testing.T) {
+	line, err := encodeLine(
+		"kafka lag",
+		map[string]string{"topic": "a,b=c d", "empty": ""},
+		map[string]interface{}{
+			"lag":    int64(10),
+			"rate":   2.5,
+			"ok":     true,
+			"path":   `/data "x" \y`,
+			"count":  3,
+			"leader": int32(-1),
+		},
+		time.Unix(1, 5),
+	)
+
+	assert.NoError(t, err)
+	want := `kafka\ lag,topic=a\,b\=c\ d count=3i,lag=10i,leader=-1i,ok=true,path="/data \"x\" \\y",rate=2.5 1000000005`
+	assert.Equal(t, want, line)
+}
+
+func TestEncodeLine_InvalidFields(t *testing.T) {
+	_, err := encodeLine("kafka", nil, nil, time.Now())
+	assert.Error(t, err)
+
+	_, err = encodeLine("kafka", nil, map[string]interface{}{"rate": math.NaN()}, time.Now())
+	assert.Error(t, err)
+
+	_, err = encodeLine("kafka", nil, map[string]interface{}{"list": []int{1}}, time.Now())
+	assert.Error(t, err)
+}
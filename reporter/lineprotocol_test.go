@@ -0,0 +1,228 @@
+package reporter_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/reporter"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type lineWriter struct {
+	lines []string
+	err   error
+}
+
+func (w *lineWriter) WriteLines(lines []byte) error {
+	w.lines = append(w.lines, strings.Split(strings.TrimSuffix(string(lines), "\n"), "\n")...)
+	return w.err
+}
+
+func TestLineProtocolReporter_ReportBrokerOffsets(t *testing.T) {
+	w := &lineWriter{}
+	r := reporter.NewLineProtocolReporter(w,
+		reporter.LineMetric("kafka"),
+		reporter.LineTags([]string{"cluster", "eu"}),
+		reporter.LineLog(testutil.Logger),
+	)
+
+	offsets := &store.BrokerOffsets{
+		"test": []*store.BrokerOffset{
+			{
+				OldestOffset: 0,
+				NewestOffset: 1000,
+				Timestamp:    1600000000123,
+				ProduceRate:  12.5,
+			},
+		},
+		"nil": []*store.BrokerOffset{nil},
+	}
+	r.ReportBrokerOffsets(offsets)
+
+	want := "kafka,cluster=eu,partition=0,topic=test,type=BrokerOffset available=1000i,newest=1000i,oldest=0i,produce_rate=12.5 1600000000123000000"
+	assert.Equal(t, []string{want}, w.lines)
+}
+
+func TestLineProtocolReporter_ReportConsumerOffsets(t *testing.T) {
+	w := &lineWriter{}
+	r := reporter.NewLineProtocolReporter(w)
+
+	offsets := &store.ConsumerOffsets{
+		"foo": map[string][]*store.ConsumerOffset{
+			"test": {
+				{
+					Offset:    1000,
+					Lag:       100,
+					Timestamp: 1600000000000,
+				},
+			},
+		},
+	}
+	r.ReportConsumerOffsets(offsets)
+
+	assert.Len(t, w.lines, 2)
+	assert.True(t, strings.HasPrefix(w.lines[0], "kafka,group=foo,partition=0,topic=test,type=ConsumerOffset "))
+	assert.True(t, strings.HasSuffix(w.lines[0], " 1600000000000000000"))
+	assert.Equal(t, "kafka,group=foo,topic=test,type=ConsumerCatchUp catching_up=false 1600000000000000000", w.lines[1])
+}
+
+func TestLineProtocolReporter_ReportBrokerMetadata(t *testing.T) {
+	w := &lineWriter{}
+	r := reporter.NewLineProtocolReporter(w)
+
+	metadata := &store.BrokerMetadata{
+		"test": []*store.Metadata{
+			{
+				Leader:    1,
+				Replicas:  []int32{1, 2},
+				Isr:       []int32{1},
+				Timestamp: 1600000000123,
+			},
+		},
+		"nil": []*store.Metadata{nil},
+	}
+	r.ReportBrokerMetadata(metadata)
+
+	want := "kafka,partition=0,topic=test,type=BrokerMetadata isr=1i,isr_diff=1,leaders=1i,replicas=2i 1600000000123000000"
+	assert.Equal(t, []string{want}, w.lines)
+}
+
+func TestLineProtocolReporter_ReportClusterHealth(t *testing.T) {
+	w := &lineWriter{}
+	r := reporter.NewLineProtocolReporter(w)
+
+	start := time.Now().UnixNano()
+	r.ReportClusterHealth(&store.ClusterHealth{UnderReplicated: 1})
+
+	assert.Len(t, w.lines, 1)
+	assert.True(t, strings.HasPrefix(w.lines[0], "kafka,type=ClusterHealth non_preferred_leader=0i,offline=0i,under_min_isr=0i,under_replicated=1i "))
+	assertReportTime(t, w.lines[0], start)
+}
+
+func TestLineProtocolReporter_ReportClusterSummary(t *testing.T) {
+	w := &lineWriter{}
+	r := reporter.NewLineProtocolReporter(w)
+
+	start := time.Now().UnixNano()
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: 1, Brokers: 3, ConnectedBrokers: 2})
+
+	assert.Len(t, w.lines, 1)
+	assert.True(t, strings.HasPrefix(w.lines[0], "kafka,type=ClusterSummary brokers=3i,connected_brokers=2i,controller=1i "))
+	assertReportTime(t, w.lines[0], start)
+}
+
+// assertReportTime asserts the line is stamped with the time it was reported.
+func assertReportTime(t *testing.T, line string, start int64) {
+	ts, err := strconv.ParseInt(line[strings.LastIndex(line, " ")+1:], 10, 64)
+	assert.NoError(t, err)
+	assert.True(t, ts >= start && ts <= time.Now().UnixNano())
+}
+
+func TestLineProtocolReporter_WriteError(t *testing.T) {
+	w := &lineWriter{err: errors.New("test error")}
+	r := reporter.NewLineProtocolReporter(w, reporter.LineLog(testutil.Logger))
+
+	r.ReportClusterHealth(&store.ClusterHealth{})
+
+	assert.Len(t, w.lines, 1)
+}
+
+func TestInfluxV2Writer_WriteLines(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		assert.Equal(t, "/api/v2/write", req.URL.Path)
+		assert.Equal(t, "my-org", req.URL.Query().Get("org"))
+		assert.Equal(t, "my-bucket", req.URL.Query().Get("bucket"))
+		assert.Equal(t, "ns", req.URL.Query().Get("precision"))
+		assert.Equal(t, "Token secret", req.Header.Get("Authorization"))
+		assert.Equal(t, "kafka value=1i 1\n", string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w := reporter.NewInfluxV2Writer(srv.URL, "my-org", "my-bucket", "secret", nil)
+
+	err := w.WriteLines([]byte("kafka value=1i 1\n"))
+
+	assert.NoError(t, err)
+}
+
+func TestInfluxV2Writer_WriteLinesError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"code":"unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	w := reporter.NewInfluxV2Writer(srv.URL, "my-org", "my-bucket", "secret", nil)
+
+	err := w.WriteLines([]byte("kafka value=1i 1\n"))
+
+	assert.EqualError(t, err, `responded with status 401: {"code":"unauthorized"}`)
+}
+
+func TestInfluxV1Writer_WriteLines(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, pass, ok := req.BasicAuth()
+
+		assert.Equal(t, "/write", req.URL.Path)
+		assert.Equal(t, "kage", req.URL.Query().Get("db"))
+		assert.Equal(t, "autogen", req.URL.Query().Get("rp"))
+		assert.Equal(t, "ns", req.URL.Query().Get("precision"))
+		assert.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "pass", pass)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w := reporter.NewInfluxV1Writer(srv.URL, "kage", "autogen", "user", "pass", nil)
+
+	err := w.WriteLines([]byte("kafka value=1i 1\n"))
+
+	assert.NoError(t, err)
+}
+
+func TestUDPLineWriter_WriteLines(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := reporter.NewUDPLineWriter(conn.LocalAddr().String(), 40)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	err = w.WriteLines([]byte("kafka value=1i 1\nkafka value=2i 2\nkafka,topic=a_very_long_topic value=3i 3\n"))
+	assert.NoError(t, err)
+
+	var packets []string
+	buf := make([]byte, 1024)
+	for i := 0; i < 2; i++ {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, string(buf[:n]))
+	}
+
+	assert.Equal(t, []string{
+		"kafka value=1i 1\nkafka value=2i 2\n",
+		"kafka,topic=a_very_long_topic value=3i 3\n",
+	}, packets)
+}
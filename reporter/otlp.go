@@ -0,0 +1,380 @@
+package reporter
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hamba/pkg/log"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+	"golang.org/x/net/http2"
+)
+
+// OTLP protocols.
+const (
+	// OTLPProtocolGRPC exports the metrics with the OTLP/gRPC protocol.
+	OTLPProtocolGRPC = "grpc"
+	// OTLPProtocolHTTP exports the metrics with the OTLP/HTTP protocol, encoded as protobuf.
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
+const (
+	otlpHTTPPath   = "/v1/metrics"
+	otlpGRPCMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+)
+
+// OTLPReporterFunc represents a configuration function for OTLPReporter.
+type OTLPReporterFunc func(r *OTLPReporter)
+
+// OTLPProtocol configures the export protocol on an OTLPReporter.
+func OTLPProtocol(protocol string) OTLPReporterFunc {
+	return func(r *OTLPReporter) {
+		r.protocol = protocol
+	}
+}
+
+// OTLPPrefix configures the metric name prefix on an OTLPReporter.
+func OTLPPrefix(prefix string) OTLPReporterFunc {
+	return func(r *OTLPReporter) {
+		r.prefix = prefix
+	}
+}
+
+// OTLPResource configures the resource attributes on an OTLPReporter.
+//
+// The attributes are key value pairs.
+func OTLPResource(attrs []string) OTLPReporterFunc {
+	return func(r *OTLPReporter) {
+		r.resource = attrs
+	}
+}
+
+// OTLPHeaders configures the headers sent with every export on an OTLPReporter.
+//
+// The headers are key value pairs.
+func OTLPHeaders(headers []string) OTLPReporterFunc {
+	return func(r *OTLPReporter) {
+		r.headers = headers
+	}
+}
+
+// OTLPClient configures the http client on an OTLPReporter.
+//
+// The client must support HTTP/2 for the gRPC protocol.
+func OTLPClient(client *http.Client) OTLPReporterFunc {
+	return func(r *OTLPReporter) {
+		r.client = client
+	}
+}
+
+// OTLPLog configures the logger on an OTLPReporter.
+func OTLPLog(log log.Logger) OTLPReporterFunc {
+	return func(r *OTLPReporter) {
+		r.log = log
+	}
+}
+
+// OTLPReporter represents an OpenTelemetry OTLP metrics reporter.
+//
+// Every report is exported as a set of gauges.
+type OTLPReporter struct {
+	url string
+
+	protocol string
+	prefix   string
+	resource []string
+	headers  []string
+	client   *http.Client
+
+	log log.Logger
+}
+
+// NewOTLPReporter creates and returns a new OTLPReporter exporting to the collector endpoint.
+//
+// The endpoint is an http or https url. An http endpoint is
+// used without TLS with the gRPC protocol.
+func NewOTLPReporter(endpoint string, opts ...OTLPReporterFunc) (*OTLPReporter, error) {
+	r := &OTLPReporter{
+		protocol: OTLPProtocolHTTP,
+		prefix:   "kafka",
+		log:      log.Null,
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("otlp: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("otlp: unsupported endpoint scheme \"%s\"", u.Scheme)
+	}
+
+	switch r.protocol {
+	case OTLPProtocolHTTP:
+		r.url = endpoint
+		if strings.Trim(u.Path, "/") == "" {
+			r.url = strings.TrimRight(endpoint, "/") + otlpHTTPPath
+		}
+
+		if r.client == nil {
+			r.client = &http.Client{Timeout: 10 * time.Second}
+		}
+
+	case OTLPProtocolGRPC:
+		r.url = strings.TrimRight(endpoint, "/") + otlpGRPCMethod
+
+		if r.client == nil {
+			r.client = newHTTP2Client(u.Scheme == "http")
+		}
+
+	default:
+		return nil, fmt.Errorf("otlp: unknown protocol \"%s\"", r.protocol)
+	}
+
+	return r, nil
+}
+
+// newHTTP2Client creates an HTTP/2 client, without TLS when insecure.
+func newHTTP2Client(insecure bool) *http.Client {
+	t := &http2.Transport{}
+	if insecure {
+		t.AllowHTTP = true
+		t.DialTLS = func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		}
+	}
+
+	return &http.Client{Timeout: 10 * time.Second, Transport: t}
+}
+
+// ReportBrokerOffsets reports a snapshot of the broker offsets.
+func (r OTLPReporter) ReportBrokerOffsets(o *store.BrokerOffsets) {
+	b := newOTLPBatch()
+	brokerOffsetGauges(o, b.gauge)
+	r.export("offsets", b)
+}
+
+// ReportBrokerMetadata reports a snapshot of the broker metadata.
+func (r OTLPReporter) ReportBrokerMetadata(m *store.BrokerMetadata) {
+	b := newOTLPBatch()
+	brokerMetadataGauges(m, b.gauge)
+	r.export("metadata", b)
+}
+
+// ReportConsumerOffsets reports a snapshot of the consumer group offsets.
+func (r OTLPReporter) ReportConsumerOffsets(o *store.ConsumerOffsets) {
+	b := newOTLPBatch()
+	consumerOffsetGauges(o, b.gauge)
+	r.export("consumer-offsets", b)
+}
+
+// ReportClusterHealth reports a snapshot of the cluster partition health.
+func (r OTLPReporter) ReportClusterHealth(h *store.ClusterHealth) {
+	b := newOTLPBatch()
+	clusterHealthGauges(h, b.gauge)
+	r.export("cluster health", b)
+}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+func (r OTLPReporter) ReportBrokerLogDirs(l *store.BrokerLogDirs) {
+	b := newOTLPBatch()
+	brokerLogDirGauges(l, b.gauge)
+	r.export("log dirs", b)
+}
+
+// ReportClusterSummary reports a summary of the cluster brokers.
+func (r OTLPReporter) ReportClusterSummary(s *kafka.ClusterSummary) {
+	b := newOTLPBatch()
+	clusterSummaryGauges(s, b.gauge)
+	r.export("cluster summary", b)
+}
+
+// export encodes and sends the batch, logging any error.
+func (r OTLPReporter) export(name string, b *otlpBatch) {
+	if len(b.names) == 0 {
+		return
+	}
+
+	msg := r.encode(b, time.Now())
+
+	var err error
+	switch r.protocol {
+	case OTLPProtocolGRPC:
+		err = r.sendGRPC(msg)
+
+	default:
+		err = r.sendHTTP(msg)
+	}
+
+	if err != nil {
+		r.log.Error("otlp: " + name + ": " + err.Error())
+	}
+}
+
+// sendHTTP sends an export request with the OTLP/HTTP protocol.
+func (r OTLPReporter) sendHTTP(msg []byte) error {
+	req, err := r.newRequest(msg, "application/x-protobuf")
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with status %d", r.url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// sendGRPC sends an export request as a unary gRPC call.
+func (r OTLPReporter) sendGRPC(msg []byte) error {
+	// A gRPC message is prefixed by its compression flag and length.
+	body := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(body[1:5], uint32(len(msg)))
+	copy(body[5:], msg)
+
+	req, err := r.newRequest(body, "application/grpc")
+	if err != nil {
+		return err
+	}
+	req.Header.Set("TE", "trailers")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The trailers are only available once the body is read.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", r.url, resp.StatusCode)
+	}
+
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		// A response without a message may have its status in the headers.
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		return fmt.Errorf("%s responded with grpc status %s: %s", r.url, status, message)
+	}
+
+	return nil
+}
+
+// newRequest creates an export request.
+func (r OTLPReporter) newRequest(body []byte, contentType string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	for i := 0; i < len(r.headers); i += 2 {
+		req.Header.Set(r.headers[i], r.headers[i+1])
+	}
+
+	return req, nil
+}
+
+// encode encodes the batch as an ExportMetricsServiceRequest.
+func (r OTLPReporter) encode(b *otlpBatch, now time.Time) []byte {
+	ts := uint64(now.UnixNano())
+
+	e := &protoEncoder{}
+	// ExportMetricsServiceRequest.resource_metrics
+	e.message(1, func(e *protoEncoder) {
+		// ResourceMetrics.resource
+		e.message(1, func(e *protoEncoder) {
+			encodeOTLPAttributes(e, 1, r.resource)
+		})
+
+		// ResourceMetrics.scope_metrics
+		e.message(2, func(e *protoEncoder) {
+			// ScopeMetrics.scope
+			e.message(1, func(e *protoEncoder) {
+				e.string(1, "kage")
+			})
+
+			for _, name := range b.names {
+				// ScopeMetrics.metrics
+				e.message(2, func(e *protoEncoder) {
+					e.string(1, metricName(r.prefix, name))
+
+					// Metric.gauge
+					e.message(5, func(e *protoEncoder) {
+						for _, p := range b.points[name] {
+							// Gauge.data_points
+							e.message(1, func(e *protoEncoder) {
+								e.fixed64(3, ts)
+								e.double(4, p.value)
+								encodeOTLPAttributes(e, 7, p.tags)
+							})
+						}
+					})
+				})
+			}
+		})
+	})
+
+	return e.Bytes()
+}
+
+// encodeOTLPAttributes encodes key value pairs as repeated string KeyValue fields.
+func encodeOTLPAttributes(e *protoEncoder, field int, attrs []string) {
+	for i := 0; i < len(attrs); i += 2 {
+		e.message(field, func(e *protoEncoder) {
+			e.string(1, attrs[i])
+			// KeyValue.value
+			e.message(2, func(e *protoEncoder) {
+				e.string(1, attrs[i+1])
+			})
+		})
+	}
+}
+
+// otlpPoint represents a gauge data point.
+type otlpPoint struct {
+	tags  []string
+	value float64
+}
+
+// otlpBatch groups the gauge data points by metric.
+type otlpBatch struct {
+	names  []string
+	points map[string][]otlpPoint
+}
+
+func newOTLPBatch() *otlpBatch {
+	return &otlpBatch{points: map[string][]otlpPoint{}}
+}
+
+// gauge adds a data point to its metric.
+func (b *otlpBatch) gauge(scope, field string, value float64, tags []string) {
+	name := scope + "." + field
+	if _, ok := b.points[name]; !ok {
+		b.names = append(b.names, name)
+	}
+
+	b.points[name] = append(b.points[name], otlpPoint{tags: tags, value: value})
+}
@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestOTLPPrefix(t *testing.T) {
+	r := &OTLPReporter{}
+
+	OTLPPrefix("kafka")(r)
+
+	assert.Equal(t, r.prefix, "kafka")
+}
+
+func TestOTLPResource(t *testing.T) {
+	r := &OTLPReporter{}
+
+	OTLPResource([]string{"foo", "bar"})(r)
+
+	assert.Equal(t, r.resource[1], "bar")
+}
+
+func TestOTLPReporter_Encode(t *testing.T) {
+	r := OTLPReporter{prefix: "kafka", resource: []string{"a", "b"}}
+	b := newOTLPBatch()
+	b.gauge("s", "f", 1, []string{"c", "d"})
+
+	got := r.encode(b, time.Unix(0, 1))
+
+	want := []byte{
+		0x0a, 0x43, // resource_metrics
+		0x0a, 0x0a, // resource
+		0x0a, 0x08, 0x0a, 0x01, 'a', 0x12, 0x03, 0x0a, 0x01, 'b', // attribute a=b
+		0x12, 0x35, // scope_metrics
+		0x0a, 0x06, 0x0a, 0x04, 'k', 'a', 'g', 'e', // scope
+		0x12, 0x2b, // metric
+		0x0a, 0x09, 'k', 'a', 'f', 'k', 'a', '.', 's', '.', 'f', // name
+		0x2a, 0x1e, // gauge
+		0x0a, 0x1c, // data point
+		0x19, 1, 0, 0, 0, 0, 0, 0, 0, // time_unix_nano
+		0x21, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // as_double
+		0x3a, 0x08, 0x0a, 0x01, 'c', 0x12, 0x03, 0x0a, 0x01, 'd', // attribute c=d
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestOTLPReporter_SendGRPCError(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "3")
+		w.Header().Set("Grpc-Message", "invalid metrics")
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(h2c.NewHandler(h, &http2.Server{}))
+	defer srv.Close()
+
+	r, err := NewOTLPReporter(srv.URL, OTLPProtocol(OTLPProtocolGRPC))
+	assert.NoError(t, err)
+
+	err = r.sendGRPC([]byte{})
+
+	assert.EqualError(t, err, srv.URL+otlpGRPCMethod+" responded with grpc status 3: invalid metrics")
+}
+
+func TestOTLPReporter_SendGRPCTrailers(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte{0, 0, 0, 0, 0})
+		w.Header().Set("Grpc-Status", "0")
+	})
+	srv := httptest.NewServer(h2c.NewHandler(h, &http2.Server{}))
+	defer srv.Close()
+
+	r, err := NewOTLPReporter(srv.URL, OTLPProtocol(OTLPProtocolGRPC))
+	assert.NoError(t, err)
+
+	err = r.sendGRPC([]byte{})
+
+	assert.NoError(t, err)
+}
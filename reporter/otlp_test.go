@@ -0,0 +1,115 @@
+package reporter_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/reporter"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestNewOTLPReporter_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		opts     []reporter.OTLPReporterFunc
+	}{
+		{name: "Scheme", endpoint: "tcp://127.0.0.1:4317"},
+		{name: "Protocol", endpoint: "http://127.0.0.1:4317", opts: []reporter.OTLPReporterFunc{reporter.OTLPProtocol("foo")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reporter.NewOTLPReporter(tt.endpoint, tt.opts...)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOTLPReporter_ReportClusterSummaryHTTP(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		called = true
+		body, _ := ioutil.ReadAll(req.Body)
+
+		assert.Equal(t, "/v1/metrics", req.URL.Path)
+		assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+		assert.True(t, bytes.Contains(body, []byte("kafka.cluster_summary.connected_brokers")))
+		assert.True(t, bytes.Contains(body, []byte("service.version")))
+	}))
+	defer srv.Close()
+
+	r, err := reporter.NewOTLPReporter(srv.URL,
+		reporter.OTLPResource([]string{"service.name", "kage", "service.version", "1.0.0"}),
+		reporter.OTLPHeaders([]string{"Authorization", "Bearer secret"}),
+		reporter.OTLPLog(testutil.Logger),
+	)
+	assert.NoError(t, err)
+
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: 1, Brokers: 3, ConnectedBrokers: 2})
+
+	assert.True(t, called)
+}
+
+func TestOTLPReporter_ReportConsumerOffsetsGRPC(t *testing.T) {
+	var called bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		called = true
+		body, _ := ioutil.ReadAll(req.Body)
+
+		assert.Equal(t, "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export", req.URL.Path)
+		assert.Equal(t, "application/grpc", req.Header.Get("Content-Type"))
+		assert.Equal(t, 2, req.ProtoMajor)
+		assert.Equal(t, byte(0), body[0])
+		assert.Equal(t, uint32(len(body)-5), binary.BigEndian.Uint32(body[1:5]))
+		assert.True(t, bytes.Contains(body, []byte("kafka.consumer_offset.lag")))
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte{0, 0, 0, 0, 0})
+		w.Header().Set("Grpc-Status", "0")
+	})
+	srv := httptest.NewServer(h2c.NewHandler(h, &http2.Server{}))
+	defer srv.Close()
+
+	r, err := reporter.NewOTLPReporter(srv.URL, reporter.OTLPProtocol(reporter.OTLPProtocolGRPC))
+	assert.NoError(t, err)
+
+	offsets := &store.ConsumerOffsets{
+		"foo": map[string][]*store.ConsumerOffset{
+			"test": {
+				{
+					Offset: 1000,
+					Lag:    100,
+				},
+			},
+		},
+	}
+	r.ReportConsumerOffsets(offsets)
+
+	assert.True(t, called)
+}
+
+func TestOTLPReporter_ReportEmpty(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("unexpected export")
+	}))
+	defer srv.Close()
+
+	r, err := reporter.NewOTLPReporter(srv.URL)
+	assert.NoError(t, err)
+
+	r.ReportBrokerOffsets(&store.BrokerOffsets{})
+}
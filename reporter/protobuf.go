@@ -0,0 +1,66 @@
+package reporter
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Protobuf wire types.
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+)
+
+// protoEncoder encodes values in the protobuf wire format.
+type protoEncoder struct {
+	buf []byte
+}
+
+// key writes a field key.
+func (e *protoEncoder) key(field, wire int) {
+	e.varint(uint64(field)<<3 | uint64(wire))
+}
+
+// varint writes a base 128 varint.
+func (e *protoEncoder) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	e.buf = append(e.buf, b[:n]...)
+}
+
+// string writes a string field.
+func (e *protoEncoder) string(field int, v string) {
+	e.key(field, protoBytes)
+	e.varint(uint64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// fixed64 writes a fixed64 field.
+func (e *protoEncoder) fixed64(field int, v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+
+	e.key(field, protoFixed64)
+	e.buf = append(e.buf, b[:]...)
+}
+
+// double writes a double field.
+func (e *protoEncoder) double(field int, v float64) {
+	e.fixed64(field, math.Float64bits(v))
+}
+
+// message writes an embedded message field.
+func (e *protoEncoder) message(field int, fn func(e *protoEncoder)) {
+	m := &protoEncoder{}
+	fn(m)
+
+	e.key(field, protoBytes)
+	e.varint(uint64(len(m.buf)))
+	e.buf = append(e.buf, m.buf...)
+}
+
+// Bytes returns the encoded bytes.
+func (e *protoEncoder) Bytes() []byte {
+	return e.buf
+}
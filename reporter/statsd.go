@@ -0,0 +1,238 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hamba/pkg/log"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+)
+
+// StatsD metric formats.
+const (
+	// StatsDFormatPlain is the plain StatsD format, with the tag values in the metric name.
+	StatsDFormatPlain = "statsd"
+	// StatsDFormatDogStatsD is the DogStatsD format, with the tags appended to the metric.
+	StatsDFormatDogStatsD = "dogstatsd"
+)
+
+// defaultStatsDPacketSize is the default maximum size of a packet, fitting an
+// ethernet MTU without fragmentation.
+const defaultStatsDPacketSize = 1432
+
+// StatsDReporterFunc represents a configuration function for StatsDReporter.
+type StatsDReporterFunc func(r *StatsDReporter)
+
+// StatsDFormat configures the metric format on a StatsDReporter.
+func StatsDFormat(format string) StatsDReporterFunc {
+	return func(r *StatsDReporter) {
+		r.format = format
+	}
+}
+
+// StatsDPrefix configures the metric name prefix on a StatsDReporter.
+func StatsDPrefix(prefix string) StatsDReporterFunc {
+	return func(r *StatsDReporter) {
+		r.prefix = prefix
+	}
+}
+
+// StatsDTags configures the global tags on a StatsDReporter.
+func StatsDTags(tags []string) StatsDReporterFunc {
+	return func(r *StatsDReporter) {
+		r.tags = tags
+	}
+}
+
+// StatsDPacketSize configures the maximum packet size on a StatsDReporter.
+func StatsDPacketSize(size int) StatsDReporterFunc {
+	return func(r *StatsDReporter) {
+		r.packetSize = size
+	}
+}
+
+// StatsDLog configures the logger on a StatsDReporter.
+func StatsDLog(log log.Logger) StatsDReporterFunc {
+	return func(r *StatsDReporter) {
+		r.log = log
+	}
+}
+
+// StatsDReporter represents a StatsD reporter.
+type StatsDReporter struct {
+	conn net.Conn
+
+	format     string
+	prefix     string
+	tags       []string
+	packetSize int
+
+	log log.Logger
+}
+
+// NewStatsDReporter creates and returns a new StatsDReporter sending to the given UDP address.
+func NewStatsDReporter(addr string, opts ...StatsDReporterFunc) (*StatsDReporter, error) {
+	r := &StatsDReporter{
+		format:     StatsDFormatPlain,
+		packetSize: defaultStatsDPacketSize,
+		log:        log.Null,
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if r.format != StatsDFormatPlain && r.format != StatsDFormatDogStatsD {
+		return nil, fmt.Errorf("statsd: unknown format \"%s\"", r.format)
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: cannot connect to %s: %w", addr, err)
+	}
+	r.conn = conn
+
+	return r, nil
+}
+
+// ReportBrokerOffsets reports a snapshot of the broker offsets.
+func (r StatsDReporter) ReportBrokerOffsets(o *store.BrokerOffsets) {
+	b := r.newBatch()
+	brokerOffsetGauges(o, b.gauge)
+	b.flush()
+}
+
+// ReportBrokerMetadata reports a snapshot of the broker metadata.
+func (r StatsDReporter) ReportBrokerMetadata(m *store.BrokerMetadata) {
+	b := r.newBatch()
+	brokerMetadataGauges(m, b.gauge)
+	b.flush()
+}
+
+// ReportConsumerOffsets reports a snapshot of the consumer group offsets.
+func (r StatsDReporter) ReportConsumerOffsets(o *store.ConsumerOffsets) {
+	b := r.newBatch()
+	consumerOffsetGauges(o, b.gauge)
+	b.flush()
+}
+
+// ReportClusterHealth reports a snapshot of the cluster partition health.
+func (r StatsDReporter) ReportClusterHealth(h *store.ClusterHealth) {
+	b := r.newBatch()
+	clusterHealthGauges(h, b.gauge)
+	b.flush()
+}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+func (r StatsDReporter) ReportBrokerLogDirs(l *store.BrokerLogDirs) {
+	b := r.newBatch()
+	brokerLogDirGauges(l, b.gauge)
+	b.flush()
+}
+
+// ReportClusterSummary reports a summary of the cluster brokers.
+func (r StatsDReporter) ReportClusterSummary(s *kafka.ClusterSummary) {
+	b := r.newBatch()
+	clusterSummaryGauges(s, b.gauge)
+	b.flush()
+}
+
+// Close closes the connection to StatsD.
+func (r StatsDReporter) Close() error {
+	return r.conn.Close()
+}
+
+// newBatch creates a batch of metrics sent by the reporter.
+func (r StatsDReporter) newBatch() *statsdBatch {
+	return &statsdBatch{r: r}
+}
+
+// statsdBatch batches metric lines into packets.
+type statsdBatch struct {
+	r   StatsDReporter
+	buf bytes.Buffer
+}
+
+// gauge adds a gauge to the batch, sending the packet when full.
+//
+// The tags are key value pairs.
+func (b *statsdBatch) gauge(scope, field string, value float64, tags []string) {
+	line := b.r.line(scope, field, value, tags)
+
+	if b.buf.Len() > 0 && b.buf.Len()+1+len(line) > b.r.packetSize {
+		b.flush()
+	}
+
+	if b.buf.Len() > 0 {
+		b.buf.WriteByte('\n')
+	}
+	b.buf.WriteString(line)
+}
+
+// flush sends the batched lines.
+func (b *statsdBatch) flush() {
+	if b.buf.Len() == 0 {
+		return
+	}
+
+	if _, err := b.r.conn.Write(b.buf.Bytes()); err != nil {
+		b.r.log.Error("statsd: " + err.Error())
+	}
+	b.buf.Reset()
+}
+
+// line formats a gauge line.
+func (r StatsDReporter) line(scope, field string, value float64, tags []string) string {
+	v := strconv.FormatFloat(value, 'f', -1, 64)
+
+	if r.format == StatsDFormatDogStatsD {
+		var pairs []string
+		for _, t := range [][]string{r.tags, tags} {
+			for i := 0; i < len(t); i += 2 {
+				pairs = append(pairs, dogStatsDReplacer.Replace(t[i])+":"+dogStatsDReplacer.Replace(t[i+1]))
+			}
+		}
+
+		line := metricName(r.prefix, scope, field) + ":" + v + "|g"
+		if len(pairs) > 0 {
+			line += "|#" + strings.Join(pairs, ",")
+		}
+
+		return line
+	}
+
+	// The plain format has no tags, so the tag values become part of the name.
+	parts := []string{scope}
+	for _, t := range [][]string{r.tags, tags} {
+		for i := 1; i < len(t); i += 2 {
+			parts = append(parts, statsDReplacer.Replace(t[i]))
+		}
+	}
+	parts = append(parts, field)
+	name := metricName(r.prefix, parts...)
+
+	if value < 0 {
+		// A signed gauge changes the value in plain StatsD, so it is reset first.
+		return name + ":0|g\n" + name + ":" + v + "|g"
+	}
+
+	return name + ":" + v + "|g"
+}
+
+var (
+	statsDReplacer    = strings.NewReplacer(".", "_", ":", "_", "|", "_", "@", "_", "#", "_", " ", "_", "\n", "_", "/", "_")
+	dogStatsDReplacer = strings.NewReplacer(",", "_", "|", "_", "#", "_", " ", "_", "\n", "_")
+)
+
+// metricName joins the parts of a metric name.
+func metricName(prefix string, parts ...string) string {
+	if prefix != "" {
+		parts = append([]string{prefix}, parts...)
+	}
+
+	return strings.Join(parts, ".")
+}
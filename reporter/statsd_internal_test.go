@@ -0,0 +1,39 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsDFormat(t *testing.T) {
+	r := &StatsDReporter{}
+
+	StatsDFormat(StatsDFormatDogStatsD)(r)
+
+	assert.Equal(t, r.format, StatsDFormatDogStatsD)
+}
+
+func TestStatsDPrefix(t *testing.T) {
+	r := &StatsDReporter{}
+
+	StatsDPrefix("kafka")(r)
+
+	assert.Equal(t, r.prefix, "kafka")
+}
+
+func TestStatsDTags(t *testing.T) {
+	r := &StatsDReporter{}
+
+	StatsDTags([]string{"foo", "bar"})(r)
+
+	assert.Equal(t, r.tags[1], "bar")
+}
+
+func TestStatsDPacketSize(t *testing.T) {
+	r := &StatsDReporter{}
+
+	StatsDPacketSize(512)(r)
+
+	assert.Equal(t, r.packetSize, 512)
+}
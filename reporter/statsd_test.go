@@ -0,0 +1,197 @@
+package reporter_test
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/reporter"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStatsDListener(t *testing.T) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func readStatsDPacket(t *testing.T, conn net.PacketConn) string {
+	buf := make([]byte, 65536)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(buf[:n])
+}
+
+func TestNewStatsDReporter_UnknownFormat(t *testing.T) {
+	_, err := reporter.NewStatsDReporter("127.0.0.1:8125", reporter.StatsDFormat("foo"))
+
+	assert.Error(t, err)
+}
+
+func TestStatsDReporter_Close(t *testing.T) {
+	conn := newStatsDListener(t)
+	defer conn.Close()
+
+	r, err := reporter.NewStatsDReporter(conn.LocalAddr().String())
+	assert.NoError(t, err)
+
+	assert.Implements(t, (*io.Closer)(nil), r)
+	assert.NoError(t, r.Close())
+	assert.Error(t, r.Close())
+}
+
+func TestStatsDReporter_ReportBrokerOffsets(t *testing.T) {
+	conn := newStatsDListener(t)
+	defer conn.Close()
+
+	r, err := reporter.NewStatsDReporter(conn.LocalAddr().String(),
+		reporter.StatsDPrefix("kafka"),
+		reporter.StatsDTags([]string{"cluster", "eu"}),
+		reporter.StatsDLog(testutil.Logger),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	offsets := &store.BrokerOffsets{
+		"test.topic": []*store.BrokerOffset{
+			{
+				OldestOffset: 0,
+				NewestOffset: 1000,
+				Timestamp:    time.Now().Unix() * 1000,
+				ProduceRate:  12.5,
+			},
+		},
+		"nil": []*store.BrokerOffset{nil},
+	}
+	r.ReportBrokerOffsets(offsets)
+
+	want := "kafka.broker_offset.eu.test_topic.0.oldest:0|g\n" +
+		"kafka.broker_offset.eu.test_topic.0.newest:1000|g\n" +
+		"kafka.broker_offset.eu.test_topic.0.available:1000|g\n" +
+		"kafka.broker_offset.eu.test_topic.0.produce_rate:12.5|g"
+	assert.Equal(t, want, readStatsDPacket(t, conn))
+}
+
+func TestStatsDReporter_ReportConsumerOffsetsDogStatsD(t *testing.T) {
+	conn := newStatsDListener(t)
+	defer conn.Close()
+
+	r, err := reporter.NewStatsDReporter(conn.LocalAddr().String(),
+		reporter.StatsDFormat(reporter.StatsDFormatDogStatsD),
+		reporter.StatsDPrefix("kafka"),
+		reporter.StatsDTags([]string{"cluster", "eu"}),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	offsets := &store.ConsumerOffsets{
+		"foo": map[string][]*store.ConsumerOffset{
+			"test": {
+				{
+					Offset:    1000,
+					Lag:       100,
+					Timestamp: time.Now().Unix() * 1000,
+				},
+			},
+		},
+	}
+	r.ReportConsumerOffsets(offsets)
+
+	got := readStatsDPacket(t, conn)
+	lines := strings.Split(got, "\n")
+	assert.Contains(t, lines, "kafka.consumer_offset.offset:1000|g|#cluster:eu,group:foo,topic:test,partition:0")
+	assert.Contains(t, lines, "kafka.consumer_offset.lag:100|g|#cluster:eu,group:foo,topic:test,partition:0")
+	assert.Contains(t, lines, "kafka.consumer_catch_up.catching_up:0|g|#cluster:eu,group:foo,topic:test")
+}
+
+func TestStatsDReporter_ReportClusterSummary(t *testing.T) {
+	conn := newStatsDListener(t)
+	defer conn.Close()
+
+	r, err := reporter.NewStatsDReporter(conn.LocalAddr().String(),
+		reporter.StatsDFormat(reporter.StatsDFormatDogStatsD),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: 1, Brokers: 3, ConnectedBrokers: 2})
+
+	want := "cluster_summary.controller:1|g\n" +
+		"cluster_summary.brokers:3|g\n" +
+		"cluster_summary.connected_brokers:2|g"
+	assert.Equal(t, want, readStatsDPacket(t, conn))
+}
+
+func TestStatsDReporter_ReportClusterSummaryNegativeGauge(t *testing.T) {
+	conn := newStatsDListener(t)
+	defer conn.Close()
+
+	r, err := reporter.NewStatsDReporter(conn.LocalAddr().String())
+	assert.NoError(t, err)
+	defer r.Close()
+
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: -1, Brokers: 3, ConnectedBrokers: 0})
+
+	want := "cluster_summary.controller:0|g\n" +
+		"cluster_summary.controller:-1|g\n" +
+		"cluster_summary.brokers:3|g\n" +
+		"cluster_summary.connected_brokers:0|g"
+	assert.Equal(t, want, readStatsDPacket(t, conn))
+}
+
+func TestStatsDReporter_ReportClusterSummaryNegativeGaugeDogStatsD(t *testing.T) {
+	conn := newStatsDListener(t)
+	defer conn.Close()
+
+	r, err := reporter.NewStatsDReporter(conn.LocalAddr().String(),
+		reporter.StatsDFormat(reporter.StatsDFormatDogStatsD),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	r.ReportClusterSummary(&kafka.ClusterSummary{ControllerID: -1, Brokers: 3, ConnectedBrokers: 0})
+
+	want := "cluster_summary.controller:-1|g\n" +
+		"cluster_summary.brokers:3|g\n" +
+		"cluster_summary.connected_brokers:0|g"
+	assert.Equal(t, want, readStatsDPacket(t, conn))
+}
+
+func TestStatsDReporter_Batching(t *testing.T) {
+	conn := newStatsDListener(t)
+	defer conn.Close()
+
+	r, err := reporter.NewStatsDReporter(conn.LocalAddr().String(),
+		reporter.StatsDPacketSize(64),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	r.ReportClusterHealth(&store.ClusterHealth{UnderReplicated: 1, UnderMinIsr: 2, Offline: 3, NonPreferredLeader: 4})
+
+	var lines []string
+	for len(lines) < 4 {
+		pkt := readStatsDPacket(t, conn)
+		assert.True(t, len(pkt) <= 64)
+		lines = append(lines, strings.Split(pkt, "\n")...)
+	}
+
+	assert.Equal(t, []string{
+		"cluster_health.under_replicated:1|g",
+		"cluster_health.under_min_isr:2|g",
+		"cluster_health.offline:3|g",
+		"cluster_health.non_preferred_leader:4|g",
+	}, lines)
+}
@@ -1,6 +1,11 @@
 package kage
 
-import "github.com/msales/kage/store"
+import (
+	"io"
+
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/store"
+)
 
 // Reporter represents a offset reporter.
 type Reporter interface {
@@ -12,6 +17,15 @@ type Reporter interface {
 
 	// ReportConsumerOffsets reports a snapshot of the consumer group offsets.
 	ReportConsumerOffsets(o *store.ConsumerOffsets)
+
+	// ReportClusterHealth reports a snapshot of the cluster partition health.
+	ReportClusterHealth(h *store.ClusterHealth)
+
+	// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+	ReportBrokerLogDirs(l *store.BrokerLogDirs)
+
+	// ReportClusterSummary reports a summary of the cluster brokers.
+	ReportClusterSummary(s *kafka.ClusterSummary)
 }
 
 // Reporters represents a set of reporters.
@@ -42,3 +56,33 @@ func (rs *Reporters) ReportConsumerOffsets(v *store.ConsumerOffsets) {
 		r.ReportConsumerOffsets(v)
 	}
 }
+
+// ReportClusterHealth reports a snapshot of the cluster partition health on all reporters.
+func (rs *Reporters) ReportClusterHealth(v *store.ClusterHealth) {
+	for _, r := range *rs {
+		r.ReportClusterHealth(v)
+	}
+}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories on all reporters.
+func (rs *Reporters) ReportBrokerLogDirs(v *store.BrokerLogDirs) {
+	for _, r := range *rs {
+		r.ReportBrokerLogDirs(v)
+	}
+}
+
+// ReportClusterSummary reports a summary of the cluster brokers on all reporters.
+func (rs *Reporters) ReportClusterSummary(v *kafka.ClusterSummary) {
+	for _, r := range *rs {
+		r.ReportClusterSummary(v)
+	}
+}
+
+// Close closes the reporters holding resources.
+func (rs *Reporters) Close() {
+	for _, r := range *rs {
+		if c, ok := r.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+}
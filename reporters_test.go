@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/msales/kage"
+	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/store"
 	"github.com/msales/kage/testutil/mocks"
 	"github.com/stretchr/testify/assert"
@@ -81,3 +82,89 @@ func TestReporters_ReportBrokerMetadata(t *testing.T) {
 
 	m1.AssertExpectations(t)
 }
+
+func TestReporters_ReportClusterHealth(t *testing.T) {
+	rs := kage.Reporters{}
+	health := &store.ClusterHealth{}
+
+	m1 := new(mocks.MockReporter)
+	m1.On("ReportClusterHealth", mock.AnythingOfType("*store.ClusterHealth")).Run(func(args mock.Arguments) {
+		assert.Equal(t, health, args.Get(0))
+	})
+	rs.Add("test1", m1)
+
+	m2 := new(mocks.MockReporter)
+	m2.On("ReportClusterHealth", mock.AnythingOfType("*store.ClusterHealth")).Run(func(args mock.Arguments) {
+		assert.Equal(t, health, args.Get(0))
+	})
+	rs.Add("test2", m2)
+
+	rs.ReportClusterHealth(health)
+
+	m1.AssertExpectations(t)
+}
+
+func TestReporters_ReportBrokerLogDirs(t *testing.T) {
+	rs := kage.Reporters{}
+	logDirs := &store.BrokerLogDirs{}
+
+	m1 := new(mocks.MockReporter)
+	m1.On("ReportBrokerLogDirs", mock.AnythingOfType("*store.BrokerLogDirs")).Run(func(args mock.Arguments) {
+		assert.Equal(t, logDirs, args.Get(0))
+	})
+	rs.Add("test1", m1)
+
+	m2 := new(mocks.MockReporter)
+	m2.On("ReportBrokerLogDirs", mock.AnythingOfType("*store.BrokerLogDirs")).Run(func(args mock.Arguments) {
+		assert.Equal(t, logDirs, args.Get(0))
+	})
+	rs.Add("test2", m2)
+
+	rs.ReportBrokerLogDirs(logDirs)
+
+	m1.AssertExpectations(t)
+}
+
+func TestReporters_ReportClusterSummary(t *testing.T) {
+	rs := kage.Reporters{}
+	summary := &kafka.ClusterSummary{}
+
+	m1 := new(mocks.MockReporter)
+	m1.On("ReportClusterSummary", mock.AnythingOfType("*kafka.ClusterSummary")).Run(func(args mock.Arguments) {
+		assert.Equal(t, summary, args.Get(0))
+	})
+	rs.Add("test1", m1)
+
+	m2 := new(mocks.MockReporter)
+	m2.On("ReportClusterSummary", mock.AnythingOfType("*kafka.ClusterSummary")).Run(func(args mock.Arguments) {
+		assert.Equal(t, summary, args.Get(0))
+	})
+	rs.Add("test2", m2)
+
+	rs.ReportClusterSummary(summary)
+
+	m1.AssertExpectations(t)
+}
+
+type closingReporter struct {
+	mocks.MockReporter
+
+	closed bool
+}
+
+func (r *closingReporter) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestReporters_Close(t *testing.T) {
+	rs := kage.Reporters{}
+
+	r := &closingReporter{}
+	rs.Add("test1", r)
+	rs.Add("test2", new(mocks.MockReporter))
+
+	rs.Close()
+
+	assert.True(t, r.closed)
+}
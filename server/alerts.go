@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/msales/kage"
+)
+
+type alertStatus struct {
+	Rule      string            `json:"rule"`
+	Type      string            `json:"type"`
+	Cluster   string            `json:"cluster,omitempty"`
+	Labels    map[string]string `json:"labels"`
+	State     string            `json:"state"`
+	Value     float64           `json:"value"`
+	Threshold float64           `json:"threshold"`
+	Message   string            `json:"message"`
+	Since     time.Time         `json:"since"`
+	FiredAt   *time.Time        `json:"fired_at,omitempty"`
+}
+
+// AlertsHandler handles requests for the pending and firing alerts.
+func (s *Server) AlertsHandler(w http.ResponseWriter, r *http.Request) {
+	alerts := []alertStatus{}
+	if s.Alerter == nil {
+		s.writeJSON(w, alerts)
+		return
+	}
+
+	c, scoped := r.Context().Value(clusterKey{}).(*kage.Cluster)
+	for _, a := range s.Alerter.Alerts() {
+		if scoped && a.Cluster != c.Name {
+			continue
+		}
+
+		status := alertStatus{
+			Rule:      a.Rule,
+			Type:      a.Type,
+			Cluster:   a.Cluster,
+			Labels:    a.Labels,
+			State:     a.State,
+			Value:     a.Value,
+			Threshold: a.Threshold,
+			Message:   a.Message,
+			Since:     a.Since,
+		}
+		if !a.FiredAt.IsZero() {
+			firedAt := a.FiredAt
+			status.FiredAt = &firedAt
+		}
+
+		alerts = append(alerts, status)
+	}
+
+	s.writeJSON(w, alerts)
+}
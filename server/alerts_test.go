@@ -0,0 +1,94 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/msales/kage"
+	"github.com/msales/kage/alert"
+	"github.com/msales/kage/server"
+	"github.com/msales/kage/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertsHandler(t *testing.T) {
+	ts := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	alerter := new(mocks.MockAlerter)
+	alerter.On("Alerts").Return([]alert.Alert{
+		{
+			Rule:      "lag",
+			Type:      alert.RuleConsumerLag,
+			Cluster:   "eu",
+			Labels:    map[string]string{"group": "foo", "topic": "bar"},
+			State:     alert.StateFiring,
+			Value:     1500,
+			Threshold: 1000,
+			Message:   "cluster eu: consumer group foo lag on topic bar is 1500 (threshold 1000)",
+			Since:     ts,
+			FiredAt:   ts.Add(time.Minute),
+		},
+		{
+			Rule:    "brokers",
+			Type:    alert.RuleBrokerDisconnected,
+			Cluster: "us",
+			Labels:  map[string]string{"broker": "1"},
+			State:   alert.StatePending,
+			Value:   1,
+			Message: "cluster us: broker 1 is disconnected",
+			Since:   ts,
+		},
+	})
+
+	app := newClustersApplication()
+	app.Alerter = alerter
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{
+			path: "/alerts",
+			want: "[{\"rule\":\"lag\",\"type\":\"consumer_lag\",\"cluster\":\"eu\",\"labels\":{\"group\":\"foo\",\"topic\":\"bar\"},\"state\":\"firing\",\"value\":1500,\"threshold\":1000,\"message\":\"cluster eu: consumer group foo lag on topic bar is 1500 (threshold 1000)\",\"since\":\"2020-09-01T12:00:00Z\",\"fired_at\":\"2020-09-01T12:01:00Z\"}," +
+				"{\"rule\":\"brokers\",\"type\":\"broker_disconnected\",\"cluster\":\"us\",\"labels\":{\"broker\":\"1\"},\"state\":\"pending\",\"value\":1,\"threshold\":0,\"message\":\"cluster us: broker 1 is disconnected\",\"since\":\"2020-09-01T12:00:00Z\"}]",
+		},
+		{
+			path: "/clusters/us/alerts",
+			want: "[{\"rule\":\"brokers\",\"type\":\"broker_disconnected\",\"cluster\":\"us\",\"labels\":{\"broker\":\"1\"},\"state\":\"pending\",\"value\":1,\"threshold\":0,\"message\":\"cluster us: broker 1 is disconnected\",\"since\":\"2020-09-01T12:00:00Z\"}]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			srv := server.New(app)
+			srv.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.want, rr.Body.String())
+		})
+	}
+}
+
+func TestAlertsHandler_NoAlerter(t *testing.T) {
+	req, err := http.NewRequest("GET", "/alerts", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	srv := server.New(&kage.Application{})
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "[]", rr.Body.String())
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-zoo/bone"
+	"github.com/msales/kage"
+)
+
+// clusterHeader is the response header containing the cluster name.
+const clusterHeader = "X-Kage-Cluster"
+
+type clusterKey struct{}
+
+// scope represents the clusters a request applies to.
+type scope interface {
+	Collect()
+	Flush(ctx context.Context) error
+	IsHealthy() bool
+}
+
+type clusterStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+}
+
+// ClustersHandler handles requests for the monitored clusters.
+func (s *Server) ClustersHandler(w http.ResponseWriter, r *http.Request) {
+	clusters := []clusterStatus{}
+	for _, name := range s.Clusters.Names() {
+		clusters = append(clusters, clusterStatus{
+			Name:    name,
+			Healthy: s.Clusters[name].IsHealthy(),
+		})
+	}
+
+	s.writeJSON(w, clusters)
+}
+
+// get registers the handler for the path on the default cluster and on the named clusters.
+func (s *Server) get(path string, h http.HandlerFunc) {
+	s.mux.GetFunc(path, s.withDefaultCluster(h))
+	s.mux.GetFunc("/clusters/:cluster"+path, s.withCluster(h))
+}
+
+// post registers the handler for the path on the default cluster and on the named clusters.
+func (s *Server) post(path string, h http.HandlerFunc) {
+	s.mux.PostFunc(path, s.withDefaultCluster(h))
+	s.mux.PostFunc("/clusters/:cluster"+path, s.withCluster(h))
+}
+
+// withDefaultCluster tags the response with the default cluster name.
+func (s *Server) withDefaultCluster(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.Name != "" {
+			w.Header().Set(clusterHeader, s.Name)
+		}
+
+		h(w, r)
+	}
+}
+
+// withCluster resolves the named cluster of the request, or responds with a 404 status code.
+func (s *Server) withCluster(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, ok := s.Cluster(bone.GetValue(r, "cluster"))
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set(clusterHeader, c.Name)
+
+		h(w, r.WithContext(context.WithValue(r.Context(), clusterKey{}, c)))
+	}
+}
+
+// cluster returns the cluster of the request.
+func (s *Server) cluster(r *http.Request) *kage.Cluster {
+	if c, ok := r.Context().Value(clusterKey{}).(*kage.Cluster); ok {
+		return c
+	}
+
+	return s.DefaultCluster()
+}
+
+// scope returns the named cluster of the request, or the whole application.
+func (s *Server) scope(r *http.Request) scope {
+	if c, ok := r.Context().Value(clusterKey{}).(*kage.Cluster); ok {
+		return c
+	}
+
+	return s.Application
+}
@@ -0,0 +1,109 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/msales/kage"
+	"github.com/msales/kage/kafka"
+	"github.com/msales/kage/server"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func newClustersApplication() *kage.Application {
+	euMonitor := new(mocks.MockMonitor)
+	euMonitor.On("Brokers").Return([]kafka.Broker{{ID: 0, Connected: true}})
+	euMonitor.On("IsHealthy").Return(true)
+
+	usMonitor := new(mocks.MockMonitor)
+	usMonitor.On("Brokers").Return([]kafka.Broker{{ID: 1, Connected: false}})
+	usMonitor.On("IsHealthy").Return(false)
+
+	usStore := new(mocks.MockStore)
+	usStore.On("BrokerOffsets").Return(store.BrokerOffsets{
+		"test": []*store.BrokerOffset{{OldestOffset: 0, NewestOffset: 100}},
+	})
+	usStore.On("BrokerMetadata").Return(store.BrokerMetadata{})
+	usStore.On("ConsumerOffsets").Return(store.ConsumerOffsets{})
+
+	eu := &kage.Cluster{Name: "eu", Monitor: euMonitor}
+	us := &kage.Cluster{Name: "us", Monitor: usMonitor, Store: usStore}
+
+	return &kage.Application{
+		Name:     "eu",
+		Monitor:  euMonitor,
+		Clusters: kage.Clusters{"eu": eu, "us": us},
+	}
+}
+
+func TestClustersHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/clusters", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	srv := server.New(newClustersApplication())
+	srv.ServeHTTP(rr, req)
+
+	want := "[{\"name\":\"eu\",\"healthy\":true},{\"name\":\"us\",\"healthy\":false}]"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestClusterRoutes(t *testing.T) {
+	tests := []struct {
+		path    string
+		cluster string
+		code    int
+		body    string
+	}{
+		{path: "/brokers", cluster: "eu", code: http.StatusOK, body: "[{\"id\":0,\"connected\":true,\"address\":\"\",\"rack\":\"\",\"controller\":false,\"leaders\":0,\"replicas\":0,\"last_seen\":null}]"},
+		{path: "/clusters/eu/brokers", cluster: "eu", code: http.StatusOK, body: "[{\"id\":0,\"connected\":true,\"address\":\"\",\"rack\":\"\",\"controller\":false,\"leaders\":0,\"replicas\":0,\"last_seen\":null}]"},
+		{path: "/clusters/us/brokers", cluster: "us", code: http.StatusOK, body: "[{\"id\":1,\"connected\":false,\"address\":\"\",\"rack\":\"\",\"controller\":false,\"leaders\":0,\"replicas\":0,\"last_seen\":null}]"},
+		{path: "/clusters/us/health", cluster: "us", code: http.StatusInternalServerError},
+		{path: "/clusters/eu/health", cluster: "eu", code: http.StatusOK},
+		{path: "/health", cluster: "eu", code: http.StatusInternalServerError},
+		{path: "/clusters/none/brokers", code: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			srv := server.New(newClustersApplication())
+			srv.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.cluster, rr.Header().Get("X-Kage-Cluster"))
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestClusterMetricsHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/clusters/us/metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	srv := server.New(newClustersApplication())
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.Contains(rr.Body.String(), "kage_topic_partition_newest_offset{cluster=\"us\",topic=\"test\",partition=\"0\"} 100\n"))
+}
@@ -2,27 +2,84 @@ package server
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/go-zoo/bone"
 	"github.com/msales/kage/store"
 )
 
 type consumerGroup struct {
-	Group      string              `json:"group"`
-	Topic      string              `json:"topic"`
-	TotalLag   int64               `json:"total_lag"`
-	Partitions []consumerPartition `json:"partitions"`
+	Group             string              `json:"group"`
+	Topic             string              `json:"topic"`
+	TotalLag          int64               `json:"total_lag"`
+	MaxTimeLagSeconds float64             `json:"max_time_lag_seconds"`
+	ConsumeRate       float64             `json:"consume_rate"`
+	ETASeconds        *float64            `json:"eta_seconds"`
+	CatchingUp        bool                `json:"catching_up"`
+	DataLoss          bool                `json:"data_loss"`
+	AtRisk            bool                `json:"at_risk"`
+	Partitions        []consumerPartition `json:"partitions"`
 }
 
 type consumerPartition struct {
-	Partition int   `json:"partition"`
-	Offset    int64 `json:"offset"`
-	Lag       int64 `json:"lag"`
+	Partition      int     `json:"partition"`
+	Offset         int64   `json:"offset"`
+	Lag            int64   `json:"lag"`
+	TimeLagSeconds float64 `json:"time_lag_seconds"`
+	ConsumeRate    float64 `json:"consume_rate"`
+
+	DistanceToOldest int64 `json:"distance_to_oldest"`
+	DataLoss         bool  `json:"data_loss"`
+	AtRisk           bool  `json:"at_risk"`
+}
+
+type consumerGroupStatus struct {
+	Group      string                    `json:"group"`
+	Status     string                    `json:"status"`
+	TotalLag   int64                     `json:"total_lag"`
+	Partitions []consumerPartitionStatus `json:"partitions"`
+}
+
+type consumerPartitionStatus struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Status    string `json:"status"`
+	Offset    int64  `json:"offset"`
+	Lag       int64  `json:"lag"`
+}
+
+type consumerGroupMembers struct {
+	Group        string                    `json:"group"`
+	State        string                    `json:"state"`
+	ProtocolType string                    `json:"protocol_type"`
+	Protocol     string                    `json:"protocol"`
+	Members      []consumerMember          `json:"members"`
+	Partitions   []consumerPartitionMember `json:"partitions"`
+}
+
+type consumerMember struct {
+	MemberID   string               `json:"member_id"`
+	ClientID   string               `json:"client_id"`
+	ClientHost string               `json:"client_host"`
+	Assignment []consumerAssignment `json:"assignment"`
+}
+
+type consumerAssignment struct {
+	Topic      string  `json:"topic"`
+	Partitions []int32 `json:"partitions"`
+}
+
+type consumerPartitionMember struct {
+	Topic      string `json:"topic"`
+	Partition  int32  `json:"partition"`
+	MemberID   string `json:"member_id"`
+	ClientID   string `json:"client_id"`
+	ClientHost string `json:"client_host"`
 }
 
 // ConsumerGroupsHandler handles requests for consumer groups offsets.
 func (s *Server) ConsumerGroupsHandler(w http.ResponseWriter, r *http.Request) {
-	offsets := s.Store.ConsumerOffsets()
+	offsets := s.cluster(r).Store.ConsumerOffsets()
 
 	groups := []consumerGroup{}
 	for group, topics := range offsets {
@@ -34,7 +91,7 @@ func (s *Server) ConsumerGroupsHandler(w http.ResponseWriter, r *http.Request) {
 
 // ConsumerGroupHandler handles requests for a consumer group offsets.
 func (s *Server) ConsumerGroupHandler(w http.ResponseWriter, r *http.Request) {
-	offsets := s.Store.ConsumerOffsets()
+	offsets := s.cluster(r).Store.ConsumerOffsets()
 
 	group := bone.GetValue(r, "group")
 	topics, ok := offsets[group]
@@ -48,6 +105,110 @@ func (s *Server) ConsumerGroupHandler(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, groups)
 }
 
+// ConsumerGroupStatusHandler handles requests for a consumer group status.
+func (s *Server) ConsumerGroupStatusHandler(w http.ResponseWriter, r *http.Request) {
+	offsets := s.cluster(r).Store.ConsumerOffsets()
+
+	group := bone.GetValue(r, "group")
+	topics, ok := offsets[group]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	status := consumerGroupStatus{
+		Group:      group,
+		Status:     store.EvaluateConsumerGroup(topics).String(),
+		Partitions: []consumerPartitionStatus{},
+	}
+	for topic, partitions := range topics {
+		for i, partition := range partitions {
+			if partition == nil {
+				continue
+			}
+
+			status.TotalLag += partition.Lag
+			status.Partitions = append(status.Partitions, consumerPartitionStatus{
+				Topic:     topic,
+				Partition: i,
+				Status:    partition.Status.String(),
+				Offset:    partition.Offset,
+				Lag:       partition.Lag,
+			})
+		}
+	}
+
+	sort.Slice(status.Partitions, func(i, j int) bool {
+		if status.Partitions[i].Topic != status.Partitions[j].Topic {
+			return status.Partitions[i].Topic < status.Partitions[j].Topic
+		}
+		return status.Partitions[i].Partition < status.Partitions[j].Partition
+	})
+
+	s.writeJSON(w, status)
+}
+
+// ConsumerGroupMembersHandler handles requests for a consumer group membership.
+func (s *Server) ConsumerGroupMembersHandler(w http.ResponseWriter, r *http.Request) {
+	groups := s.cluster(r).Store.ConsumerGroups()
+
+	group := bone.GetValue(r, "group")
+	desc, ok := groups[group]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	members := consumerGroupMembers{
+		Group:        group,
+		State:        desc.State,
+		ProtocolType: desc.ProtocolType,
+		Protocol:     desc.Protocol,
+		Members:      []consumerMember{},
+		Partitions:   []consumerPartitionMember{},
+	}
+	for _, member := range desc.Members {
+		m := consumerMember{
+			MemberID:   member.MemberID,
+			ClientID:   member.ClientID,
+			ClientHost: member.ClientHost,
+			Assignment: []consumerAssignment{},
+		}
+
+		for topic, partitions := range member.Assignment {
+			m.Assignment = append(m.Assignment, consumerAssignment{Topic: topic, Partitions: partitions})
+
+			for _, partition := range partitions {
+				members.Partitions = append(members.Partitions, consumerPartitionMember{
+					Topic:      topic,
+					Partition:  partition,
+					MemberID:   member.MemberID,
+					ClientID:   member.ClientID,
+					ClientHost: member.ClientHost,
+				})
+			}
+		}
+
+		sort.Slice(m.Assignment, func(i, j int) bool {
+			return m.Assignment[i].Topic < m.Assignment[j].Topic
+		})
+
+		members.Members = append(members.Members, m)
+	}
+
+	sort.Slice(members.Members, func(i, j int) bool {
+		return members.Members[i].MemberID < members.Members[j].MemberID
+	})
+	sort.Slice(members.Partitions, func(i, j int) bool {
+		if members.Partitions[i].Topic != members.Partitions[j].Topic {
+			return members.Partitions[i].Topic < members.Partitions[j].Topic
+		}
+		return members.Partitions[i].Partition < members.Partitions[j].Partition
+	})
+
+	s.writeJSON(w, members)
+}
+
 func createConsumerGroup(group string, topics map[string][]*store.ConsumerOffset) []consumerGroup {
 	groups := []consumerGroup{}
 	for topic, partitions := range topics {
@@ -63,15 +224,32 @@ func createConsumerGroup(group string, topics map[string][]*store.ConsumerOffset
 			}
 
 			bp := consumerPartition{
-				Partition: i,
-				Offset:    partition.Offset,
-				Lag:       partition.Lag,
+				Partition:      i,
+				Offset:         partition.Offset,
+				Lag:            partition.Lag,
+				TimeLagSeconds: float64(partition.TimeLag) / 1000,
+				ConsumeRate:    partition.ConsumeRate,
+
+				DistanceToOldest: partition.DistanceToOldest,
+				DataLoss:         partition.DataLoss,
+				AtRisk:           partition.AtRisk,
 			}
 
 			bt.TotalLag += bp.Lag
+			bt.DataLoss = bt.DataLoss || bp.DataLoss
+			bt.AtRisk = bt.AtRisk || bp.AtRisk
+			bt.ConsumeRate += bp.ConsumeRate
+			if bp.TimeLagSeconds > bt.MaxTimeLagSeconds {
+				bt.MaxTimeLagSeconds = bp.TimeLagSeconds
+			}
 			bt.Partitions[i] = bp
 		}
 
+		if eta, ok := store.EstimateCatchUp(partitions); ok {
+			bt.ETASeconds = &eta
+			bt.CatchingUp = true
+		}
+
 		groups = append(groups, bt)
 	}
 
@@ -22,7 +22,7 @@ func TestConsumerGroupsHandler(t *testing.T) {
 
 	co := store.ConsumerOffsets{
 		"test": map[string][]*store.ConsumerOffset{
-			"test": {{Offset: 0, Lag: 100, Timestamp: 0}},
+			"test": {{Offset: 0, Lag: 100, TimeLag: 1500, ConsumeRate: 2.5, Timestamp: 0}},
 		},
 	}
 
@@ -34,7 +34,7 @@ func TestConsumerGroupsHandler(t *testing.T) {
 	srv := server.New(app)
 	srv.ServeHTTP(rr, req)
 
-	want := "[{\"group\":\"test\",\"topic\":\"test\",\"total_lag\":100,\"partitions\":[{\"partition\":0,\"offset\":0,\"lag\":100}]}]"
+	want := "[{\"group\":\"test\",\"topic\":\"test\",\"total_lag\":100,\"max_time_lag_seconds\":1.5,\"consume_rate\":2.5,\"eta_seconds\":null,\"catching_up\":false,\"data_loss\":false,\"at_risk\":false,\"partitions\":[{\"partition\":0,\"offset\":0,\"lag\":100,\"time_lag_seconds\":1.5,\"consume_rate\":2.5,\"distance_to_oldest\":0,\"data_loss\":false,\"at_risk\":false}]}]"
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Equal(t, want, rr.Body.String())
 }
@@ -49,7 +49,7 @@ func TestConsumerGroupHandler(t *testing.T) {
 
 	co := store.ConsumerOffsets{
 		"test": map[string][]*store.ConsumerOffset{
-			"test": {{Offset: 0, Lag: 100, Timestamp: 0}},
+			"test": {{Offset: 0, Lag: 100, TimeLag: 1500, ConsumeRate: 2.5, DrainRate: 2, DistanceToOldest: -20, DataLoss: true, AtRisk: true, Timestamp: 0}},
 		},
 	}
 
@@ -61,7 +61,7 @@ func TestConsumerGroupHandler(t *testing.T) {
 	srv := server.New(app)
 	srv.ServeHTTP(rr, req)
 
-	want := "[{\"group\":\"test\",\"topic\":\"test\",\"total_lag\":100,\"partitions\":[{\"partition\":0,\"offset\":0,\"lag\":100}]}]"
+	want := "[{\"group\":\"test\",\"topic\":\"test\",\"total_lag\":100,\"max_time_lag_seconds\":1.5,\"consume_rate\":2.5,\"eta_seconds\":50,\"catching_up\":true,\"data_loss\":true,\"at_risk\":true,\"partitions\":[{\"partition\":0,\"offset\":0,\"lag\":100,\"time_lag_seconds\":1.5,\"consume_rate\":2.5,\"distance_to_oldest\":-20,\"data_loss\":true,\"at_risk\":true}]}]"
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Equal(t, want, rr.Body.String())
 }
@@ -76,7 +76,7 @@ func TestConsumerGroupHandler_NotFound(t *testing.T) {
 
 	co := store.ConsumerOffsets{
 		"test": map[string][]*store.ConsumerOffset{
-			"test": {{Offset: 0, Lag: 100, Timestamp: 0}},
+			"test": {{Offset: 0, Lag: 100, TimeLag: 1500, ConsumeRate: 2.5, Timestamp: 0}},
 		},
 	}
 
@@ -90,3 +90,119 @@ func TestConsumerGroupHandler_NotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, rr.Code)
 }
+
+func TestConsumerGroupStatusHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/consumers/test/status", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	co := store.ConsumerOffsets{
+		"test": map[string][]*store.ConsumerOffset{
+			"test": {
+				{Offset: 0, Lag: 100, Timestamp: 0, Status: store.ConsumerStatusOK},
+				nil,
+				{Offset: 10, Lag: 50, Timestamp: 0, Status: store.ConsumerStatusStalled},
+			},
+		},
+	}
+
+	store := new(mocks.MockStore)
+	store.On("ConsumerOffsets").Return(co)
+
+	app := &kage.Application{Store: store}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	want := "{\"group\":\"test\",\"status\":\"STALLED\",\"total_lag\":150,\"partitions\":[{\"topic\":\"test\",\"partition\":0,\"status\":\"OK\",\"offset\":0,\"lag\":100},{\"topic\":\"test\",\"partition\":2,\"status\":\"STALLED\",\"offset\":10,\"lag\":50}]}"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestConsumerGroupStatusHandler_NotFound(t *testing.T) {
+	req, err := http.NewRequest("GET", "/consumers/none/status", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	co := store.ConsumerOffsets{}
+
+	store := new(mocks.MockStore)
+	store.On("ConsumerOffsets").Return(co)
+
+	app := &kage.Application{Store: store}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestConsumerGroupMembersHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/consumers/test/members", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	cg := store.ConsumerGroups{
+		"test": {
+			State:        "Stable",
+			ProtocolType: "consumer",
+			Protocol:     "range",
+			Members: []store.ConsumerGroupMember{
+				{
+					MemberID:   "consumer-2",
+					ClientID:   "consumer",
+					ClientHost: "/127.0.0.2",
+					Assignment: map[string][]int32{"test": {1}},
+				},
+				{
+					MemberID:   "consumer-1",
+					ClientID:   "consumer",
+					ClientHost: "/127.0.0.1",
+					Assignment: map[string][]int32{"test": {0}},
+				},
+			},
+		},
+	}
+
+	store := new(mocks.MockStore)
+	store.On("ConsumerGroups").Return(cg)
+
+	app := &kage.Application{Store: store}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	want := "{\"group\":\"test\",\"state\":\"Stable\",\"protocol_type\":\"consumer\",\"protocol\":\"range\",\"members\":[{\"member_id\":\"consumer-1\",\"client_id\":\"consumer\",\"client_host\":\"/127.0.0.1\",\"assignment\":[{\"topic\":\"test\",\"partitions\":[0]}]},{\"member_id\":\"consumer-2\",\"client_id\":\"consumer\",\"client_host\":\"/127.0.0.2\",\"assignment\":[{\"topic\":\"test\",\"partitions\":[1]}]}],\"partitions\":[{\"topic\":\"test\",\"partition\":0,\"member_id\":\"consumer-1\",\"client_id\":\"consumer\",\"client_host\":\"/127.0.0.1\"},{\"topic\":\"test\",\"partition\":1,\"member_id\":\"consumer-2\",\"client_id\":\"consumer\",\"client_host\":\"/127.0.0.2\"}]}"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestConsumerGroupMembersHandler_NotFound(t *testing.T) {
+	req, err := http.NewRequest("GET", "/consumers/none/members", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	cg := store.ConsumerGroups{}
+
+	store := new(mocks.MockStore)
+	store.On("ConsumerGroups").Return(cg)
+
+	app := &kage.Application{Store: store}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+)
+
+type clusterHealth struct {
+	UnderReplicated    int           `json:"under_replicated"`
+	UnderMinIsr        int           `json:"under_min_isr"`
+	Offline            int           `json:"offline"`
+	NonPreferredLeader int           `json:"non_preferred_leader"`
+	Topics             []topicHealth `json:"topics"`
+}
+
+type topicHealth struct {
+	Topic              string  `json:"topic"`
+	Partitions         int     `json:"partitions"`
+	UnderReplicated    []int32 `json:"under_replicated"`
+	UnderMinIsr        []int32 `json:"under_min_isr"`
+	Offline            []int32 `json:"offline"`
+	NonPreferredLeader []int32 `json:"non_preferred_leader"`
+}
+
+// ClusterHealthHandler handles requests for the cluster partition health.
+func (s *Server) ClusterHealthHandler(w http.ResponseWriter, r *http.Request) {
+	h := s.cluster(r).Health()
+
+	ch := clusterHealth{
+		UnderReplicated:    h.UnderReplicated,
+		UnderMinIsr:        h.UnderMinIsr,
+		Offline:            h.Offline,
+		NonPreferredLeader: h.NonPreferredLeader,
+		Topics:             []topicHealth{},
+	}
+	for topic, th := range h.Topics {
+		ch.Topics = append(ch.Topics, topicHealth{
+			Topic:              topic,
+			Partitions:         th.Partitions,
+			UnderReplicated:    partitionList(th.UnderReplicated),
+			UnderMinIsr:        partitionList(th.UnderMinIsr),
+			Offline:            partitionList(th.Offline),
+			NonPreferredLeader: partitionList(th.NonPreferredLeader),
+		})
+	}
+	sort.Slice(ch.Topics, func(i, j int) bool {
+		return ch.Topics[i].Topic < ch.Topics[j].Topic
+	})
+
+	s.writeJSON(w, ch)
+}
+
+// partitionList returns the partitions, encoding nil as an empty list.
+func partitionList(partitions []int32) []int32 {
+	if partitions == nil {
+		return []int32{}
+	}
+
+	return partitions
+}
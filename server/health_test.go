@@ -0,0 +1,48 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msales/kage"
+	"github.com/msales/kage/server"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterHealthHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/cluster/health", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	md := store.BrokerMetadata{
+		"foo": {
+			{Leader: 2, Replicas: []int32{1, 2}, Isr: []int32{2}},
+			{Leader: -1, Replicas: []int32{1, 2}, Isr: []int32{}},
+		},
+		"bar": {
+			{Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1, 2}},
+		},
+	}
+	configs := store.TopicConfigs{
+		"foo": {Configs: map[string]string{"min.insync.replicas": "2"}},
+	}
+
+	s := new(mocks.MockStore)
+	s.On("BrokerMetadata").Return(md)
+	s.On("TopicConfigs").Return(configs)
+
+	app := &kage.Application{Store: s}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	want := "{\"under_replicated\":2,\"under_min_isr\":2,\"offline\":1,\"non_preferred_leader\":1,\"topics\":[{\"topic\":\"bar\",\"partitions\":1,\"under_replicated\":[],\"under_min_isr\":[],\"offline\":[],\"non_preferred_leader\":[]},{\"topic\":\"foo\",\"partitions\":2,\"under_replicated\":[0,1],\"under_min_isr\":[0,1],\"offline\":[1],\"non_preferred_leader\":[0]}]}"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-zoo/bone"
+	"github.com/msales/kage/store"
+)
+
+type consumerHistory struct {
+	Group      string                     `json:"group"`
+	Topic      string                     `json:"topic"`
+	Partitions []consumerPartitionHistory `json:"partitions"`
+}
+
+type consumerPartitionHistory struct {
+	Partition int                    `json:"partition"`
+	Points    []consumerHistoryPoint `json:"points"`
+}
+
+type consumerHistoryPoint struct {
+	Timestamp int64 `json:"timestamp"`
+	Offset    int64 `json:"offset"`
+	Lag       int64 `json:"lag"`
+}
+
+type topicHistory struct {
+	Topic      string                  `json:"topic"`
+	Partitions []topicPartitionHistory `json:"partitions"`
+}
+
+type topicPartitionHistory struct {
+	Partition int                 `json:"partition"`
+	Points    []topicHistoryPoint `json:"points"`
+}
+
+type topicHistoryPoint struct {
+	Timestamp int64 `json:"timestamp"`
+	Oldest    int64 `json:"oldest"`
+	Newest    int64 `json:"newest"`
+	Available int64 `json:"available"`
+}
+
+// ConsumerGroupHistoryHandler handles requests for the lag history of a consumer group.
+func (s *Server) ConsumerGroupHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	rng, err := parseHistoryRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	offsets := s.cluster(r).Store.ConsumerOffsets()
+
+	group := bone.GetValue(r, "group")
+	topics, ok := offsets[group]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	names := make([]string, 0, len(topics))
+	if topic := r.URL.Query().Get("topic"); topic != "" {
+		if _, ok := topics[topic]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		names = append(names, topic)
+	} else {
+		for topic := range topics {
+			names = append(names, topic)
+		}
+		sort.Strings(names)
+	}
+
+	history := []consumerHistory{}
+	for _, topic := range names {
+		ch := consumerHistory{
+			Group:      group,
+			Topic:      topic,
+			Partitions: []consumerPartitionHistory{},
+		}
+
+		for i, points := range s.cluster(r).Store.ConsumerHistory(group, topic, rng) {
+			ph := consumerPartitionHistory{
+				Partition: i,
+				Points:    make([]consumerHistoryPoint, len(points)),
+			}
+			for j, p := range points {
+				ph.Points[j] = consumerHistoryPoint{
+					Timestamp: p.Timestamp,
+					Offset:    p.Offset,
+					Lag:       p.Lag,
+				}
+			}
+
+			ch.Partitions = append(ch.Partitions, ph)
+		}
+
+		history = append(history, ch)
+	}
+
+	s.writeJSON(w, history)
+}
+
+// TopicHistoryHandler handles requests for the offset history of a topic.
+func (s *Server) TopicHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	rng, err := parseHistoryRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	topic := bone.GetValue(r, "topic")
+	partitions := s.cluster(r).Store.BrokerHistory(topic, rng)
+	if partitions == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	history := topicHistory{
+		Topic:      topic,
+		Partitions: make([]topicPartitionHistory, len(partitions)),
+	}
+	for i, points := range partitions {
+		ph := topicPartitionHistory{
+			Partition: i,
+			Points:    make([]topicHistoryPoint, len(points)),
+		}
+		for j, p := range points {
+			ph.Points[j] = topicHistoryPoint{
+				Timestamp: p.Timestamp,
+				Oldest:    p.OldestOffset,
+				Newest:    p.Offset,
+				Available: p.Offset - p.OldestOffset,
+			}
+		}
+
+		history.Partitions[i] = ph
+	}
+
+	s.writeJSON(w, history)
+}
+
+// parseHistoryRange parses the from, to and step query parameters of a history request.
+//
+// The from and to times are either RFC3339 or Unix timestamps in milliseconds,
+// and the step is a duration (e.g. "5m").
+func parseHistoryRange(r *http.Request) (store.HistoryRange, error) {
+	q := r.URL.Query()
+
+	var (
+		rng store.HistoryRange
+		err error
+	)
+	if rng.From, err = parseHistoryTime(q.Get("from")); err != nil {
+		return rng, fmt.Errorf("invalid from: %w", err)
+	}
+	if rng.To, err = parseHistoryTime(q.Get("to")); err != nil {
+		return rng, fmt.Errorf("invalid to: %w", err)
+	}
+
+	if step := q.Get("step"); step != "" {
+		d, err := time.ParseDuration(step)
+		if err != nil || d < 0 {
+			return rng, fmt.Errorf("invalid step \"%s\"", step)
+		}
+		rng.Step = d.Milliseconds()
+	}
+
+	return rng, nil
+}
+
+// parseHistoryTime parses an RFC3339 time or a Unix timestamp in milliseconds.
+func parseHistoryTime(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return ms, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.UnixNano() / int64(time.Millisecond), nil
+}
@@ -0,0 +1,132 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msales/kage"
+	"github.com/msales/kage/server"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerGroupHistoryHandler(t *testing.T) {
+	co := store.ConsumerOffsets{
+		"foo": {
+			"test":  []*store.ConsumerOffset{{Offset: 10, Lag: 5}},
+			"other": []*store.ConsumerOffset{{Offset: 10, Lag: 5}},
+		},
+	}
+	rng := store.HistoryRange{From: 1600000000000, To: 1600000060000, Step: 30000}
+
+	s := new(mocks.MockStore)
+	s.On("ConsumerOffsets").Return(co)
+	s.On("ConsumerHistory", "foo", "test", rng).Return([][]store.HistoryPoint{
+		{{Timestamp: 1600000000000, Offset: 5, Lag: 10}, {Timestamp: 1600000030000, Offset: 10, Lag: 5}},
+	})
+
+	app := &kage.Application{Store: s}
+
+	tests := []struct {
+		path string
+		code int
+		want string
+	}{
+		{
+			path: "/consumers/foo/history?topic=test&from=2020-09-13T12:26:40Z&to=1600000060000&step=30s",
+			code: http.StatusOK,
+			want: "[{\"group\":\"foo\",\"topic\":\"test\",\"partitions\":[{\"partition\":0,\"points\":[{\"timestamp\":1600000000000,\"offset\":5,\"lag\":10},{\"timestamp\":1600000030000,\"offset\":10,\"lag\":5}]}]}]",
+		},
+		{path: "/consumers/foo/history?topic=unknown", code: http.StatusNotFound, want: ""},
+		{path: "/consumers/unknown/history", code: http.StatusNotFound, want: ""},
+		{path: "/consumers/foo/history?from=yesterday", code: http.StatusBadRequest, want: "invalid from: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\"\n"},
+		{path: "/consumers/foo/history?step=-1m", code: http.StatusBadRequest, want: "invalid step \"-1m\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			srv := server.New(app)
+			srv.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, rr.Body.String())
+		})
+	}
+}
+
+func TestConsumerGroupHistoryHandler_AllTopics(t *testing.T) {
+	req, err := http.NewRequest("GET", "/consumers/foo/history", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	co := store.ConsumerOffsets{
+		"foo": {
+			"b": []*store.ConsumerOffset{{Offset: 10, Lag: 5}},
+			"a": []*store.ConsumerOffset{{Offset: 10, Lag: 5}},
+		},
+	}
+
+	s := new(mocks.MockStore)
+	s.On("ConsumerOffsets").Return(co)
+	s.On("ConsumerHistory", "foo", "a", store.HistoryRange{}).Return([][]store.HistoryPoint{nil})
+	s.On("ConsumerHistory", "foo", "b", store.HistoryRange{}).Return([][]store.HistoryPoint{})
+
+	srv := server.New(&kage.Application{Store: s})
+	srv.ServeHTTP(rr, req)
+
+	want := "[{\"group\":\"foo\",\"topic\":\"a\",\"partitions\":[{\"partition\":0,\"points\":[]}]},{\"group\":\"foo\",\"topic\":\"b\",\"partitions\":[]}]"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestTopicHistoryHandler(t *testing.T) {
+	s := new(mocks.MockStore)
+	s.On("BrokerHistory", "test", store.HistoryRange{}).Return([][]store.HistoryPoint{
+		{{Timestamp: 1600000000000, Offset: 100, OldestOffset: 10}},
+	})
+	s.On("BrokerHistory", "unknown", store.HistoryRange{}).Return([][]store.HistoryPoint(nil))
+
+	app := &kage.Application{Store: s}
+
+	tests := []struct {
+		path string
+		code int
+		want string
+	}{
+		{
+			path: "/topics/test/history",
+			code: http.StatusOK,
+			want: "{\"topic\":\"test\",\"partitions\":[{\"partition\":0,\"points\":[{\"timestamp\":1600000000000,\"oldest\":10,\"newest\":100,\"available\":90}]}]}",
+		},
+		{path: "/topics/unknown/history", code: http.StatusNotFound, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			srv := server.New(app)
+			srv.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/go-zoo/bone"
+)
+
+type brokerLogDirs struct {
+	Broker  int32    `json:"broker"`
+	Size    int64    `json:"size"`
+	LogDirs []logDir `json:"log_dirs"`
+}
+
+type logDir struct {
+	Path     string          `json:"path"`
+	Size     int64           `json:"size"`
+	Replicas []logDirReplica `json:"replicas"`
+}
+
+type logDirReplica struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Size      int64  `json:"size"`
+	OffsetLag int64  `json:"offset_lag"`
+	Temporary bool   `json:"temporary"`
+}
+
+type topicSize struct {
+	Topic      string          `json:"topic"`
+	Size       int64           `json:"size"`
+	Partitions []partitionSize `json:"partitions"`
+}
+
+type partitionSize struct {
+	Partition int32         `json:"partition"`
+	Size      int64         `json:"size"`
+	Replicas  []replicaSize `json:"replicas"`
+}
+
+type replicaSize struct {
+	Broker    int32  `json:"broker"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	OffsetLag int64  `json:"offset_lag"`
+	Temporary bool   `json:"temporary"`
+}
+
+// BrokerLogDirsHandler handles requests for the log directories of a broker.
+func (s *Server) BrokerLogDirsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(bone.GetValue(r, "id"), 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	dirs, ok := s.cluster(r).Store.BrokerLogDirs()[int32(id)]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	bl := brokerLogDirs{
+		Broker:  int32(id),
+		LogDirs: []logDir{},
+	}
+	for _, dir := range dirs.LogDirs {
+		ld := logDir{
+			Path:     dir.Path,
+			Size:     dir.Size(),
+			Replicas: make([]logDirReplica, 0, len(dir.Replicas)),
+		}
+		for _, replica := range dir.Replicas {
+			ld.Replicas = append(ld.Replicas, logDirReplica{
+				Topic:     replica.Topic,
+				Partition: replica.Partition,
+				Size:      replica.Size,
+				OffsetLag: replica.OffsetLag,
+				Temporary: replica.Temporary,
+			})
+		}
+
+		bl.Size += ld.Size
+		bl.LogDirs = append(bl.LogDirs, ld)
+	}
+
+	s.writeJSON(w, bl)
+}
+
+// TopicSizeHandler handles requests for the size on disk of a topic.
+func (s *Server) TopicSizeHandler(w http.ResponseWriter, r *http.Request) {
+	topic := bone.GetValue(r, "topic")
+
+	partitions := map[int32]*partitionSize{}
+	for broker, dirs := range s.cluster(r).Store.BrokerLogDirs() {
+		for _, dir := range dirs.LogDirs {
+			for _, replica := range dir.Replicas {
+				if replica.Topic != topic {
+					continue
+				}
+
+				ps, ok := partitions[replica.Partition]
+				if !ok {
+					ps = &partitionSize{Partition: replica.Partition}
+					partitions[replica.Partition] = ps
+				}
+
+				ps.Size += replica.Size
+				ps.Replicas = append(ps.Replicas, replicaSize{
+					Broker:    broker,
+					Path:      dir.Path,
+					Size:      replica.Size,
+					OffsetLag: replica.OffsetLag,
+					Temporary: replica.Temporary,
+				})
+			}
+		}
+	}
+
+	if len(partitions) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	ts := topicSize{
+		Topic:      topic,
+		Partitions: make([]partitionSize, 0, len(partitions)),
+	}
+	for _, ps := range partitions {
+		sort.Slice(ps.Replicas, func(i, j int) bool {
+			if ps.Replicas[i].Broker != ps.Replicas[j].Broker {
+				return ps.Replicas[i].Broker < ps.Replicas[j].Broker
+			}
+			return ps.Replicas[i].Path < ps.Replicas[j].Path
+		})
+
+		ts.Size += ps.Size
+		ts.Partitions = append(ts.Partitions, *ps)
+	}
+	sort.Slice(ts.Partitions, func(i, j int) bool {
+		return ts.Partitions[i].Partition < ts.Partitions[j].Partition
+	})
+
+	s.writeJSON(w, ts)
+}
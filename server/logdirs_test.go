@@ -0,0 +1,113 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msales/kage"
+	"github.com/msales/kage/server"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLogDirs = store.BrokerLogDirs{
+	1: {LogDirs: []store.LogDir{
+		{Path: "/data", Replicas: []store.LogDirReplica{
+			{Topic: "test", Partition: 0, Size: 1024},
+			{Topic: "other", Partition: 0, Size: 256},
+		}},
+	}},
+	2: {LogDirs: []store.LogDir{
+		{Path: "/data", Replicas: []store.LogDirReplica{
+			{Topic: "test", Partition: 0, Size: 1000, OffsetLag: 3},
+		}},
+	}},
+}
+
+func TestBrokerLogDirsHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/brokers/1/logdirs", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	s := new(mocks.MockStore)
+	s.On("BrokerLogDirs").Return(testLogDirs)
+
+	app := &kage.Application{Store: s}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	want := "{\"broker\":1,\"size\":1280,\"log_dirs\":[{\"path\":\"/data\",\"size\":1280,\"replicas\":[{\"topic\":\"test\",\"partition\":0,\"size\":1024,\"offset_lag\":0,\"temporary\":false},{\"topic\":\"other\",\"partition\":0,\"size\":256,\"offset_lag\":0,\"temporary\":false}]}]}"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestBrokerLogDirsHandler_NotFound(t *testing.T) {
+	tests := []string{"/brokers/3/logdirs", "/brokers/foo/logdirs"}
+
+	for _, path := range tests {
+		t.Run(path, func(t *testing.T) {
+			req, err := http.NewRequest("GET", path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			s := new(mocks.MockStore)
+			s.On("BrokerLogDirs").Return(testLogDirs)
+
+			app := &kage.Application{Store: s}
+
+			srv := server.New(app)
+			srv.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+	}
+}
+
+func TestTopicSizeHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/topics/test/size", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	s := new(mocks.MockStore)
+	s.On("BrokerLogDirs").Return(testLogDirs)
+
+	app := &kage.Application{Store: s}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	want := "{\"topic\":\"test\",\"size\":2024,\"partitions\":[{\"partition\":0,\"size\":2024,\"replicas\":[{\"broker\":1,\"path\":\"/data\",\"size\":1024,\"offset_lag\":0,\"temporary\":false},{\"broker\":2,\"path\":\"/data\",\"size\":1000,\"offset_lag\":3,\"temporary\":false}]}]}"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestTopicSizeHandler_NotFound(t *testing.T) {
+	req, err := http.NewRequest("GET", "/topics/none/size", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	s := new(mocks.MockStore)
+	s.On("BrokerLogDirs").Return(testLogDirs)
+
+	app := &kage.Application{Store: s}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
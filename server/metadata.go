@@ -18,7 +18,7 @@ type partitionMetadata struct {
 
 // MetadataHandler handles requests for topic metadata.
 func (s *Server) MetadataHandler(w http.ResponseWriter, r *http.Request) {
-	metadata := s.Store.BrokerMetadata()
+	metadata := s.cluster(r).Store.BrokerMetadata()
 
 	topics := []topicMetadata{}
 	for topic, partitions := range metadata {
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prometheus metric names exposed on the metrics endpoint.
+const (
+	metricTopicOldestOffset   = "kage_topic_partition_oldest_offset"
+	metricTopicNewestOffset   = "kage_topic_partition_newest_offset"
+	metricTopicAvailable      = "kage_topic_partition_available_messages"
+	metricTopicReplicas       = "kage_topic_partition_replicas"
+	metricTopicIsr            = "kage_topic_partition_in_sync_replicas"
+	metricTopicLeader         = "kage_topic_partition_leader"
+	metricConsumerGroupOffset = "kage_consumer_group_offset"
+	metricConsumerGroupLag    = "kage_consumer_group_lag"
+	metricConsumerGroupTime   = "kage_consumer_group_time_lag_seconds"
+	prometheusTextContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+type metricFamily struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+func (f *metricFamily) add(value float64, labels ...string) {
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+// withLabels returns the base labels followed by the label key value pairs.
+func withLabels(base []string, labels ...string) []string {
+	return append(append(make([]string, 0, len(base)+len(labels)), base...), labels...)
+}
+
+// MetricsHandler handles requests for metrics in the Prometheus text format.
+func (s *Server) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	c := s.cluster(r)
+
+	var clusterLabels []string
+	if c.Name != "" {
+		clusterLabels = []string{"cluster", c.Name}
+	}
+
+	oldest := &metricFamily{name: metricTopicOldestOffset, help: "The oldest available offset of a topic partition."}
+	newest := &metricFamily{name: metricTopicNewestOffset, help: "The newest offset of a topic partition."}
+	available := &metricFamily{name: metricTopicAvailable, help: "The number of messages available in a topic partition."}
+	for topic, partitions := range c.Store.BrokerOffsets() {
+		for partition, offset := range partitions {
+			if offset == nil {
+				continue
+			}
+
+			labels := withLabels(clusterLabels, "topic", topic, "partition", strconv.Itoa(partition))
+			oldest.add(float64(offset.OldestOffset), labels...)
+			newest.add(float64(offset.NewestOffset), labels...)
+			available.add(float64(offset.NewestOffset-offset.OldestOffset), labels...)
+		}
+	}
+
+	replicas := &metricFamily{name: metricTopicReplicas, help: "The number of replicas of a topic partition."}
+	isr := &metricFamily{name: metricTopicIsr, help: "The number of in-sync replicas of a topic partition."}
+	leader := &metricFamily{name: metricTopicLeader, help: "The broker ID of the leader of a topic partition, -1 if there is no leader."}
+	for topic, partitions := range c.Store.BrokerMetadata() {
+		for partition, metadata := range partitions {
+			if metadata == nil {
+				continue
+			}
+
+			labels := withLabels(clusterLabels, "topic", topic, "partition", strconv.Itoa(partition))
+			replicas.add(float64(len(metadata.Replicas)), labels...)
+			isr.add(float64(len(metadata.Isr)), labels...)
+			leader.add(float64(metadata.Leader), labels...)
+		}
+	}
+
+	groupOffset := &metricFamily{name: metricConsumerGroupOffset, help: "The committed offset of a consumer group on a topic partition."}
+	groupLag := &metricFamily{name: metricConsumerGroupLag, help: "The lag of a consumer group on a topic partition."}
+	groupTimeLag := &metricFamily{name: metricConsumerGroupTime, help: "The estimated time lag in seconds of a consumer group on a topic partition."}
+	for group, topics := range c.Store.ConsumerOffsets() {
+		for topic, partitions := range topics {
+			for partition, offset := range partitions {
+				if offset == nil {
+					continue
+				}
+
+				labels := withLabels(clusterLabels, "group", group, "topic", topic, "partition", strconv.Itoa(partition))
+				groupOffset.add(float64(offset.Offset), labels...)
+				groupLag.add(float64(offset.Lag), labels...)
+				groupTimeLag.add(float64(offset.TimeLag)/1000, labels...)
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	for _, f := range []*metricFamily{oldest, newest, available, replicas, isr, leader, groupOffset, groupLag, groupTimeLag} {
+		writeMetricFamily(buf, f)
+	}
+
+	w.Header().Set("Content-Type", prometheusTextContentType)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeMetricFamily writes the metric family as gauges in the Prometheus text format.
+func writeMetricFamily(buf *bytes.Buffer, f *metricFamily) {
+	lines := make([]string, 0, len(f.samples))
+	for _, s := range f.samples {
+		lines = append(lines, f.name+formatLabels(s.labels)+" "+strconv.FormatFloat(s.value, 'f', -1, 64))
+	}
+	sort.Strings(lines)
+
+	fmt.Fprintf(buf, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(buf, "# TYPE %s gauge\n", f.name)
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// formatLabels formats the label key value pairs.
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelValueReplacer.Replace(labels[i+1])+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
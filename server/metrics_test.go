@@ -0,0 +1,76 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msales/kage"
+	"github.com/msales/kage/server"
+	"github.com/msales/kage/store"
+	"github.com/msales/kage/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	bo := store.BrokerOffsets{
+		"test": []*store.BrokerOffset{{OldestOffset: 10, NewestOffset: 100, Timestamp: 0}, nil},
+	}
+	bm := store.BrokerMetadata{
+		"test": []*store.Metadata{{Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1}, Timestamp: 0}, nil},
+	}
+	co := store.ConsumerOffsets{
+		"foo\"bar": map[string][]*store.ConsumerOffset{
+			"test": {{Offset: 90, Lag: 10, TimeLag: 2500, Timestamp: 0}, nil},
+		},
+	}
+
+	store := new(mocks.MockStore)
+	store.On("BrokerOffsets").Return(bo)
+	store.On("BrokerMetadata").Return(bm)
+	store.On("ConsumerOffsets").Return(co)
+
+	app := &kage.Application{Store: store}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	want := `# HELP kage_topic_partition_oldest_offset The oldest available offset of a topic partition.
+# TYPE kage_topic_partition_oldest_offset gauge
+kage_topic_partition_oldest_offset{topic="test",partition="0"} 10
+# HELP kage_topic_partition_newest_offset The newest offset of a topic partition.
+# TYPE kage_topic_partition_newest_offset gauge
+kage_topic_partition_newest_offset{topic="test",partition="0"} 100
+# HELP kage_topic_partition_available_messages The number of messages available in a topic partition.
+# TYPE kage_topic_partition_available_messages gauge
+kage_topic_partition_available_messages{topic="test",partition="0"} 90
+# HELP kage_topic_partition_replicas The number of replicas of a topic partition.
+# TYPE kage_topic_partition_replicas gauge
+kage_topic_partition_replicas{topic="test",partition="0"} 2
+# HELP kage_topic_partition_in_sync_replicas The number of in-sync replicas of a topic partition.
+# TYPE kage_topic_partition_in_sync_replicas gauge
+kage_topic_partition_in_sync_replicas{topic="test",partition="0"} 1
+# HELP kage_topic_partition_leader The broker ID of the leader of a topic partition, -1 if there is no leader.
+# TYPE kage_topic_partition_leader gauge
+kage_topic_partition_leader{topic="test",partition="0"} 1
+# HELP kage_consumer_group_offset The committed offset of a consumer group on a topic partition.
+# TYPE kage_consumer_group_offset gauge
+kage_consumer_group_offset{group="foo\"bar",topic="test",partition="0"} 90
+# HELP kage_consumer_group_lag The lag of a consumer group on a topic partition.
+# TYPE kage_consumer_group_lag gauge
+kage_consumer_group_lag{group="foo\"bar",topic="test",partition="0"} 10
+# HELP kage_consumer_group_time_lag_seconds The estimated time lag in seconds of a consumer group on a topic partition.
+# TYPE kage_consumer_group_time_lag_seconds gauge
+kage_consumer_group_time_lag_seconds{group="foo\"bar",topic="test",partition="0"} 2.5
+`
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, want, rr.Body.String())
+}
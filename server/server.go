@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-zoo/bone"
 	"github.com/msales/kage"
+	"github.com/msales/kage/kafka"
 )
 
 // Server represents an http server.
@@ -23,14 +26,32 @@ func New(app *kage.Application) *Server {
 		mux:         bone.New(),
 	}
 
-	s.mux.GetFunc("/brokers", s.BrokersHandler)
-	s.mux.GetFunc("/brokers/health", s.BrokersHealthHandler)
-	s.mux.GetFunc("/metadata", s.MetadataHandler)
-	s.mux.GetFunc("/topics", s.TopicsHandler)
-	s.mux.GetFunc("/consumers", s.ConsumerGroupsHandler)
-	s.mux.GetFunc("/consumers/:group", s.ConsumerGroupHandler)
+	s.get("/brokers", s.BrokersHandler)
+	s.get("/brokers/health", s.BrokersHealthHandler)
+	s.get("/brokers/:id", s.BrokerHandler)
+	s.get("/brokers/:id/logdirs", s.BrokerLogDirsHandler)
+	s.get("/metadata", s.MetadataHandler)
+	s.get("/topics", s.TopicsHandler)
+	s.get("/topics/:topic", s.TopicHandler)
+	s.get("/topics/:topic/history", s.TopicHistoryHandler)
+	s.get("/topics/:topic/size", s.TopicSizeHandler)
+	s.get("/consumers", s.ConsumerGroupsHandler)
+	s.get("/consumers/:group", s.ConsumerGroupHandler)
+	s.get("/consumers/:group/status", s.ConsumerGroupStatusHandler)
+	s.get("/consumers/:group/members", s.ConsumerGroupMembersHandler)
+	s.get("/consumers/:group/history", s.ConsumerGroupHistoryHandler)
 
-	s.mux.GetFunc("/health", s.HealthHandler)
+	s.get("/cluster/health", s.ClusterHealthHandler)
+
+	s.get("/alerts", s.AlertsHandler)
+
+	s.get("/metrics", s.MetricsHandler)
+
+	s.post("/collect", s.CollectHandler)
+
+	s.get("/health", s.HealthHandler)
+
+	s.mux.GetFunc("/clusters", s.ClustersHandler)
 
 	return s
 }
@@ -42,26 +63,66 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 type brokerStatus struct {
-	ID        int32 `json:"id"`
-	Connected bool  `json:"connected"`
+	ID         int32      `json:"id"`
+	Connected  bool       `json:"connected"`
+	Address    string     `json:"address"`
+	Rack       string     `json:"rack"`
+	Controller bool       `json:"controller"`
+	Leaders    int        `json:"leaders"`
+	Replicas   int        `json:"replicas"`
+	LastSeen   *time.Time `json:"last_seen"`
 }
 
 // BrokersHandler handles requests for brokers status.
 func (s *Server) BrokersHandler(w http.ResponseWriter, r *http.Request) {
 	brokers := []brokerStatus{}
-	for _, b := range s.Monitor.Brokers() {
-		brokers = append(brokers, brokerStatus{
-			ID:        b.ID,
-			Connected: b.Connected,
-		})
+	for _, b := range s.cluster(r).Monitor.Brokers() {
+		brokers = append(brokers, newBrokerStatus(b))
 	}
 
 	s.writeJSON(w, brokers)
 }
 
+// BrokerHandler handles requests for a broker status.
+func (s *Server) BrokerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(bone.GetValue(r, "id"), 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	for _, b := range s.cluster(r).Monitor.Brokers() {
+		if b.ID == int32(id) {
+			s.writeJSON(w, newBrokerStatus(b))
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// newBrokerStatus creates a broker status from a broker.
+func newBrokerStatus(b kafka.Broker) brokerStatus {
+	status := brokerStatus{
+		ID:         b.ID,
+		Connected:  b.Connected,
+		Address:    b.Address,
+		Rack:       b.Rack,
+		Controller: b.Controller,
+		Leaders:    b.Leaders,
+		Replicas:   b.Replicas,
+	}
+	if !b.LastSeen.IsZero() {
+		lastSeen := b.LastSeen
+		status.LastSeen = &lastSeen
+	}
+
+	return status
+}
+
 // BrokersHealthHandler handles requests for brokers health.
 func (s *Server) BrokersHealthHandler(w http.ResponseWriter, r *http.Request) {
-	for _, b := range s.Monitor.Brokers() {
+	for _, b := range s.cluster(r).Monitor.Brokers() {
 		if !b.Connected {
 			w.WriteHeader(500)
 			return
@@ -69,9 +130,24 @@ func (s *Server) BrokersHealthHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// CollectHandler handles requests to collect the cluster state immediately.
+//
+// The response is written once the collected state has been applied to the store.
+func (s *Server) CollectHandler(w http.ResponseWriter, r *http.Request) {
+	sc := s.scope(r)
+	sc.Collect()
+
+	if err := sc.Flush(r.Context()); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // HealthHandler handles health requests.
 func (s *Server) HealthHandler(w http.ResponseWriter, r *http.Request) {
-	if !s.IsHealthy() {
+	if !s.scope(r).IsHealthy() {
 		w.WriteHeader(500)
 		return
 	}
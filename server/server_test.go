@@ -4,13 +4,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/msales/kage"
 	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/server"
+	"github.com/msales/kage/store"
 	"github.com/msales/kage/testutil"
 	"github.com/msales/kage/testutil/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestBrokersHandler(t *testing.T) {
@@ -22,18 +25,71 @@ func TestBrokersHandler(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	monitor := new(mocks.MockMonitor)
-	monitor.On("Brokers").Return([]kafka.Broker{{ID: 0, Connected: false}})
+	monitor.On("Brokers").Return([]kafka.Broker{
+		{ID: 0, Connected: false, Address: "127.0.0.1:9092"},
+		{ID: 1, Connected: true, Address: "127.0.0.2:9092", Rack: "a", Controller: true, Leaders: 2, Replicas: 4, LastSeen: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	})
 
 	app := &kage.Application{Monitor: monitor}
 
 	srv := server.New(app)
 	srv.ServeHTTP(rr, req)
 
-	want := "[{\"id\":0,\"connected\":false}]"
+	want := "[{\"id\":0,\"connected\":false,\"address\":\"127.0.0.1:9092\",\"rack\":\"\",\"controller\":false,\"leaders\":0,\"replicas\":0,\"last_seen\":null}," +
+		"{\"id\":1,\"connected\":true,\"address\":\"127.0.0.2:9092\",\"rack\":\"a\",\"controller\":true,\"leaders\":2,\"replicas\":4,\"last_seen\":\"2020-01-02T03:04:05Z\"}]"
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Equal(t, want, rr.Body.String())
 }
 
+func TestBrokerHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/brokers/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	monitor := new(mocks.MockMonitor)
+	monitor.On("Brokers").Return([]kafka.Broker{
+		{ID: 0, Connected: false},
+		{ID: 1, Connected: true, Address: "127.0.0.2:9092", Controller: true, Leaders: 2, Replicas: 4},
+	})
+
+	app := &kage.Application{Monitor: monitor}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	want := "{\"id\":1,\"connected\":true,\"address\":\"127.0.0.2:9092\",\"rack\":\"\",\"controller\":true,\"leaders\":2,\"replicas\":4,\"last_seen\":null}"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestBrokerHandler_NotFound(t *testing.T) {
+	tests := []string{"/brokers/2", "/brokers/foo"}
+
+	for _, path := range tests {
+		t.Run(path, func(t *testing.T) {
+			req, err := http.NewRequest("GET", path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			monitor := new(mocks.MockMonitor)
+			monitor.On("Brokers").Return([]kafka.Broker{{ID: 0, Connected: true}})
+
+			app := &kage.Application{Monitor: monitor}
+
+			srv := server.New(app)
+			srv.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+	}
+}
+
 func TestBrokersHealthHandler(t *testing.T) {
 	req, err := http.NewRequest("GET", "/brokers/health", nil)
 	if err != nil {
@@ -98,3 +154,71 @@ func TestHealthFail(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
 }
+
+func TestHealthFailOnOfflinePartitions(t *testing.T) {
+	req, err := http.NewRequest("GET", "/health", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	md := store.BrokerMetadata{
+		"test": {{Leader: -1, Replicas: []int32{1, 2}, Isr: []int32{}}},
+	}
+
+	s := new(mocks.MockStore)
+	s.On("BrokerMetadata").Return(md)
+	s.On("TopicConfigs").Return(store.TopicConfigs{})
+
+	monitor := new(mocks.MockMonitor)
+	monitor.On("IsHealthy").Return(true)
+
+	app := &kage.Application{
+		Store:                   s,
+		Monitor:                 monitor,
+		FailOnOfflinePartitions: true,
+	}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestCollectHandler(t *testing.T) {
+	req, err := http.NewRequest("POST", "/collect", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	memStore, err := store.New()
+	assert.NoError(t, err)
+	defer memStore.Close()
+
+	monitor := new(mocks.MockMonitor)
+	monitor.On("Collect").Return().Run(func(args mock.Arguments) {
+		memStore.Channel() <- &store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           0,
+			Oldest:              false,
+			Offset:              100,
+			Timestamp:           time.Now().Unix() * 1000,
+			TopicPartitionCount: 1,
+		}
+	})
+
+	app := &kage.Application{
+		Store:   memStore,
+		Monitor: monitor,
+	}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, int64(100), memStore.BrokerOffsets()["test"][0].NewestOffset)
+	monitor.AssertExpectations(t)
+}
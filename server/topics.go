@@ -2,24 +2,47 @@ package server
 
 import (
 	"net/http"
+
+	"github.com/go-zoo/bone"
 )
 
 type brokerTopics struct {
 	Topic          string            `json:"topic"`
 	TotalAvailable int64             `json:"total_available"`
+	ProduceRate    float64           `json:"produce_rate"`
 	Partitions     []brokerPartition `json:"partitions"`
 }
 
 type brokerPartition struct {
-	Partition int   `json:"partition"`
-	Oldest    int64 `json:"oldest"`
-	Newest    int64 `json:"newest"`
-	Available int64 `json:"available"`
+	Partition   int     `json:"partition"`
+	Oldest      int64   `json:"oldest"`
+	Newest      int64   `json:"newest"`
+	Available   int64   `json:"available"`
+	ProduceRate float64 `json:"produce_rate"`
+}
+
+type topicDetail struct {
+	Topic          string            `json:"topic"`
+	TotalAvailable int64             `json:"total_available"`
+	ProduceRate    float64           `json:"produce_rate"`
+	Configs        map[string]string `json:"configs"`
+	Partitions     []topicPartition  `json:"partitions"`
+}
+
+type topicPartition struct {
+	Partition   int     `json:"partition"`
+	Oldest      int64   `json:"oldest"`
+	Newest      int64   `json:"newest"`
+	Available   int64   `json:"available"`
+	ProduceRate float64 `json:"produce_rate"`
+	Leader      int32   `json:"leader"`
+	Replicas    []int32 `json:"replicas"`
+	Isr         []int32 `json:"isr"`
 }
 
 // TopicsHandler handles requests for topic offsets.
 func (s *Server) TopicsHandler(w http.ResponseWriter, r *http.Request) {
-	offsets := s.Store.BrokerOffsets()
+	offsets := s.cluster(r).Store.BrokerOffsets()
 
 	topics := []brokerTopics{}
 	for topic, partitions := range offsets {
@@ -34,13 +57,15 @@ func (s *Server) TopicsHandler(w http.ResponseWriter, r *http.Request) {
 			}
 
 			bp := brokerPartition{
-				Partition: i,
-				Oldest:    partition.OldestOffset,
-				Newest:    partition.NewestOffset,
-				Available: partition.NewestOffset - partition.OldestOffset,
+				Partition:   i,
+				Oldest:      partition.OldestOffset,
+				Newest:      partition.NewestOffset,
+				Available:   partition.NewestOffset - partition.OldestOffset,
+				ProduceRate: partition.ProduceRate,
 			}
 
 			bt.TotalAvailable += bp.Available
+			bt.ProduceRate += bp.ProduceRate
 			bt.Partitions[i] = bp
 		}
 
@@ -49,3 +74,53 @@ func (s *Server) TopicsHandler(w http.ResponseWriter, r *http.Request) {
 
 	s.writeJSON(w, topics)
 }
+
+// TopicHandler handles requests for the offsets, metadata and configuration of a topic.
+func (s *Server) TopicHandler(w http.ResponseWriter, r *http.Request) {
+	c := s.cluster(r)
+	topic := bone.GetValue(r, "topic")
+
+	offsets, hasOffsets := c.Store.BrokerOffsets()[topic]
+	metadata, hasMetadata := c.Store.BrokerMetadata()[topic]
+	if !hasOffsets && !hasMetadata {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	td := topicDetail{
+		Topic:   topic,
+		Configs: map[string]string{},
+	}
+	if config, ok := c.Store.TopicConfigs()[topic]; ok {
+		td.Configs = config.Configs
+	}
+
+	n := len(offsets)
+	if len(metadata) > n {
+		n = len(metadata)
+	}
+
+	td.Partitions = make([]topicPartition, n)
+	for i := range td.Partitions {
+		tp := topicPartition{Partition: i, Leader: -1}
+
+		if i < len(offsets) && offsets[i] != nil {
+			tp.Oldest = offsets[i].OldestOffset
+			tp.Newest = offsets[i].NewestOffset
+			tp.Available = offsets[i].NewestOffset - offsets[i].OldestOffset
+			tp.ProduceRate = offsets[i].ProduceRate
+		}
+
+		if i < len(metadata) && metadata[i] != nil {
+			tp.Leader = metadata[i].Leader
+			tp.Replicas = metadata[i].Replicas
+			tp.Isr = metadata[i].Isr
+		}
+
+		td.TotalAvailable += tp.Available
+		td.ProduceRate += tp.ProduceRate
+		td.Partitions[i] = tp
+	}
+
+	s.writeJSON(w, td)
+}
@@ -21,7 +21,7 @@ func TestTopicsHandler(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	bo := store.BrokerOffsets{
-		"test": []*store.BrokerOffset{{OldestOffset: 0, NewestOffset: 100, Timestamp: 0}},
+		"test": []*store.BrokerOffset{{OldestOffset: 0, NewestOffset: 100, Timestamp: 0, ProduceRate: 4}},
 	}
 
 	store := new(mocks.MockStore)
@@ -32,7 +32,60 @@ func TestTopicsHandler(t *testing.T) {
 	srv := server.New(app)
 	srv.ServeHTTP(rr, req)
 
-	want := "[{\"topic\":\"test\",\"total_available\":100,\"partitions\":[{\"partition\":0,\"oldest\":0,\"newest\":100,\"available\":100}]}]"
+	want := "[{\"topic\":\"test\",\"total_available\":100,\"produce_rate\":4,\"partitions\":[{\"partition\":0,\"oldest\":0,\"newest\":100,\"available\":100,\"produce_rate\":4}]}]"
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Equal(t, want, rr.Body.String())
 }
+
+func TestTopicHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/topics/test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	bo := store.BrokerOffsets{
+		"test": []*store.BrokerOffset{{OldestOffset: 0, NewestOffset: 100, Timestamp: 0, ProduceRate: 4}, nil},
+	}
+	bm := store.BrokerMetadata{
+		"test": []*store.Metadata{{Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1, 2}}, {Leader: 2, Replicas: []int32{2, 1}, Isr: []int32{2}}},
+	}
+	tc := store.TopicConfigs{
+		"test": {Configs: map[string]string{"retention.ms": "604800000", "cleanup.policy": "delete"}},
+	}
+
+	s := new(mocks.MockStore)
+	s.On("BrokerOffsets").Return(bo)
+	s.On("BrokerMetadata").Return(bm)
+	s.On("TopicConfigs").Return(tc)
+
+	app := &kage.Application{Store: s}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	want := "{\"topic\":\"test\",\"total_available\":100,\"produce_rate\":4,\"configs\":{\"cleanup.policy\":\"delete\",\"retention.ms\":\"604800000\"},\"partitions\":[{\"partition\":0,\"oldest\":0,\"newest\":100,\"available\":100,\"produce_rate\":4,\"leader\":1,\"replicas\":[1,2],\"isr\":[1,2]},{\"partition\":1,\"oldest\":0,\"newest\":0,\"available\":0,\"produce_rate\":0,\"leader\":2,\"replicas\":[2,1],\"isr\":[2]}]}"
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestTopicHandler_NotFound(t *testing.T) {
+	req, err := http.NewRequest("GET", "/topics/none", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	s := new(mocks.MockStore)
+	s.On("BrokerOffsets").Return(store.BrokerOffsets{})
+	s.On("BrokerMetadata").Return(store.BrokerMetadata{})
+
+	app := &kage.Application{Store: s}
+
+	srv := server.New(app)
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
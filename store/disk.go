@@ -0,0 +1,484 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket names in the disk store database.
+var (
+	brokerOffsetsBucket   = []byte("broker_offsets")
+	consumerOffsetsBucket = []byte("consumer_offsets")
+	metadataBucket        = []byte("metadata")
+	consumerGroupsBucket  = []byte("consumer_groups")
+	topicConfigsBucket    = []byte("topic_configs")
+	logDirsBucket         = []byte("log_dirs")
+	brokerHistoryBucket   = []byte("broker_history")
+	consumerHistoryBucket = []byte("consumer_history")
+)
+
+// keySeparator separates the parts of a database key.
+const keySeparator = "\x00"
+
+// DiskStore represents an on disk data store.
+//
+// The state is kept in memory and written through to a bbolt database
+// together with a bounded history, which is replayed when the store is opened.
+// The downsampled history is snapshotted on every cleanup and when the store is closed.
+type DiskStore struct {
+	mem *MemoryStore
+	db  *bolt.DB
+
+	cleanupTicker *time.Ticker
+	shutdown      chan struct{}
+
+	stateCh chan interface{}
+}
+
+// NewDiskStore creates and returns a new DiskStore persisted in the given file.
+func NewDiskStore(path string, opts ...StoreFunc) (*DiskStore, error) {
+	o := newOptions(opts)
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("store: cannot open database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{brokerOffsetsBucket, consumerOffsetsBucket, metadataBucket, consumerGroupsBucket, topicConfigsBucket, logDirsBucket, brokerHistoryBucket, consumerHistoryBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("store: cannot create buckets: %w", err)
+	}
+
+	d := &DiskStore{
+		mem:      newMemoryStore(o),
+		db:       db,
+		shutdown: make(chan struct{}),
+		stateCh:  make(chan interface{}, 10000),
+	}
+
+	if err := d.load(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	d.CleanConsumerOffsets()
+
+	// Start the offset reader
+	go readStates(d.stateCh, d.shutdown, d.SetState)
+
+	// Start cleanup task
+	d.cleanupTicker = time.NewTicker(o.cleanupInterval)
+	go func() {
+		for range d.cleanupTicker.C {
+			d.CleanConsumerOffsets()
+			_ = d.persistHistory()
+		}
+	}()
+
+	return d, nil
+}
+
+// SetState adds a state into the store.
+func (d *DiskStore) SetState(v interface{}) error {
+	if err := d.mem.SetState(v); err != nil {
+		return err
+	}
+
+	return d.persist(v)
+}
+
+// BrokerOffsets returns a snapshot of the current broker offsets.
+func (d *DiskStore) BrokerOffsets() BrokerOffsets {
+	return d.mem.BrokerOffsets()
+}
+
+// ConsumerOffsets returns a snapshot of the current consumer group offsets.
+func (d *DiskStore) ConsumerOffsets() ConsumerOffsets {
+	return d.mem.ConsumerOffsets()
+}
+
+// BrokerMetadata returns a snapshot of the current broker metadata.
+func (d *DiskStore) BrokerMetadata() BrokerMetadata {
+	return d.mem.BrokerMetadata()
+}
+
+// ConsumerGroups returns a snapshot of the current consumer group descriptions.
+func (d *DiskStore) ConsumerGroups() ConsumerGroups {
+	return d.mem.ConsumerGroups()
+}
+
+// TopicConfigs returns a snapshot of the current topic configurations.
+func (d *DiskStore) TopicConfigs() TopicConfigs {
+	return d.mem.TopicConfigs()
+}
+
+// BrokerLogDirs returns a snapshot of the current broker log directories.
+func (d *DiskStore) BrokerLogDirs() BrokerLogDirs {
+	return d.mem.BrokerLogDirs()
+}
+
+// BrokerHistory returns the offset history of a topic, indexed by partition.
+func (d *DiskStore) BrokerHistory(topic string, r HistoryRange) [][]HistoryPoint {
+	return d.mem.BrokerHistory(topic, r)
+}
+
+// ConsumerHistory returns the offset and lag history of a consumer group on a topic, indexed by partition.
+func (d *DiskStore) ConsumerHistory(group, topic string, r HistoryRange) [][]HistoryPoint {
+	return d.mem.ConsumerHistory(group, topic, r)
+}
+
+// CleanConsumerOffsets cleans old offsets and consumer groups from the DiskStore.
+func (d *DiskStore) CleanConsumerOffsets() {
+	d.mem.CleanConsumerOffsets()
+
+	offsets := d.mem.ConsumerOffsets()
+	groups := d.mem.ConsumerGroups()
+	_ = d.db.Update(func(tx *bolt.Tx) error {
+		err := deleteKeys(tx.Bucket(consumerOffsetsBucket), func(parts []string) bool {
+			if len(parts) != 4 {
+				return true
+			}
+
+			_, ok := offsets[parts[0]][parts[1]]
+			return !ok
+		})
+		if err != nil {
+			return err
+		}
+
+		return deleteKeys(tx.Bucket(consumerGroupsBucket), func(parts []string) bool {
+			_, ok := groups[parts[0]]
+			return !ok
+		})
+	})
+}
+
+// deleteKeys deletes the keys from the bucket matching the stale function.
+func deleteKeys(b *bolt.Bucket, staleFn func(parts []string) bool) error {
+	var stale [][]byte
+	err := b.ForEach(func(k, _ []byte) error {
+		if staleFn(strings.Split(string(k), keySeparator)) {
+			stale = append(stale, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range stale {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Channel get the offset channel.
+func (d *DiskStore) Channel() chan interface{} {
+	return d.stateCh
+}
+
+// Close gracefully stops the DiskStore.
+func (d *DiskStore) Close() {
+	d.cleanupTicker.Stop()
+	close(d.shutdown)
+
+	_ = d.persistHistory()
+	_ = d.db.Close()
+}
+
+// persist appends the state to its bounded history in the database.
+//
+// Each state is stored under the key of its series followed by its zero padded
+// timestamp, so the samples of a series are ordered by time and the oldest are
+// trimmed without rewriting the others.
+func (d *DiskStore) persist(v interface{}) error {
+	var (
+		bucket []byte
+		key    string
+		limit  int
+	)
+
+	switch val := v.(type) {
+	case *BrokerPartitionOffset:
+		bucket = brokerOffsetsBucket
+		key = dbKey(val.Topic, strconv.Itoa(int(val.Partition)), strconv.FormatBool(val.Oldest))
+		limit = 1
+		if !val.Oldest {
+			limit = brokerHistorySize
+		}
+
+	case *ConsumerPartitionOffset:
+		bucket = consumerOffsetsBucket
+		key = dbKey(val.Group, val.Topic, strconv.Itoa(int(val.Partition)))
+		limit = statusWindowSize
+
+	case *BrokerPartitionMetadata:
+		bucket = metadataBucket
+		key = dbKey(val.Topic, strconv.Itoa(int(val.Partition)))
+		limit = 1
+
+	case *ConsumerGroupDescription:
+		bucket = consumerGroupsBucket
+		key = dbKey(val.Group)
+		limit = 1
+
+	case *TopicConfigDescription:
+		bucket = topicConfigsBucket
+		key = dbKey(val.Topic)
+		limit = 1
+
+	case *BrokerLogDirsDescription:
+		bucket = logDirsBucket
+		key = dbKey(strconv.Itoa(int(val.Broker)))
+		limit = 1
+
+	default:
+		return errors.New("store: unknown state object")
+	}
+
+	record, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return d.db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+
+		sampleKey := dbKey(key, fmt.Sprintf("%019d", stateTimestamp(v)))
+		if err := b.Put([]byte(sampleKey), record); err != nil {
+			return err
+		}
+
+		return trimSeries(b, []byte(key+keySeparator), limit)
+	})
+}
+
+// trimSeries deletes the oldest samples of the series with the key prefix beyond the limit.
+func trimSeries(b *bolt.Bucket, prefix []byte, limit int) error {
+	var keys [][]byte
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+
+	for i := 0; i < len(keys)-limit; i++ {
+		if err := b.Delete(keys[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persistHistory replaces the persisted history series with a snapshot of the memory store.
+func (d *DiskStore) persistHistory() error {
+	broker, consumer := d.mem.historySnapshot()
+
+	brokerRecords := make(map[string][]byte, len(broker))
+	for key, tiers := range broker {
+		record, err := json.Marshal(tiers)
+		if err != nil {
+			return err
+		}
+		brokerRecords[dbKey(key.topic, strconv.Itoa(int(key.partition)))] = record
+	}
+
+	consumerRecords := make(map[string][]byte, len(consumer))
+	for key, tiers := range consumer {
+		record, err := json.Marshal(tiers)
+		if err != nil {
+			return err
+		}
+		consumerRecords[dbKey(key.group, key.topic, strconv.Itoa(int(key.partition)))] = record
+	}
+
+	return d.db.Update(func(tx *bolt.Tx) error {
+		if err := replaceBucket(tx, brokerHistoryBucket, brokerRecords); err != nil {
+			return err
+		}
+
+		return replaceBucket(tx, consumerHistoryBucket, consumerRecords)
+	})
+}
+
+// replaceBucket replaces the contents of the bucket with the records.
+func replaceBucket(tx *bolt.Tx, name []byte, records map[string][]byte) error {
+	if err := tx.DeleteBucket(name); err != nil {
+		return err
+	}
+
+	b, err := tx.CreateBucket(name)
+	if err != nil {
+		return err
+	}
+
+	for k, record := range records {
+		if err := b.Put([]byte(k), record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadHistory reads the persisted history series.
+func loadHistory(tx *bolt.Tx) (map[topicPartitionKey][][]HistoryPoint, map[consumerKey][][]HistoryPoint, error) {
+	broker := map[topicPartitionKey][][]HistoryPoint{}
+	err := tx.Bucket(brokerHistoryBucket).ForEach(func(k, record []byte) error {
+		parts := strings.Split(string(k), keySeparator)
+		if len(parts) != 2 {
+			return nil
+		}
+		partition, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil
+		}
+
+		var tiers [][]HistoryPoint
+		if err := json.Unmarshal(record, &tiers); err != nil {
+			return err
+		}
+		broker[topicPartitionKey{topic: parts[0], partition: int32(partition)}] = tiers
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consumer := map[consumerKey][][]HistoryPoint{}
+	err = tx.Bucket(consumerHistoryBucket).ForEach(func(k, record []byte) error {
+		parts := strings.Split(string(k), keySeparator)
+		if len(parts) != 3 {
+			return nil
+		}
+		partition, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil
+		}
+
+		var tiers [][]HistoryPoint
+		if err := json.Unmarshal(record, &tiers); err != nil {
+			return err
+		}
+		consumer[consumerKey{group: parts[0], topic: parts[1], partition: int32(partition)}] = tiers
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return broker, consumer, nil
+}
+
+// persistedState represents a state read from the database.
+type persistedState struct {
+	state     interface{}
+	order     int
+	timestamp int64
+}
+
+// load restores the persisted history and replays the persisted states into memory.
+//
+// States older than the restored history are not added to it again.
+func (d *DiskStore) load() error {
+	var (
+		states          []persistedState
+		brokerHistory   map[topicPartitionKey][][]HistoryPoint
+		consumerHistory map[consumerKey][][]HistoryPoint
+	)
+
+	err := d.db.View(func(tx *bolt.Tx) error {
+		var err error
+		brokerHistory, consumerHistory, err = loadHistory(tx)
+		if err != nil {
+			return err
+		}
+
+		decoders := []struct {
+			bucket []byte
+			newFn  func() interface{}
+		}{
+			{bucket: metadataBucket, newFn: func() interface{} { return &BrokerPartitionMetadata{} }},
+			{bucket: brokerOffsetsBucket, newFn: func() interface{} { return &BrokerPartitionOffset{} }},
+			{bucket: consumerOffsetsBucket, newFn: func() interface{} { return &ConsumerPartitionOffset{} }},
+			{bucket: consumerGroupsBucket, newFn: func() interface{} { return &ConsumerGroupDescription{} }},
+			{bucket: topicConfigsBucket, newFn: func() interface{} { return &TopicConfigDescription{} }},
+			{bucket: logDirsBucket, newFn: func() interface{} { return &BrokerLogDirsDescription{} }},
+		}
+
+		for order, dec := range decoders {
+			err := tx.Bucket(dec.bucket).ForEach(func(_, record []byte) error {
+				v := dec.newFn()
+				if err := json.Unmarshal(record, v); err != nil {
+					return err
+				}
+
+				states = append(states, persistedState{state: v, order: order, timestamp: stateTimestamp(v)})
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("store: cannot load database: %w", err)
+	}
+
+	// Replay in time order, with broker states before the consumer states that depend on them.
+	sort.SliceStable(states, func(i, j int) bool {
+		if states[i].timestamp != states[j].timestamp {
+			return states[i].timestamp < states[j].timestamp
+		}
+		return states[i].order < states[j].order
+	})
+
+	d.mem.restoreHistory(brokerHistory, consumerHistory)
+	for _, s := range states {
+		_ = d.mem.SetState(s.state)
+	}
+	d.mem.pruneHistory()
+
+	return nil
+}
+
+// stateTimestamp returns the timestamp of a state.
+func stateTimestamp(v interface{}) int64 {
+	switch val := v.(type) {
+	case *BrokerPartitionOffset:
+		return val.Timestamp
+	case *ConsumerPartitionOffset:
+		return val.Timestamp
+	case *BrokerPartitionMetadata:
+		return val.Timestamp
+	case *ConsumerGroupDescription:
+		return val.Timestamp
+	case *TopicConfigDescription:
+		return val.Timestamp
+	case *BrokerLogDirsDescription:
+		return val.Timestamp
+	default:
+		return 0
+	}
+}
+
+// dbKey creates a database key from its parts.
+func dbKey(parts ...string) string {
+	return strings.Join(parts, keySeparator)
+}
@@ -0,0 +1,360 @@
+package store_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/msales/kage/store"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newDiskStore(t *testing.T) testStore {
+	diskStore, err := store.NewDiskStore(filepath.Join(t.TempDir(), "kage.db"))
+	assert.NoError(t, err)
+
+	t.Cleanup(diskStore.Close)
+
+	return diskStore
+}
+
+func TestNewDiskStore_InvalidPath(t *testing.T) {
+	_, err := store.NewDiskStore(filepath.Join(t.TempDir(), "missing", "kage.db"))
+
+	assert.Error(t, err)
+}
+
+func TestDiskStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kage.db")
+
+	diskStore, err := store.NewDiskStore(path)
+	assert.NoError(t, err)
+
+	ts := time.Now().Unix() * 1000
+	diskStore.SetState(&store.BrokerPartitionMetadata{
+		Topic:               "test",
+		Partition:           0,
+		TopicPartitionCount: 1,
+		Leader:              100,
+		Replicas:            []int32{100, 101},
+		Isr:                 []int32{100},
+		Timestamp:           ts,
+	})
+	diskStore.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              true,
+		Offset:              10,
+		Timestamp:           ts,
+		TopicPartitionCount: 1,
+	})
+	for i, offset := range []int64{1000, 2000} {
+		diskStore.SetState(&store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           0,
+			Oldest:              false,
+			Offset:              offset,
+			Timestamp:           ts + int64(i)*10000,
+			TopicPartitionCount: 1,
+		})
+		diskStore.SetState(&store.ConsumerPartitionOffset{
+			Group:     "foo",
+			Topic:     "test",
+			Partition: 0,
+			Offset:    500,
+			Timestamp: ts + int64(i)*10000 + 1,
+		})
+	}
+	diskStore.SetState(&store.ConsumerGroupDescription{
+		Group:        "foo",
+		State:        "Stable",
+		ProtocolType: "consumer",
+		Members:      []store.ConsumerGroupMember{{MemberID: "consumer-1-abc", Assignment: map[string][]int32{"test": {0}}}},
+		Timestamp:    ts,
+	})
+	diskStore.SetState(&store.TopicConfigDescription{
+		Topic:     "test",
+		Configs:   map[string]string{"min.insync.replicas": "2"},
+		Timestamp: ts,
+	})
+	diskStore.SetState(&store.BrokerLogDirsDescription{
+		Broker:    100,
+		LogDirs:   []store.LogDir{{Path: "/data", Replicas: []store.LogDirReplica{{Topic: "test", Partition: 0, Size: 1024}}}},
+		Timestamp: ts,
+	})
+	want := diskStore.ConsumerOffsets()
+	diskStore.Close()
+
+	diskStore, err = store.NewDiskStore(path)
+	assert.NoError(t, err)
+	defer diskStore.Close()
+
+	brokerOffsets := diskStore.BrokerOffsets()
+	assert.Equal(t, int64(10), brokerOffsets["test"][0].OldestOffset)
+	assert.Equal(t, int64(2000), brokerOffsets["test"][0].NewestOffset)
+
+	metadata := diskStore.BrokerMetadata()
+	assert.Equal(t, int32(100), metadata["test"][0].Leader)
+	assert.Equal(t, []int32{100}, metadata["test"][0].Isr)
+
+	consumerOffsets := diskStore.ConsumerOffsets()
+	assert.Equal(t, want, consumerOffsets)
+	assert.Equal(t, int64(1500), consumerOffsets["foo"]["test"][0].Lag)
+	assert.Equal(t, store.ConsumerStatusStalled, consumerOffsets["foo"]["test"][0].Status)
+
+	groups := diskStore.ConsumerGroups()
+	assert.Equal(t, "Stable", groups["foo"].State)
+	assert.Equal(t, []int32{0}, groups["foo"].Members[0].Assignment["test"])
+
+	configs := diskStore.TopicConfigs()
+	assert.Equal(t, "2", configs["test"].Configs["min.insync.replicas"])
+
+	logDirs := diskStore.BrokerLogDirs()
+	assert.Equal(t, int64(1024), logDirs[100].LogDirs[0].Size())
+}
+
+func TestDiskStore_TrimsPersistedHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kage.db")
+
+	diskStore, err := store.NewDiskStore(path)
+	assert.NoError(t, err)
+
+	ts := time.Now().Unix() * 1000
+	for i := 0; i < 130; i++ {
+		diskStore.SetState(&store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           0,
+			Oldest:              false,
+			Offset:              int64(i) * 100,
+			Timestamp:           ts + int64(i)*1000,
+			TopicPartitionCount: 1,
+		})
+		diskStore.SetState(&store.ConsumerPartitionOffset{
+			Group:     "foo",
+			Topic:     "test",
+			Partition: 0,
+			Offset:    int64(i) * 50,
+			Timestamp: ts + int64(i)*1000 + 1,
+		})
+	}
+	diskStore.Close()
+
+	db, err := bolt.Open(path, 0600, nil)
+	assert.NoError(t, err)
+	_ = db.View(func(tx *bolt.Tx) error {
+		assert.Equal(t, 120, tx.Bucket([]byte("broker_offsets")).Stats().KeyN)
+		assert.Equal(t, 10, tx.Bucket([]byte("consumer_offsets")).Stats().KeyN)
+		return nil
+	})
+	_ = db.Close()
+
+	diskStore, err = store.NewDiskStore(path)
+	assert.NoError(t, err)
+	defer diskStore.Close()
+
+	assert.Equal(t, int64(12900), diskStore.BrokerOffsets()["test"][0].NewestOffset)
+	assert.Equal(t, int64(6450), diskStore.ConsumerOffsets()["foo"]["test"][0].Offset)
+}
+
+func TestDiskStore_PersistsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kage.db")
+
+	diskStore, err := store.NewDiskStore(path)
+	assert.NoError(t, err)
+
+	// More samples than are persisted, so the older ones are only kept downsampled.
+	ts := time.Now().Add(-5*time.Hour).Unix() * 1000
+	for i := 0; i < 200; i++ {
+		diskStore.SetState(&store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           0,
+			Oldest:              false,
+			Offset:              int64(i) * 100,
+			Timestamp:           ts + int64(i)*60000,
+			TopicPartitionCount: 1,
+		})
+		diskStore.SetState(&store.ConsumerPartitionOffset{
+			Group:     "foo",
+			Topic:     "test",
+			Partition: 0,
+			Offset:    int64(i) * 50,
+			Timestamp: ts + int64(i)*60000 + 1,
+		})
+	}
+	brokerHistory := diskStore.BrokerHistory("test", store.HistoryRange{})
+	consumerHistory := diskStore.ConsumerHistory("foo", "test", store.HistoryRange{})
+	diskStore.Close()
+
+	diskStore, err = store.NewDiskStore(path)
+	assert.NoError(t, err)
+	defer diskStore.Close()
+
+	assert.Greater(t, len(brokerHistory[0]), 120)
+	assert.Equal(t, brokerHistory, diskStore.BrokerHistory("test", store.HistoryRange{}))
+	assert.Equal(t, consumerHistory, diskStore.ConsumerHistory("foo", "test", store.HistoryRange{}))
+
+	// New samples are added to the restored history.
+	diskStore.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              20000,
+		Timestamp:           ts + 200*60000,
+		TopicPartitionCount: 1,
+	})
+
+	history := diskStore.BrokerHistory("test", store.HistoryRange{})
+	assert.Equal(t, int64(20000), history[0][len(history[0])-1].Offset)
+}
+
+func TestDiskStore_CleanConsumerOffsetsPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kage.db")
+
+	diskStore, err := store.NewDiskStore(path)
+	assert.NoError(t, err)
+
+	diskStore.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix() * 1000,
+		TopicPartitionCount: 1,
+	})
+	diskStore.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: time.Now().Unix()*1000 - (25 * int64(time.Hour.Seconds()) * 1000),
+	})
+	diskStore.CleanConsumerOffsets()
+	diskStore.Close()
+
+	diskStore, err = store.NewDiskStore(path)
+	assert.NoError(t, err)
+	defer diskStore.Close()
+
+	assert.Len(t, diskStore.ConsumerOffsets(), 0)
+}
+
+func TestDiskStore_SetState(t *testing.T) {
+	testStoreSetState(t, newDiskStore)
+}
+
+func TestDiskStore_BrokerOffsets(t *testing.T) {
+	testStoreBrokerOffsets(t, newDiskStore)
+}
+
+func TestDiskStore_BrokerOffsetsMissingParition(t *testing.T) {
+	testStoreBrokerOffsetsMissingParition(t, newDiskStore)
+}
+
+func TestDiskStore_BrokerOffsetsIncreasePartitions(t *testing.T) {
+	testStoreBrokerOffsetsIncreasePartitions(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsets(t *testing.T) {
+	testStoreConsumerOffsets(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsZeroOffset(t *testing.T) {
+	testStoreConsumerOffsetsZeroOffset(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsMissingPartition(t *testing.T) {
+	testStoreConsumerOffsetsMissingPartition(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsNoBrokerPartition(t *testing.T) {
+	testStoreConsumerOffsetsNoBrokerPartition(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsBeforeBrokerOffsets(t *testing.T) {
+	testStoreConsumerOffsetsBeforeBrokerOffsets(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsDeleted(t *testing.T) {
+	testStoreConsumerOffsetsDeleted(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsDeletedBeforeCommit(t *testing.T) {
+	testStoreConsumerOffsetsDeletedBeforeCommit(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsBrokerPartitionNil(t *testing.T) {
+	testStoreConsumerOffsetsBrokerPartitionNil(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsIncreasePartitions(t *testing.T) {
+	testStoreConsumerOffsetsIncreasePartitions(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsIncreasePartitionsBeforeBroker(t *testing.T) {
+	testStoreConsumerOffsetsIncreasePartitionsBeforeBroker(t, newDiskStore)
+}
+
+func TestDiskStore_BrokerMetadata(t *testing.T) {
+	testStoreBrokerMetadata(t, newDiskStore)
+}
+
+func TestDiskStore_BrokerMetadataMissingPartition(t *testing.T) {
+	testStoreBrokerMetadataMissingPartition(t, newDiskStore)
+}
+
+func TestDiskStore_BrokerMetadataIncreasePartitions(t *testing.T) {
+	testStoreBrokerMetadataIncreasePartitions(t, newDiskStore)
+}
+
+func TestDiskStore_CleanConsumerOffsets(t *testing.T) {
+	testStoreCleanConsumerOffsets(t, newDiskStore)
+}
+
+func TestDiskStore_CleanConsumerOffsetsMissingPartition(t *testing.T) {
+	testStoreCleanConsumerOffsetsMissingPartition(t, newDiskStore)
+}
+
+func TestDiskStore_CleanConsumerOffsetsBeforeBrokerOffsets(t *testing.T) {
+	testStoreCleanConsumerOffsetsBeforeBrokerOffsets(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsStatus(t *testing.T) {
+	testStoreConsumerOffsetsStatus(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsTimeLag(t *testing.T) {
+	testStoreConsumerOffsetsTimeLag(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerOffsetsRetention(t *testing.T) {
+	testStoreConsumerOffsetsRetention(t, newDiskStore)
+}
+
+func TestDiskStore_Rates(t *testing.T) {
+	testStoreRates(t, newDiskStore)
+}
+
+func TestDiskStore_History(t *testing.T) {
+	testStoreHistory(t, newDiskStore)
+}
+
+func TestDiskStore_ConsumerGroups(t *testing.T) {
+	testStoreConsumerGroups(t, newDiskStore)
+}
+
+func TestDiskStore_TopicConfigs(t *testing.T) {
+	testStoreTopicConfigs(t, newDiskStore)
+}
+
+func TestDiskStore_BrokerLogDirs(t *testing.T) {
+	testStoreBrokerLogDirs(t, newDiskStore)
+}
+
+func TestDiskStore_CleanConsumerGroups(t *testing.T) {
+	testStoreCleanConsumerGroups(t, newDiskStore)
+}
+
+func TestDiskStore_Flush(t *testing.T) {
+	testStoreFlush(t, newDiskStore)
+}
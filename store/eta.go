@@ -0,0 +1,47 @@
+package store
+
+// DrainRate calculates the rate in messages per second at which the lag decreases
+// over a window of samples ordered from oldest to newest. The rate is negative
+// when the lag grows.
+func DrainRate(samples []LagSample) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	if last.Timestamp <= first.Timestamp {
+		return 0
+	}
+
+	return float64(first.Lag-last.Lag) * 1000 / float64(last.Timestamp-first.Timestamp)
+}
+
+// EstimateCatchUp estimates the time in seconds until the consumer partitions of a
+// topic have no lag, from the total lag and drain rate of the partitions.
+//
+// It returns false when the lag is not decreasing, in which case the consumer
+// never catches up at the current rates.
+func EstimateCatchUp(partitions []*ConsumerOffset) (float64, bool) {
+	var (
+		lag  int64
+		rate float64
+	)
+	for _, offset := range partitions {
+		if offset == nil {
+			continue
+		}
+
+		lag += offset.Lag
+		rate += offset.DrainRate
+	}
+
+	if lag == 0 {
+		return 0, true
+	}
+
+	if rate <= 0 {
+		return 0, false
+	}
+
+	return float64(lag) / rate, true
+}
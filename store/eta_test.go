@@ -0,0 +1,51 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/msales/kage/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []store.LagSample
+		want    float64
+	}{
+		{name: "no samples", samples: nil, want: 0},
+		{name: "single sample", samples: []store.LagSample{{Lag: 100, Timestamp: 1000}}, want: 0},
+		{name: "decreasing", samples: []store.LagSample{{Lag: 1000, Timestamp: 0}, {Lag: 800, Timestamp: 5000}, {Lag: 500, Timestamp: 10000}}, want: 50},
+		{name: "growing", samples: []store.LagSample{{Lag: 500, Timestamp: 0}, {Lag: 1000, Timestamp: 10000}}, want: -50},
+		{name: "same time", samples: []store.LagSample{{Lag: 500, Timestamp: 1000}, {Lag: 100, Timestamp: 1000}}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, store.DrainRate(tt.samples))
+		})
+	}
+}
+
+func TestEstimateCatchUp(t *testing.T) {
+	tests := []struct {
+		name       string
+		partitions []*store.ConsumerOffset
+		wantETA    float64
+		wantOK     bool
+	}{
+		{name: "no lag", partitions: []*store.ConsumerOffset{{Lag: 0, DrainRate: -10}, nil}, wantETA: 0, wantOK: true},
+		{name: "draining", partitions: []*store.ConsumerOffset{{Lag: 1000, DrainRate: 15}, {Lag: 500, DrainRate: 0}, nil}, wantETA: 100, wantOK: true},
+		{name: "growing", partitions: []*store.ConsumerOffset{{Lag: 1000, DrainRate: 10}, {Lag: 500, DrainRate: -20}}, wantETA: 0, wantOK: false},
+		{name: "stalled", partitions: []*store.ConsumerOffset{{Lag: 1000}}, wantETA: 0, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eta, ok := store.EstimateCatchUp(tt.partitions)
+
+			assert.Equal(t, tt.wantETA, eta)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
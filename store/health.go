@@ -0,0 +1,81 @@
+package store
+
+import "strconv"
+
+// ClusterHealth represents the partition health of a cluster.
+type ClusterHealth struct {
+	UnderReplicated    int
+	UnderMinIsr        int
+	Offline            int
+	NonPreferredLeader int
+	Topics             map[string]*TopicHealth
+}
+
+// TopicHealth represents the partition health of a topic.
+type TopicHealth struct {
+	Partitions         int
+	UnderReplicated    []int32
+	UnderMinIsr        []int32
+	Offline            []int32
+	NonPreferredLeader []int32
+}
+
+// EvaluateClusterHealth computes the partition health from the broker metadata.
+//
+// Partitions are only checked against min.insync.replicas when the
+// topic configuration is known.
+func EvaluateClusterHealth(md BrokerMetadata, configs TopicConfigs) ClusterHealth {
+	h := ClusterHealth{Topics: make(map[string]*TopicHealth, len(md))}
+
+	for topic, partitions := range md {
+		minIsr := minInsyncReplicas(configs[topic])
+
+		th := &TopicHealth{}
+		for partition, metadata := range partitions {
+			if metadata == nil {
+				continue
+			}
+			th.Partitions++
+
+			p := int32(partition)
+			if len(metadata.Isr) < len(metadata.Replicas) {
+				th.UnderReplicated = append(th.UnderReplicated, p)
+			}
+
+			if minIsr > 0 && len(metadata.Isr) < minIsr {
+				th.UnderMinIsr = append(th.UnderMinIsr, p)
+			}
+
+			if metadata.Leader == -1 {
+				th.Offline = append(th.Offline, p)
+				continue
+			}
+
+			if len(metadata.Replicas) > 0 && metadata.Leader != metadata.Replicas[0] {
+				th.NonPreferredLeader = append(th.NonPreferredLeader, p)
+			}
+		}
+
+		h.UnderReplicated += len(th.UnderReplicated)
+		h.UnderMinIsr += len(th.UnderMinIsr)
+		h.Offline += len(th.Offline)
+		h.NonPreferredLeader += len(th.NonPreferredLeader)
+		h.Topics[topic] = th
+	}
+
+	return h
+}
+
+// minInsyncReplicas returns the min.insync.replicas of a topic, or 0 if unknown.
+func minInsyncReplicas(config *TopicConfig) int {
+	if config == nil {
+		return 0
+	}
+
+	v, err := strconv.Atoi(config.Configs[ConfigMinInsyncReplicas])
+	if err != nil {
+		return 0
+	}
+
+	return v
+}
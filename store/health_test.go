@@ -0,0 +1,56 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/msales/kage/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateClusterHealth(t *testing.T) {
+	md := store.BrokerMetadata{
+		"foo": {
+			{Leader: 1, Replicas: []int32{1, 2, 3}, Isr: []int32{1, 2, 3}},
+			{Leader: 2, Replicas: []int32{1, 2, 3}, Isr: []int32{2, 3}},
+			{Leader: -1, Replicas: []int32{1, 2, 3}, Isr: []int32{}},
+			nil,
+		},
+		"bar": {
+			{Leader: 2, Replicas: []int32{2, 3}, Isr: []int32{2}},
+		},
+	}
+	configs := store.TopicConfigs{
+		"foo": {Configs: map[string]string{"min.insync.replicas": "3"}},
+	}
+
+	h := store.EvaluateClusterHealth(md, configs)
+
+	assert.Equal(t, 3, h.UnderReplicated)
+	assert.Equal(t, 2, h.UnderMinIsr)
+	assert.Equal(t, 1, h.Offline)
+	assert.Equal(t, 1, h.NonPreferredLeader)
+	assert.Equal(t, &store.TopicHealth{
+		Partitions:         3,
+		UnderReplicated:    []int32{1, 2},
+		UnderMinIsr:        []int32{1, 2},
+		Offline:            []int32{2},
+		NonPreferredLeader: []int32{1},
+	}, h.Topics["foo"])
+	assert.Equal(t, &store.TopicHealth{
+		Partitions:      1,
+		UnderReplicated: []int32{0},
+	}, h.Topics["bar"])
+}
+
+func TestEvaluateClusterHealth_InvalidMinIsr(t *testing.T) {
+	md := store.BrokerMetadata{
+		"foo": {{Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1}}},
+	}
+	configs := store.TopicConfigs{
+		"foo": {Configs: map[string]string{"min.insync.replicas": "invalid"}},
+	}
+
+	h := store.EvaluateClusterHealth(md, configs)
+
+	assert.Equal(t, 0, h.UnderMinIsr)
+}
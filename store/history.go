@@ -0,0 +1,180 @@
+package store
+
+import "time"
+
+// historyTier represents a resolution at which history points are kept.
+type historyTier struct {
+	resolution int64 // The bucket size in milliseconds, zero keeps the raw points.
+	size       int
+}
+
+// historyTiers are the resolutions of the history, from the newest to the oldest points.
+//
+// When a tier is full, its oldest point is merged into the next tier, so each
+// partition keeps at most 576 points covering about a week.
+var historyTiers = []historyTier{
+	{resolution: 0, size: 120},
+	{resolution: int64(5 * time.Minute / time.Millisecond), size: 288},
+	{resolution: int64(time.Hour / time.Millisecond), size: 168},
+}
+
+// ring represents a bounded ring buffer of history points.
+//
+// The points are allocated as they are added, so the ring only wraps
+// once it has grown to its size.
+type ring struct {
+	points []HistoryPoint
+	size   int
+	start  int
+}
+
+func newRing(size int) *ring {
+	return &ring{size: size}
+}
+
+// push adds the point to the ring, returning the evicted point if the ring was full.
+func (r *ring) push(p HistoryPoint) (HistoryPoint, bool) {
+	if len(r.points) < r.size {
+		r.points = append(r.points, p)
+		return HistoryPoint{}, false
+	}
+
+	evicted := r.points[r.start]
+	r.points[r.start] = p
+	r.start = (r.start + 1) % len(r.points)
+	return evicted, true
+}
+
+// last returns the newest point of the ring, or nil if the ring is empty.
+func (r *ring) last() *HistoryPoint {
+	if len(r.points) == 0 {
+		return nil
+	}
+
+	return &r.points[(r.start+len(r.points)-1)%len(r.points)]
+}
+
+// each calls fn for every point in the ring, from the oldest to the newest.
+func (r *ring) each(fn func(p HistoryPoint)) {
+	for i := 0; i < len(r.points); i++ {
+		fn(r.points[(r.start+i)%len(r.points)])
+	}
+}
+
+// series represents the downsampled history of a partition.
+type series struct {
+	tiers []*ring
+}
+
+func newSeries() *series {
+	s := &series{tiers: make([]*ring, len(historyTiers))}
+	for i, tier := range historyTiers {
+		s.tiers[i] = newRing(tier.size)
+	}
+
+	return s
+}
+
+// restoreSeries creates a series from the points of its tiers.
+func restoreSeries(tiers [][]HistoryPoint) *series {
+	s := newSeries()
+	for i, pts := range tiers {
+		if i >= len(s.tiers) {
+			break
+		}
+
+		for _, p := range pts {
+			s.tiers[i].push(p)
+		}
+	}
+
+	return s
+}
+
+// snapshot returns the points of each tier, from the oldest to the newest.
+func (s *series) snapshot() [][]HistoryPoint {
+	tiers := make([][]HistoryPoint, len(s.tiers))
+	for i, r := range s.tiers {
+		r.each(func(p HistoryPoint) {
+			tiers[i] = append(tiers[i], p)
+		})
+	}
+
+	return tiers
+}
+
+// add adds a point to the series.
+func (s *series) add(p HistoryPoint) {
+	if last := s.tiers[0].last(); last != nil && p.Timestamp <= last.Timestamp {
+		// States are applied concurrently and replayed over restored history,
+		// ignore points that arrive out of order or twice.
+		return
+	}
+
+	s.push(0, p)
+}
+
+// push adds the point to the tier, merging the evicted point into the next tier.
+func (s *series) push(tier int, p HistoryPoint) {
+	if res := historyTiers[tier].resolution; res > 0 {
+		p.Timestamp -= p.Timestamp % res
+
+		if last := s.tiers[tier].last(); last != nil && last.Timestamp == p.Timestamp {
+			*last = mergePoints(*last, p)
+			return
+		}
+	}
+
+	evicted, ok := s.tiers[tier].push(p)
+	if ok && tier+1 < len(s.tiers) {
+		s.push(tier+1, evicted)
+	}
+}
+
+// points returns the points within the range, from the oldest to the newest.
+func (s *series) points(r HistoryRange) []HistoryPoint {
+	var pts []HistoryPoint
+	for i := len(s.tiers) - 1; i >= 0; i-- {
+		s.tiers[i].each(func(p HistoryPoint) {
+			if p.Timestamp < r.From || (r.To > 0 && p.Timestamp > r.To) {
+				return
+			}
+
+			pts = append(pts, p)
+		})
+	}
+
+	return downsample(pts, r.Step)
+}
+
+// downsample merges the points into buckets of the step size.
+func downsample(pts []HistoryPoint, step int64) []HistoryPoint {
+	if step <= 0 || len(pts) == 0 {
+		return pts
+	}
+
+	var result []HistoryPoint
+	for _, p := range pts {
+		p.Timestamp -= p.Timestamp % step
+
+		if n := len(result); n > 0 && result[n-1].Timestamp == p.Timestamp {
+			result[n-1] = mergePoints(result[n-1], p)
+			continue
+		}
+
+		result = append(result, p)
+	}
+
+	return result
+}
+
+// mergePoints merges a newer point into a bucket, keeping the last offsets and the maximum lag.
+func mergePoints(bucket, p HistoryPoint) HistoryPoint {
+	bucket.Offset = p.Offset
+	bucket.OldestOffset = p.OldestOffset
+	if p.Lag > bucket.Lag {
+		bucket.Lag = p.Lag
+	}
+
+	return bucket
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	r := newRing(2)
+	assert.Nil(t, r.last())
+
+	_, ok := r.push(HistoryPoint{Timestamp: 1})
+	assert.False(t, ok)
+	_, ok = r.push(HistoryPoint{Timestamp: 2})
+	assert.False(t, ok)
+	evicted, ok := r.push(HistoryPoint{Timestamp: 3})
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), evicted.Timestamp)
+	assert.Equal(t, int64(3), r.last().Timestamp)
+
+	var got []int64
+	r.each(func(p HistoryPoint) {
+		got = append(got, p.Timestamp)
+	})
+	assert.Equal(t, []int64{2, 3}, got)
+}
+
+func TestRing_GrowsLazily(t *testing.T) {
+	r := newRing(3)
+	assert.Len(t, r.points, 0)
+
+	r.push(HistoryPoint{Timestamp: 1})
+	r.push(HistoryPoint{Timestamp: 2})
+	assert.Len(t, r.points, 2)
+
+	r.push(HistoryPoint{Timestamp: 3})
+	r.push(HistoryPoint{Timestamp: 4})
+	assert.Len(t, r.points, 3)
+	assert.Equal(t, int64(4), r.last().Timestamp)
+}
+
+func TestSeries_Downsamples(t *testing.T) {
+	s := newSeries()
+
+	// Two days of points every 30 seconds.
+	step := int64(30000)
+	n := 2 * 24 * 60 * 2
+	for i := 0; i < n; i++ {
+		s.add(HistoryPoint{Timestamp: int64(i) * step, Offset: int64(i), Lag: int64(i % 10)})
+	}
+
+	pts := s.points(HistoryRange{})
+
+	size := 0
+	for _, tier := range historyTiers {
+		size += tier.size
+	}
+	assert.True(t, len(pts) <= size)
+
+	// The newest points are kept at full resolution.
+	last := pts[len(pts)-historyTiers[0].size:]
+	assert.Equal(t, int64(n-1)*step, last[len(last)-1].Timestamp)
+	assert.Equal(t, step, last[1].Timestamp-last[0].Timestamp)
+
+	// The points are ordered, and older points are downsampled.
+	for i := 1; i < len(pts); i++ {
+		assert.True(t, pts[i].Timestamp > pts[i-1].Timestamp)
+	}
+	assert.Equal(t, historyTiers[2].resolution, pts[1].Timestamp-pts[0].Timestamp)
+	assert.Equal(t, int64(9), pts[0].Lag)
+}
+
+func TestSeries_IgnoresOutOfOrderPoints(t *testing.T) {
+	s := newSeries()
+
+	s.add(HistoryPoint{Timestamp: 2000, Offset: 2})
+	s.add(HistoryPoint{Timestamp: 1000, Offset: 1})
+
+	assert.Equal(t, []HistoryPoint{{Timestamp: 2000, Offset: 2}}, s.points(HistoryRange{}))
+}
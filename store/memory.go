@@ -2,25 +2,82 @@ package store
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 )
 
+const (
+	// statusWindowSize is the number of samples used to evaluate a consumer status.
+	statusWindowSize = 10
+
+	// statusStoppedAfter is the time in milliseconds after which a consumer
+	// without commits is considered stopped.
+	statusStoppedAfter = int64(10 * time.Minute / time.Millisecond)
+)
+
+type consumerKey struct {
+	group     string
+	topic     string
+	partition int32
+}
+
+// consumerWindow represents a sliding window of consumer samples.
+type consumerWindow struct {
+	samples []LagSample
+	changed int64
+}
+
+// add adds a sample to the window, dropping the oldest sample if full.
+func (w *consumerWindow) add(s LagSample) {
+	n := len(w.samples)
+	if n > 0 && s.Timestamp < w.samples[n-1].Timestamp {
+		// States are applied concurrently, ignore samples that arrive out of order.
+		return
+	}
+
+	if n == 0 || w.samples[n-1].Offset != s.Offset {
+		w.changed = s.Timestamp
+	}
+
+	w.samples = append(w.samples, s)
+	if len(w.samples) > statusWindowSize {
+		w.samples = w.samples[len(w.samples)-statusWindowSize:]
+	}
+}
+
 // State represents the state of the store.
 type State struct {
-	broker     BrokerOffsets
-	brokerLock sync.RWMutex
-
-	consumer     ConsumerOffsets
-	consumerLock sync.RWMutex
+	broker        BrokerOffsets
+	brokerHistory map[topicPartitionKey]*brokerHistory
+	brokerSeries  map[topicPartitionKey]*series
+	brokerLock    sync.RWMutex
+
+	consumer        ConsumerOffsets
+	consumerWindows map[consumerKey]*consumerWindow
+	consumerSeries  map[consumerKey]*series
+	consumerPending map[topicPartitionKey]map[string][]*ConsumerPartitionOffset
+	consumerDeleted map[consumerKey]int64
+	consumerLock    sync.RWMutex
 
 	metadata     BrokerMetadata
 	metadataLock sync.RWMutex
+
+	groups     ConsumerGroups
+	groupsLock sync.RWMutex
+
+	topicConfigs     TopicConfigs
+	topicConfigsLock sync.RWMutex
+
+	logDirs     BrokerLogDirs
+	logDirsLock sync.RWMutex
 }
 
 // MemoryStore represents an in memory data store.
 type MemoryStore struct {
 	state         *State
+	expiry        int64
+	atRisk        float64
 	cleanupTicker *time.Ticker
 	shutdown      chan struct{}
 
@@ -28,41 +85,76 @@ type MemoryStore struct {
 }
 
 // New creates and returns a new MemoryStore.
-func New() (*MemoryStore, error) {
+func New(opts ...StoreFunc) (*MemoryStore, error) {
+	o := newOptions(opts)
+	m := newMemoryStore(o)
+
+	// Start the offset reader
+	go readStates(m.stateCh, m.shutdown, m.SetState)
+
+	// Start cleanup task
+	m.cleanupTicker = time.NewTicker(o.cleanupInterval)
+	go func() {
+		for range m.cleanupTicker.C {
+			m.CleanConsumerOffsets()
+		}
+	}()
+
+	return m, nil
+}
+
+// newMemoryStore creates a MemoryStore without starting its background tasks.
+func newMemoryStore(o options) *MemoryStore {
 	m := &MemoryStore{
+		expiry:   o.expiry.Milliseconds(),
+		atRisk:   o.atRiskThreshold,
 		shutdown: make(chan struct{}),
 		stateCh:  make(chan interface{}, 10000),
 	}
 
 	// Initialise the cluster offsets
 	m.state = &State{
-		broker:   make(BrokerOffsets),
-		consumer: make(ConsumerOffsets),
-		metadata: make(BrokerMetadata),
+		broker:          make(BrokerOffsets),
+		brokerHistory:   make(map[topicPartitionKey]*brokerHistory),
+		brokerSeries:    make(map[topicPartitionKey]*series),
+		consumer:        make(ConsumerOffsets),
+		consumerWindows: make(map[consumerKey]*consumerWindow),
+		consumerSeries:  make(map[consumerKey]*series),
+		consumerPending: make(map[topicPartitionKey]map[string][]*ConsumerPartitionOffset),
+		consumerDeleted: make(map[consumerKey]int64),
+		metadata:        make(BrokerMetadata),
+		groups:          make(ConsumerGroups),
+		topicConfigs:    make(TopicConfigs),
+		logDirs:         make(BrokerLogDirs),
 	}
 
-	// Start the offset reader
-	go func() {
-		for {
-			select {
-			case v := <-m.stateCh:
-				go func() { _ = m.SetState(v) }()
+	return m
+}
 
-			case <-m.shutdown:
-				return
+// readStates applies the states read from the channel until shutdown.
+func readStates(ch chan interface{}, shutdown chan struct{}, fn func(interface{}) error) {
+	var wg sync.WaitGroup
+	for {
+		select {
+		case v := <-ch:
+			if f, ok := v.(*Flush); ok {
+				// All states read before the flush are being applied, wait for them.
+				wg.Wait()
+				close(f.done)
+				continue
 			}
-		}
-	}()
 
-	// Start cleanup task
-	m.cleanupTicker = time.NewTicker(1 * time.Hour)
-	go func() {
-		for range m.cleanupTicker.C {
-			m.CleanConsumerOffsets()
-		}
-	}()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
 
-	return m, nil
+				_ = fn(v)
+			}()
+
+		case <-shutdown:
+			return
+		}
+	}
 }
 
 // SetState adds a state into the store.
@@ -70,13 +162,29 @@ func (m *MemoryStore) SetState(v interface{}) error {
 	switch val := v.(type) {
 	case *BrokerPartitionOffset:
 		m.addBrokerOffset(val)
+		if !val.Oldest {
+			m.addPendingConsumerOffsets(val.Topic, val.Partition)
+		}
 
 	case *ConsumerPartitionOffset:
+		if val.Deleted {
+			m.deleteConsumerOffset(val)
+			break
+		}
 		m.addConsumerOffset(val)
 
 	case *BrokerPartitionMetadata:
 		m.addMetadata(val)
 
+	case *ConsumerGroupDescription:
+		m.addConsumerGroup(val)
+
+	case *TopicConfigDescription:
+		m.addTopicConfig(val)
+
+	case *BrokerLogDirsDescription:
+		m.addLogDirs(val)
+
 	default:
 		return errors.New("store: unknown state object")
 	}
@@ -102,6 +210,7 @@ func (m *MemoryStore) BrokerOffsets() BrokerOffsets {
 				OldestOffset: offset.OldestOffset,
 				NewestOffset: offset.NewestOffset,
 				Timestamp:    offset.Timestamp,
+				ProduceRate:  offset.ProduceRate,
 			}
 		}
 	}
@@ -127,9 +236,17 @@ func (m *MemoryStore) ConsumerOffsets() ConsumerOffsets {
 				}
 
 				snapshot[group][topic][partition] = &ConsumerOffset{
-					Offset:    offset.Offset,
-					Lag:       offset.Lag,
-					Timestamp: offset.Timestamp,
+					Offset:      offset.Offset,
+					Lag:         offset.Lag,
+					TimeLag:     offset.TimeLag,
+					ConsumeRate: offset.ConsumeRate,
+					DrainRate:   offset.DrainRate,
+					Timestamp:   offset.Timestamp,
+					Status:      offset.Status,
+
+					DistanceToOldest: offset.DistanceToOldest,
+					DataLoss:         offset.DataLoss,
+					AtRisk:           offset.AtRisk,
 				}
 			}
 		}
@@ -153,9 +270,10 @@ func (m *MemoryStore) BrokerMetadata() BrokerMetadata {
 			}
 
 			snapshot[topic][partition] = &Metadata{
-				Leader:   metadata.Leader,
-				Replicas: make([]int32, len(metadata.Replicas)),
-				Isr:      make([]int32, len(metadata.Isr)),
+				Leader:    metadata.Leader,
+				Replicas:  make([]int32, len(metadata.Replicas)),
+				Isr:       make([]int32, len(metadata.Isr)),
+				Timestamp: metadata.Timestamp,
 			}
 			copy(snapshot[topic][partition].Replicas, metadata.Replicas)
 			copy(snapshot[topic][partition].Isr, metadata.Isr)
@@ -165,12 +283,207 @@ func (m *MemoryStore) BrokerMetadata() BrokerMetadata {
 	return snapshot
 }
 
-// CleanConsumerOffsets cleans old offsets from the MemoryStore.
+// ConsumerGroups returns a snapshot of the current consumer group descriptions.
+func (m *MemoryStore) ConsumerGroups() ConsumerGroups {
+	m.state.groupsLock.RLock()
+	defer m.state.groupsLock.RUnlock()
+
+	snapshot := make(ConsumerGroups)
+	for group, desc := range m.state.groups {
+		members := make([]ConsumerGroupMember, len(desc.Members))
+		for i, member := range desc.Members {
+			members[i] = member
+
+			if member.Assignment == nil {
+				continue
+			}
+
+			members[i].Assignment = make(map[string][]int32, len(member.Assignment))
+			for topic, partitions := range member.Assignment {
+				members[i].Assignment[topic] = make([]int32, len(partitions))
+				copy(members[i].Assignment[topic], partitions)
+			}
+		}
+
+		snapshot[group] = &ConsumerGroup{
+			State:        desc.State,
+			ProtocolType: desc.ProtocolType,
+			Protocol:     desc.Protocol,
+			Members:      members,
+			Timestamp:    desc.Timestamp,
+		}
+	}
+
+	return snapshot
+}
+
+// TopicConfigs returns a snapshot of the current topic configurations.
+func (m *MemoryStore) TopicConfigs() TopicConfigs {
+	m.state.topicConfigsLock.RLock()
+	defer m.state.topicConfigsLock.RUnlock()
+
+	snapshot := make(TopicConfigs)
+	for topic, config := range m.state.topicConfigs {
+		configs := make(map[string]string, len(config.Configs))
+		for name, value := range config.Configs {
+			configs[name] = value
+		}
+
+		snapshot[topic] = &TopicConfig{
+			Configs:   configs,
+			Timestamp: config.Timestamp,
+		}
+	}
+
+	return snapshot
+}
+
+// BrokerLogDirs returns a snapshot of the current broker log directories.
+func (m *MemoryStore) BrokerLogDirs() BrokerLogDirs {
+	m.state.logDirsLock.RLock()
+	defer m.state.logDirsLock.RUnlock()
+
+	snapshot := make(BrokerLogDirs)
+	for broker, dirs := range m.state.logDirs {
+		logDirs := make([]LogDir, len(dirs.LogDirs))
+		for i, dir := range dirs.LogDirs {
+			logDirs[i] = LogDir{
+				Path:     dir.Path,
+				Replicas: make([]LogDirReplica, len(dir.Replicas)),
+			}
+			copy(logDirs[i].Replicas, dir.Replicas)
+		}
+
+		snapshot[broker] = &LogDirs{
+			LogDirs:   logDirs,
+			Timestamp: dirs.Timestamp,
+		}
+	}
+
+	return snapshot
+}
+
+// BrokerHistory returns the offset history of a topic, indexed by partition.
+func (m *MemoryStore) BrokerHistory(topic string, r HistoryRange) [][]HistoryPoint {
+	m.state.brokerLock.RLock()
+	defer m.state.brokerLock.RUnlock()
+
+	partitions, ok := m.state.broker[topic]
+	if !ok {
+		return nil
+	}
+
+	history := make([][]HistoryPoint, len(partitions))
+	for partition := range partitions {
+		if s, ok := m.state.brokerSeries[topicPartitionKey{topic: topic, partition: int32(partition)}]; ok {
+			history[partition] = s.points(r)
+		}
+	}
+
+	return history
+}
+
+// ConsumerHistory returns the offset and lag history of a consumer group on a topic, indexed by partition.
+func (m *MemoryStore) ConsumerHistory(group, topic string, r HistoryRange) [][]HistoryPoint {
+	m.state.consumerLock.RLock()
+	defer m.state.consumerLock.RUnlock()
+
+	partitions, ok := m.state.consumer[group][topic]
+	if !ok {
+		return nil
+	}
+
+	history := make([][]HistoryPoint, len(partitions))
+	for partition := range partitions {
+		if s, ok := m.state.consumerSeries[consumerKey{group: group, topic: topic, partition: int32(partition)}]; ok {
+			history[partition] = s.points(r)
+		}
+	}
+
+	return history
+}
+
+// historySnapshot returns the tiers of the broker and consumer history series.
+func (m *MemoryStore) historySnapshot() (map[topicPartitionKey][][]HistoryPoint, map[consumerKey][][]HistoryPoint) {
+	m.state.brokerLock.RLock()
+	broker := make(map[topicPartitionKey][][]HistoryPoint, len(m.state.brokerSeries))
+	for key, s := range m.state.brokerSeries {
+		broker[key] = s.snapshot()
+	}
+	m.state.brokerLock.RUnlock()
+
+	m.state.consumerLock.RLock()
+	consumer := make(map[consumerKey][][]HistoryPoint, len(m.state.consumerSeries))
+	for key, s := range m.state.consumerSeries {
+		consumer[key] = s.snapshot()
+	}
+	m.state.consumerLock.RUnlock()
+
+	return broker, consumer
+}
+
+// restoreHistory replaces the broker and consumer history series with the given tiers.
+func (m *MemoryStore) restoreHistory(broker map[topicPartitionKey][][]HistoryPoint, consumer map[consumerKey][][]HistoryPoint) {
+	m.state.brokerLock.Lock()
+	for key, tiers := range broker {
+		m.state.brokerSeries[key] = restoreSeries(tiers)
+	}
+	m.state.brokerLock.Unlock()
+
+	m.state.consumerLock.Lock()
+	for key, tiers := range consumer {
+		m.state.consumerSeries[key] = restoreSeries(tiers)
+	}
+	m.state.consumerLock.Unlock()
+}
+
+// pruneHistory removes the history series of partitions without offsets.
+func (m *MemoryStore) pruneHistory() {
+	m.state.brokerLock.Lock()
+	for key := range m.state.brokerSeries {
+		partitions := m.state.broker[key.topic]
+		if int(key.partition) >= len(partitions) || partitions[key.partition] == nil {
+			delete(m.state.brokerSeries, key)
+		}
+	}
+	m.state.brokerLock.Unlock()
+
+	m.state.consumerLock.Lock()
+	for key := range m.state.consumerSeries {
+		partitions := m.state.consumer[key.group][key.topic]
+		if int(key.partition) >= len(partitions) || partitions[key.partition] == nil {
+			delete(m.state.consumerSeries, key)
+		}
+	}
+	m.state.consumerLock.Unlock()
+}
+
+// CleanConsumerOffsets cleans old offsets and consumer groups from the MemoryStore.
 func (m *MemoryStore) CleanConsumerOffsets() {
+	m.cleanConsumerGroups()
+
 	m.state.consumerLock.Lock()
 	defer m.state.consumerLock.Unlock()
 
 	ts := time.Now().Unix() * 1000
+	for key, deleted := range m.state.consumerDeleted {
+		if ts-deleted > m.expiry {
+			delete(m.state.consumerDeleted, key)
+		}
+	}
+
+	for key, groups := range m.state.consumerPending {
+		for group, offsets := range groups {
+			if ts-offsets[len(offsets)-1].Timestamp > m.expiry {
+				delete(groups, group)
+			}
+		}
+
+		if len(groups) == 0 {
+			delete(m.state.consumerPending, key)
+		}
+	}
+
 	for group, topics := range m.state.consumer {
 		for topic, partitions := range topics {
 			maxDuration := int64(0)
@@ -186,8 +499,14 @@ func (m *MemoryStore) CleanConsumerOffsets() {
 				}
 			}
 
-			if maxDuration > (24 * int64(time.Hour.Seconds()) * 1000) {
+			if maxDuration > m.expiry {
 				delete(m.state.consumer[group], topic)
+
+				for partition := range partitions {
+					key := consumerKey{group: group, topic: topic, partition: int32(partition)}
+					delete(m.state.consumerWindows, key)
+					delete(m.state.consumerSeries, key)
+				}
 			}
 		}
 
@@ -197,6 +516,18 @@ func (m *MemoryStore) CleanConsumerOffsets() {
 	}
 }
 
+func (m *MemoryStore) cleanConsumerGroups() {
+	m.state.groupsLock.Lock()
+	defer m.state.groupsLock.Unlock()
+
+	ts := time.Now().Unix() * 1000
+	for group, desc := range m.state.groups {
+		if ts-desc.Timestamp > m.expiry {
+			delete(m.state.groups, group)
+		}
+	}
+}
+
 // Channel get the offset channel.
 func (m *MemoryStore) Channel() chan interface{} {
 	return m.stateCh
@@ -234,20 +565,53 @@ func (m *MemoryStore) addBrokerOffset(o *BrokerPartitionOffset) {
 	partition.Timestamp = o.Timestamp
 	if o.Oldest {
 		partition.OldestOffset = o.Offset
-	} else {
-		partition.NewestOffset = o.Offset
+		return
 	}
+	partition.NewestOffset = o.Offset
+
+	key := topicPartitionKey{topic: o.Topic, partition: o.Partition}
+	history, ok := m.state.brokerHistory[key]
+	if !ok {
+		history = &brokerHistory{}
+		m.state.brokerHistory[key] = history
+	}
+	if n := len(history.samples); n > 0 {
+		last := history.samples[n-1]
+		if rate, ok := offsetRate(last.offset, last.timestamp, o.Offset, o.Timestamp); ok {
+			partition.ProduceRate = rate
+		}
+	}
+	history.add(offsetSample{offset: o.Offset, timestamp: o.Timestamp})
+
+	s, ok := m.state.brokerSeries[key]
+	if !ok {
+		s = newSeries()
+		m.state.brokerSeries[key] = s
+	}
+	s.add(HistoryPoint{Timestamp: o.Timestamp, Offset: o.Offset, OldestOffset: partition.OldestOffset})
 }
 
 func (m *MemoryStore) addConsumerOffset(o *ConsumerPartitionOffset) {
-	brokerOffset, partitionCount := m.getBrokerOffset(o.Topic, o.Partition)
+	brokerOffset, oldestOffset, partitionCount := m.getBrokerOffset(o.Topic, o.Partition)
 	if brokerOffset == -1 {
+		// The lag cannot be computed yet, e.g. while the consumer offsets
+		// are replayed before the first broker offsets are collected.
+		m.keepPendingConsumerOffset(o)
 		return
 	}
 
+	var timeLag int64
+	if o.Offset != 0 {
+		timeLag = m.getTimeLag(o.Topic, o.Partition, o.Offset, o.Timestamp)
+	}
+
 	m.state.consumerLock.Lock()
 	defer m.state.consumerLock.Unlock()
 
+	if m.isDeletedConsumerOffset(o) {
+		return
+	}
+
 	group, ok := m.state.consumer[o.Group]
 	if !ok {
 		group = make(map[string][]*ConsumerOffset)
@@ -281,26 +645,211 @@ func (m *MemoryStore) addConsumerOffset(o *ConsumerPartitionOffset) {
 	offset.Offset = o.Offset
 	offset.Timestamp = o.Timestamp
 	offset.Lag = lag
+	offset.TimeLag = timeLag
+
+	// Messages below the oldest offset have been deleted before they were consumed.
+	offset.DistanceToOldest = 0
+	offset.DataLoss = false
+	offset.AtRisk = false
+	if o.Offset != 0 {
+		offset.DistanceToOldest = o.Offset - oldestOffset
+		offset.DataLoss = o.Offset < oldestOffset
+		// A lagging consumer close to the oldest offset loses data on the next retention run,
+		// which only matters once retention has started to delete messages.
+		retained := brokerOffset - oldestOffset
+		offset.AtRisk = !offset.DataLoss && oldestOffset > 0 && lag > 0 &&
+			float64(offset.DistanceToOldest) < float64(retained)*m.atRisk
+	}
+
+	key := consumerKey{group: o.Group, topic: o.Topic, partition: o.Partition}
+	window, ok := m.state.consumerWindows[key]
+	if !ok {
+		window = &consumerWindow{}
+		m.state.consumerWindows[key] = window
+	}
+	// A zero offset has no commit to measure the rate from.
+	if n := len(window.samples); n > 0 && window.samples[n-1].Offset != 0 {
+		last := window.samples[n-1]
+		if rate, ok := offsetRate(last.Offset, last.Timestamp, o.Offset, o.Timestamp); ok {
+			offset.ConsumeRate = rate
+		}
+	}
+	window.add(LagSample{Offset: o.Offset, Lag: lag, Timestamp: o.Timestamp})
+
+	s, ok := m.state.consumerSeries[key]
+	if !ok {
+		s = newSeries()
+		m.state.consumerSeries[key] = s
+	}
+	s.add(HistoryPoint{Timestamp: o.Timestamp, Offset: o.Offset, Lag: lag})
+
+	offset.Status = EvaluateConsumerPartition(window.samples, window.changed, statusStoppedAfter)
+	switch {
+	case offset.DataLoss:
+		offset.Status = ConsumerStatusDataLoss
+	case offset.AtRisk:
+		offset.Status = ConsumerStatusAtRisk
+	}
+	offset.DrainRate = DrainRate(window.samples)
+}
+
+// keepPendingConsumerOffset keeps a consumer offset until the broker offsets of its partition are known.
+func (m *MemoryStore) keepPendingConsumerOffset(o *ConsumerPartitionOffset) {
+	m.state.consumerLock.Lock()
+	defer m.state.consumerLock.Unlock()
+
+	if m.isDeletedConsumerOffset(o) {
+		return
+	}
+
+	key := topicPartitionKey{topic: o.Topic, partition: o.Partition}
+	groups, ok := m.state.consumerPending[key]
+	if !ok {
+		groups = make(map[string][]*ConsumerPartitionOffset)
+		m.state.consumerPending[key] = groups
+	}
+
+	// Only the latest commits are needed to evaluate the consumer status.
+	offsets := append(groups[o.Group], o)
+	sort.SliceStable(offsets, func(i, j int) bool {
+		return offsets[i].Timestamp < offsets[j].Timestamp
+	})
+	if len(offsets) > statusWindowSize {
+		offsets = offsets[len(offsets)-statusWindowSize:]
+	}
+	groups[o.Group] = offsets
 }
 
-func (m *MemoryStore) getBrokerOffset(topic string, partition int32) (int64, int) {
+// deleteConsumerOffset removes a deleted consumer offset from the store.
+//
+// States are applied concurrently, so the deletion is remembered to ignore
+// the commits it replaced that arrive after it.
+func (m *MemoryStore) deleteConsumerOffset(o *ConsumerPartitionOffset) {
+	m.state.consumerLock.Lock()
+	defer m.state.consumerLock.Unlock()
+
+	key := consumerKey{group: o.Group, topic: o.Topic, partition: o.Partition}
+	if ts, ok := m.state.consumerDeleted[key]; ok && ts >= o.Timestamp {
+		return
+	}
+
+	partitions := m.state.consumer[o.Group][o.Topic]
+	if int(o.Partition) < len(partitions) && partitions[o.Partition] != nil {
+		if partitions[o.Partition].Timestamp > o.Timestamp {
+			// The offset was committed again after the deletion.
+			return
+		}
+
+		partitions[o.Partition] = nil
+		m.removeEmptyConsumerTopic(o.Group, o.Topic)
+	}
+	delete(m.state.consumerWindows, key)
+	delete(m.state.consumerSeries, key)
+
+	pendingKey := topicPartitionKey{topic: o.Topic, partition: o.Partition}
+	if groups, ok := m.state.consumerPending[pendingKey]; ok {
+		var pending []*ConsumerPartitionOffset
+		for _, offset := range groups[o.Group] {
+			if offset.Timestamp > o.Timestamp {
+				pending = append(pending, offset)
+			}
+		}
+		if len(pending) == 0 {
+			delete(groups, o.Group)
+		} else {
+			groups[o.Group] = pending
+		}
+		if len(groups) == 0 {
+			delete(m.state.consumerPending, pendingKey)
+		}
+	}
+
+	m.state.consumerDeleted[key] = o.Timestamp
+}
+
+// removeEmptyConsumerTopic removes the consumer topic and group once they have no offsets left.
+//
+// The caller must hold the consumer lock.
+func (m *MemoryStore) removeEmptyConsumerTopic(group, topic string) {
+	for _, offset := range m.state.consumer[group][topic] {
+		if offset != nil {
+			return
+		}
+	}
+
+	delete(m.state.consumer[group], topic)
+	if len(m.state.consumer[group]) == 0 {
+		delete(m.state.consumer, group)
+	}
+}
+
+// isDeletedConsumerOffset determines if the consumer offset was committed before its deletion.
+//
+// A newer commit clears the deletion. The caller must hold the consumer lock.
+func (m *MemoryStore) isDeletedConsumerOffset(o *ConsumerPartitionOffset) bool {
+	key := consumerKey{group: o.Group, topic: o.Topic, partition: o.Partition}
+	ts, ok := m.state.consumerDeleted[key]
+	if !ok {
+		return false
+	}
+	if o.Timestamp <= ts {
+		return true
+	}
+
+	delete(m.state.consumerDeleted, key)
+	return false
+}
+
+// addPendingConsumerOffsets adds the consumer offsets kept for a partition once its broker offsets are known.
+func (m *MemoryStore) addPendingConsumerOffsets(topic string, partition int32) {
+	key := topicPartitionKey{topic: topic, partition: partition}
+
+	m.state.consumerLock.Lock()
+	groups, ok := m.state.consumerPending[key]
+	delete(m.state.consumerPending, key)
+	m.state.consumerLock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	for _, offsets := range groups {
+		for _, o := range offsets {
+			m.addConsumerOffset(o)
+		}
+	}
+}
+
+func (m *MemoryStore) getBrokerOffset(topic string, partition int32) (int64, int64, int) {
 	m.state.brokerLock.RLock()
 	defer m.state.brokerLock.RUnlock()
 
 	brokerTopic, ok := m.state.broker[topic]
 	if !ok {
-		return -1, -1
+		return -1, -1, -1
 	}
 
 	if partition < 0 || partition > int32(len(brokerTopic)-1) {
-		return -1, -1
+		return -1, -1, -1
 	}
 
 	if brokerTopic[partition] == nil {
-		return -1, -1
+		return -1, -1, -1
+	}
+
+	return brokerTopic[partition].NewestOffset, brokerTopic[partition].OldestOffset, len(brokerTopic)
+}
+
+func (m *MemoryStore) getTimeLag(topic string, partition int32, offset, ts int64) int64 {
+	m.state.brokerLock.RLock()
+	defer m.state.brokerLock.RUnlock()
+
+	history, ok := m.state.brokerHistory[topicPartitionKey{topic: topic, partition: partition}]
+	if !ok {
+		return 0
 	}
 
-	return brokerTopic[partition].NewestOffset, len(brokerTopic)
+	return history.estimateTimeLag(offset, ts)
 }
 
 func (m *MemoryStore) addMetadata(v *BrokerPartitionMetadata) {
@@ -330,3 +879,36 @@ func (m *MemoryStore) addMetadata(v *BrokerPartitionMetadata) {
 	partition.Isr = v.Isr
 	partition.Timestamp = v.Timestamp
 }
+
+func (m *MemoryStore) addConsumerGroup(v *ConsumerGroupDescription) {
+	m.state.groupsLock.Lock()
+	defer m.state.groupsLock.Unlock()
+
+	m.state.groups[v.Group] = &ConsumerGroup{
+		State:        v.State,
+		ProtocolType: v.ProtocolType,
+		Protocol:     v.Protocol,
+		Members:      v.Members,
+		Timestamp:    v.Timestamp,
+	}
+}
+
+func (m *MemoryStore) addTopicConfig(v *TopicConfigDescription) {
+	m.state.topicConfigsLock.Lock()
+	defer m.state.topicConfigsLock.Unlock()
+
+	m.state.topicConfigs[v.Topic] = &TopicConfig{
+		Configs:   v.Configs,
+		Timestamp: v.Timestamp,
+	}
+}
+
+func (m *MemoryStore) addLogDirs(v *BrokerLogDirsDescription) {
+	m.state.logDirsLock.Lock()
+	defer m.state.logDirsLock.Unlock()
+
+	m.state.logDirs[v.Broker] = &LogDirs{
+		LogDirs:   v.LogDirs,
+		Timestamp: v.Timestamp,
+	}
+}
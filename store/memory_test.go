@@ -8,489 +8,199 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestMemoryStore_SetState(t *testing.T) {
+func newMemoryStore(t *testing.T) testStore {
 	memStore, err := store.New()
 	assert.NoError(t, err)
 
-	defer memStore.Close()
-
-	err = memStore.SetState(1)
-	assert.Error(t, err)
+	t.Cleanup(memStore.Close)
 
-	err = memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              true,
-		Offset:              0,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
-	assert.NoError(t, err)
+	return memStore
 }
 
-func TestMemoryStore_BrokerOffsets(t *testing.T) {
-	memStore, err := store.New()
+func TestMemoryStore_Expiry(t *testing.T) {
+	memStore, err := store.New(store.CleanupInterval(time.Minute), store.Expiry(time.Minute))
 	assert.NoError(t, err)
-
 	defer memStore.Close()
 
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              true,
-		Offset:              0,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
 	memStore.SetState(&store.BrokerPartitionOffset{
 		Topic:               "test",
 		Partition:           0,
 		Oldest:              false,
 		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
+		Timestamp:           time.Now().Unix() * 1000,
 		TopicPartitionCount: 1,
 	})
-
-	offsets := memStore.BrokerOffsets()
-
-	assert.Contains(t, offsets, "test")
-	assert.Len(t, offsets["test"], 1)
-	assert.Equal(t, int64(0), offsets["test"][0].OldestOffset)
-	assert.Equal(t, int64(1000), offsets["test"][0].NewestOffset)
-}
-
-func TestMemoryStore_BrokerOffsetsMissingParition(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
-
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           1,
-		Oldest:              true,
-		Offset:              0,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 2,
+	memStore.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: time.Now().Unix()*1000 - 2*60*1000,
 	})
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           1,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 2,
+	memStore.SetState(&store.ConsumerGroupDescription{
+		Group:     "foo",
+		State:     "Empty",
+		Timestamp: time.Now().Unix()*1000 - 2*60*1000,
 	})
 
-	offsets := memStore.BrokerOffsets()
-
-	assert.Contains(t, offsets, "test")
-	assert.Len(t, offsets["test"], 2)
-	assert.Nil(t, offsets["test"][0])
-	assert.Equal(t, int64(0), offsets["test"][1].OldestOffset)
-	assert.Equal(t, int64(1000), offsets["test"][1].NewestOffset)
-}
-
-func TestMemoryStore_BrokerOffsetsIncreasePartitions(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
-
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              true,
-		Offset:              0,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           1,
-		Oldest:              true,
-		Offset:              0,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 2,
-	})
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           1,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 2,
-	})
+	assert.Len(t, memStore.ConsumerOffsets(), 1)
 
-	offsets := memStore.BrokerOffsets()
+	memStore.CleanConsumerOffsets()
 
-	assert.Contains(t, offsets, "test")
-	assert.Len(t, offsets["test"], 2)
-	assert.Equal(t, int64(0), offsets["test"][1].OldestOffset)
-	assert.Equal(t, int64(1000), offsets["test"][1].NewestOffset)
+	assert.Len(t, memStore.ConsumerOffsets(), 0)
+	assert.Len(t, memStore.ConsumerGroups(), 0)
 }
 
-func TestMemoryStore_ConsumerOffsets(t *testing.T) {
-	memStore, err := store.New()
+func TestMemoryStore_AtRiskThreshold(t *testing.T) {
+	memStore, err := store.New(store.AtRiskThreshold(0.5))
 	assert.NoError(t, err)
-
 	defer memStore.Close()
 
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
+	ts := time.Now().Unix() * 1000
+	for _, oldest := range []bool{true, false} {
+		offset := int64(1000)
+		if oldest {
+			offset = 200
+		}
+
+		memStore.SetState(&store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           0,
+			Oldest:              oldest,
+			Offset:              offset,
+			Timestamp:           ts,
+			TopicPartitionCount: 1,
+		})
+	}
 	memStore.SetState(&store.ConsumerPartitionOffset{
 		Group:     "foo",
 		Topic:     "test",
 		Partition: 0,
 		Offset:    500,
-		Timestamp: time.Now().Unix(),
+		Timestamp: ts,
 	})
 
-	offsets := memStore.ConsumerOffsets()
+	offset := memStore.ConsumerOffsets()["foo"]["test"][0]
+	assert.True(t, offset.AtRisk)
+	assert.Equal(t, store.ConsumerStatusAtRisk, offset.Status)
+}
 
-	assert.Contains(t, offsets, "foo")
-	assert.Contains(t, offsets["foo"], "test")
-	assert.Len(t, offsets["foo"]["test"], 1)
-	assert.Equal(t, int64(500), offsets["foo"]["test"][0].Lag)
+func TestMemoryStore_SetState(t *testing.T) {
+	testStoreSetState(t, newMemoryStore)
 }
 
-func TestMemoryStore_ConsumerOffsetsZeroOffset(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
+func TestMemoryStore_BrokerOffsets(t *testing.T) {
+	testStoreBrokerOffsets(t, newMemoryStore)
+}
 
-	defer memStore.Close()
+func TestMemoryStore_BrokerOffsetsMissingParition(t *testing.T) {
+	testStoreBrokerOffsetsMissingParition(t, newMemoryStore)
+}
 
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 0,
-		Offset:    0,
-		Timestamp: time.Now().Unix(),
-	})
+func TestMemoryStore_BrokerOffsetsIncreasePartitions(t *testing.T) {
+	testStoreBrokerOffsetsIncreasePartitions(t, newMemoryStore)
+}
 
-	offsets := memStore.ConsumerOffsets()
+func TestMemoryStore_ConsumerOffsets(t *testing.T) {
+	testStoreConsumerOffsets(t, newMemoryStore)
+}
 
-	assert.Contains(t, offsets, "foo")
-	assert.Contains(t, offsets["foo"], "test")
-	assert.Len(t, offsets["foo"]["test"], 1)
-	assert.Equal(t, int64(0), offsets["foo"]["test"][0].Lag)
+func TestMemoryStore_ConsumerOffsetsZeroOffset(t *testing.T) {
+	testStoreConsumerOffsetsZeroOffset(t, newMemoryStore)
 }
 
 func TestMemoryStore_ConsumerOffsetsMissingPartition(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
-
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           1,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 2,
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 1,
-		Offset:    0,
-		Timestamp: time.Now().Unix(),
-	})
-
-	offsets := memStore.ConsumerOffsets()
-
-	assert.Contains(t, offsets, "foo")
-	assert.Contains(t, offsets["foo"], "test")
-	assert.Len(t, offsets["foo"]["test"], 2)
-	assert.Nil(t, offsets["foo"]["test"][0])
-	assert.Equal(t, int64(0), offsets["foo"]["test"][1].Lag)
+	testStoreConsumerOffsetsMissingPartition(t, newMemoryStore)
 }
 
 func TestMemoryStore_ConsumerOffsetsNoBrokerPartition(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
+	testStoreConsumerOffsetsNoBrokerPartition(t, newMemoryStore)
+}
 
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 0,
-		Offset:    500,
-		Timestamp: time.Now().Unix(),
-	})
+func TestMemoryStore_ConsumerOffsetsBeforeBrokerOffsets(t *testing.T) {
+	testStoreConsumerOffsetsBeforeBrokerOffsets(t, newMemoryStore)
+}
 
-	offsets := memStore.ConsumerOffsets()
+func TestMemoryStore_ConsumerOffsetsDeleted(t *testing.T) {
+	testStoreConsumerOffsetsDeleted(t, newMemoryStore)
+}
 
-	assert.Len(t, offsets, 0)
+func TestMemoryStore_ConsumerOffsetsDeletedBeforeCommit(t *testing.T) {
+	testStoreConsumerOffsetsDeletedBeforeCommit(t, newMemoryStore)
 }
 
 func TestMemoryStore_ConsumerOffsetsBrokerPartitionNil(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
-
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 2,
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 1,
-		Offset:    500,
-		Timestamp: time.Now().Unix(),
-	})
-
-	offsets := memStore.ConsumerOffsets()
-
-	assert.Len(t, offsets, 0)
+	testStoreConsumerOffsetsBrokerPartitionNil(t, newMemoryStore)
 }
 
 func TestMemoryStore_ConsumerOffsetsIncreasePartitions(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
-
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 0,
-		Offset:    500,
-		Timestamp: time.Now().Unix(),
-	})
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           1,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 2,
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 1,
-		Offset:    500,
-		Timestamp: time.Now().Unix(),
-	})
-
-	offsets := memStore.ConsumerOffsets()
-
-	assert.Contains(t, offsets, "foo")
-	assert.Contains(t, offsets["foo"], "test")
-	assert.Len(t, offsets["foo"]["test"], 2)
-	assert.Equal(t, int64(500), offsets["foo"]["test"][1].Lag)
+	testStoreConsumerOffsetsIncreasePartitions(t, newMemoryStore)
 }
 
 func TestMemoryStore_ConsumerOffsetsIncreasePartitionsBeforeBroker(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
-
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 0,
-		Offset:    500,
-		Timestamp: time.Now().Unix(),
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 1,
-		Offset:    500,
-		Timestamp: time.Now().Unix(),
-	})
-
-	offsets := memStore.ConsumerOffsets()
-
-	assert.Contains(t, offsets, "foo")
-	assert.Contains(t, offsets["foo"], "test")
-	assert.Len(t, offsets["foo"]["test"], 1)
+	testStoreConsumerOffsetsIncreasePartitionsBeforeBroker(t, newMemoryStore)
 }
 
 func TestMemoryStore_BrokerMetadata(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
-
-	memStore.SetState(&store.BrokerPartitionMetadata{
-		Topic:               "test",
-		Partition:           0,
-		TopicPartitionCount: 1,
-		Leader:              100,
-		Replicas:            []int32{100, 101},
-		Isr:                 []int32{100, 101},
-		Timestamp:           time.Now().Unix(),
-	})
-
-	brokerMetadata := memStore.BrokerMetadata()
-
-	assert.Contains(t, brokerMetadata, "test")
-	assert.Len(t, brokerMetadata["test"], 1)
-	assert.Equal(t, int32(100), brokerMetadata["test"][0].Leader)
-	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][0].Replicas)
-	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][0].Isr)
+	testStoreBrokerMetadata(t, newMemoryStore)
 }
 
 func TestMemoryStore_BrokerMetadataMissingPartition(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
-
-	memStore.SetState(&store.BrokerPartitionMetadata{
-		Topic:               "test",
-		Partition:           1,
-		TopicPartitionCount: 2,
-		Leader:              100,
-		Replicas:            []int32{100, 101},
-		Isr:                 []int32{100, 101},
-		Timestamp:           time.Now().Unix(),
-	})
-
-	brokerMetadata := memStore.BrokerMetadata()
-
-	assert.Contains(t, brokerMetadata, "test")
-	assert.Len(t, brokerMetadata["test"], 2)
-	assert.Nil(t, brokerMetadata["test"][0])
-	assert.Equal(t, int32(100), brokerMetadata["test"][1].Leader)
-	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][1].Replicas)
-	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][1].Isr)
+	testStoreBrokerMetadataMissingPartition(t, newMemoryStore)
 }
 
 func TestMemoryStore_BrokerMetadataIncreasePartitions(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
-
-	defer memStore.Close()
+	testStoreBrokerMetadataIncreasePartitions(t, newMemoryStore)
+}
 
-	memStore.SetState(&store.BrokerPartitionMetadata{
-		Topic:               "test",
-		Partition:           0,
-		TopicPartitionCount: 1,
-		Leader:              100,
-		Replicas:            []int32{100, 101},
-		Isr:                 []int32{100, 101},
-		Timestamp:           time.Now().Unix(),
-	})
-	memStore.SetState(&store.BrokerPartitionMetadata{
-		Topic:               "test",
-		Partition:           1,
-		TopicPartitionCount: 2,
-		Leader:              100,
-		Replicas:            []int32{100, 101},
-		Isr:                 []int32{100, 101},
-		Timestamp:           time.Now().Unix(),
-	})
+func TestMemoryStore_CleanConsumerOffsets(t *testing.T) {
+	testStoreCleanConsumerOffsets(t, newMemoryStore)
+}
 
-	brokerMetadata := memStore.BrokerMetadata()
+func TestMemoryStore_CleanConsumerOffsetsMissingPartition(t *testing.T) {
+	testStoreCleanConsumerOffsetsMissingPartition(t, newMemoryStore)
+}
 
-	assert.Contains(t, brokerMetadata, "test")
-	assert.Len(t, brokerMetadata["test"], 1)
-	assert.Equal(t, int32(100), brokerMetadata["test"][0].Leader)
-	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][0].Replicas)
-	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][0].Isr)
+func TestMemoryStore_CleanConsumerOffsetsBeforeBrokerOffsets(t *testing.T) {
+	testStoreCleanConsumerOffsetsBeforeBrokerOffsets(t, newMemoryStore)
 }
 
-func TestMemoryStore_CleanConsumerOffsets(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
+func TestMemoryStore_ConsumerOffsetsStatus(t *testing.T) {
+	testStoreConsumerOffsetsStatus(t, newMemoryStore)
+}
 
-	defer memStore.Close()
+func TestMemoryStore_ConsumerOffsetsTimeLag(t *testing.T) {
+	testStoreConsumerOffsetsTimeLag(t, newMemoryStore)
+}
 
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           0,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 1,
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 0,
-		Offset:    500,
-		Timestamp: time.Now().Unix()*1000 - (25 * int64(time.Hour.Seconds()) * 1000),
-	})
+func TestMemoryStore_ConsumerOffsetsRetention(t *testing.T) {
+	testStoreConsumerOffsetsRetention(t, newMemoryStore)
+}
 
-	memStore.CleanConsumerOffsets()
+func TestMemoryStore_Rates(t *testing.T) {
+	testStoreRates(t, newMemoryStore)
+}
 
-	assert.Len(t, memStore.ConsumerOffsets(), 0)
+func TestMemoryStore_History(t *testing.T) {
+	testStoreHistory(t, newMemoryStore)
 }
 
-func TestMemoryStore_CleanConsumerOffsetsMissingPartition(t *testing.T) {
-	memStore, err := store.New()
-	assert.NoError(t, err)
+func TestMemoryStore_ConsumerGroups(t *testing.T) {
+	testStoreConsumerGroups(t, newMemoryStore)
+}
 
-	defer memStore.Close()
+func TestMemoryStore_TopicConfigs(t *testing.T) {
+	testStoreTopicConfigs(t, newMemoryStore)
+}
 
-	memStore.SetState(&store.BrokerPartitionOffset{
-		Topic:               "test",
-		Partition:           1,
-		Oldest:              false,
-		Offset:              1000,
-		Timestamp:           time.Now().Unix(),
-		TopicPartitionCount: 2,
-	})
-	memStore.SetState(&store.ConsumerPartitionOffset{
-		Group:     "foo",
-		Topic:     "test",
-		Partition: 1,
-		Offset:    500,
-		Timestamp: time.Now().Unix()*1000 - (25 * int64(time.Hour.Seconds()) * 1000),
-	})
+func TestMemoryStore_BrokerLogDirs(t *testing.T) {
+	testStoreBrokerLogDirs(t, newMemoryStore)
+}
 
-	memStore.CleanConsumerOffsets()
+func TestMemoryStore_CleanConsumerGroups(t *testing.T) {
+	testStoreCleanConsumerGroups(t, newMemoryStore)
+}
 
-	assert.Len(t, memStore.ConsumerOffsets(), 0)
+func TestMemoryStore_Flush(t *testing.T) {
+	testStoreFlush(t, newMemoryStore)
 }
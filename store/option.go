@@ -0,0 +1,66 @@
+package store
+
+import "time"
+
+// Default store intervals.
+const (
+	defaultCleanupInterval = time.Hour
+	defaultExpiry          = 24 * time.Hour
+)
+
+// defaultAtRiskThreshold is the default fraction of the retained messages
+// under which a consumer is considered at risk of data loss.
+const defaultAtRiskThreshold = 0.1
+
+// StoreFunc represents a function that configures a store.
+type StoreFunc func(o *options)
+
+// options represents the store configuration.
+type options struct {
+	cleanupInterval time.Duration
+	expiry          time.Duration
+	atRiskThreshold float64
+}
+
+// newOptions creates the store configuration from the given functions.
+func newOptions(opts []StoreFunc) options {
+	o := options{
+		cleanupInterval: defaultCleanupInterval,
+		expiry:          defaultExpiry,
+		atRiskThreshold: defaultAtRiskThreshold,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// CleanupInterval configures the interval at which expired state is cleaned from the store.
+func CleanupInterval(d time.Duration) StoreFunc {
+	return func(o *options) {
+		if d > 0 {
+			o.cleanupInterval = d
+		}
+	}
+}
+
+// Expiry configures the age after which consumer offsets and groups are removed from the store.
+func Expiry(d time.Duration) StoreFunc {
+	return func(o *options) {
+		if d > 0 {
+			o.expiry = d
+		}
+	}
+}
+
+// AtRiskThreshold configures the fraction of the retained messages of a partition under
+// which the distance of a consumer to the oldest offset puts it at risk of data loss.
+func AtRiskThreshold(f float64) StoreFunc {
+	return func(o *options) {
+		if f > 0 {
+			o.atRiskThreshold = f
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package store
+
+// offsetRate calculates the rate in messages per second between two offset samples.
+//
+// An offset that moved backwards, such as after an offset reset or when a topic
+// was recreated, results in a zero rate. It returns false when the samples are
+// not ordered in time, in which case the previous rate should be kept.
+func offsetRate(prevOffset, prevTs, offset, ts int64) (float64, bool) {
+	if ts <= prevTs {
+		return 0, false
+	}
+
+	if offset < prevOffset {
+		return 0, true
+	}
+
+	return float64(offset-prevOffset) * 1000 / float64(ts-prevTs), true
+}
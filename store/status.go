@@ -0,0 +1,122 @@
+package store
+
+// ConsumerStatus represents the evaluated status of a consumer.
+type ConsumerStatus int
+
+// ConsumerStatus values, ordered from best to worst.
+const (
+	// ConsumerStatusOK means the consumer is keeping up.
+	ConsumerStatusOK ConsumerStatus = iota
+	// ConsumerStatusWarn means the consumer is committing, but the lag is growing.
+	ConsumerStatusWarn
+	// ConsumerStatusStalled means the consumer has not committed within the window while there is lag.
+	ConsumerStatusStalled
+	// ConsumerStatusStopped means the consumer has not committed for longer than the
+	// stopped threshold while the lag is growing.
+	ConsumerStatusStopped
+	// ConsumerStatusError means the consumer offset moved backwards.
+	ConsumerStatusError
+	// ConsumerStatusAtRisk means the committed offset is close to the oldest
+	// offset while there is lag, so messages may be deleted before they are consumed.
+	ConsumerStatusAtRisk
+	// ConsumerStatusDataLoss means the committed offset is below the oldest
+	// offset, so messages were deleted before they were consumed.
+	ConsumerStatusDataLoss
+)
+
+// String returns the string representation of the status.
+func (s ConsumerStatus) String() string {
+	switch s {
+	case ConsumerStatusOK:
+		return "OK"
+	case ConsumerStatusWarn:
+		return "WARN"
+	case ConsumerStatusStalled:
+		return "STALLED"
+	case ConsumerStatusStopped:
+		return "STOPPED"
+	case ConsumerStatusError:
+		return "ERROR"
+	case ConsumerStatusAtRisk:
+		return "AT_RISK"
+	case ConsumerStatusDataLoss:
+		return "DATA_LOSS"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// LagSample represents a consumer offset and lag at a point in time.
+type LagSample struct {
+	Offset    int64
+	Lag       int64
+	Timestamp int64
+}
+
+// EvaluateConsumerPartition evaluates the status of a consumer partition from
+// a window of samples ordered from oldest to newest.
+//
+// The changed timestamp is the time the offset last changed, and stoppedAfter is
+// the time in milliseconds after which a consumer without commits is considered stopped.
+func EvaluateConsumerPartition(samples []LagSample, changed, stoppedAfter int64) ConsumerStatus {
+	if len(samples) < 2 {
+		return ConsumerStatusOK
+	}
+
+	// A rewound offset is an error, even when the consumer caught up before.
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Offset < samples[i-1].Offset {
+			return ConsumerStatusError
+		}
+	}
+
+	// A consumer that caught up at any point in the window is fine.
+	for _, s := range samples {
+		if s.Lag == 0 {
+			return ConsumerStatusOK
+		}
+	}
+
+	oldest, newest := samples[0], samples[len(samples)-1]
+
+	lagGrowing := newest.Lag > oldest.Lag
+
+	if oldest.Offset == newest.Offset {
+		if lagGrowing && newest.Timestamp-changed >= stoppedAfter {
+			return ConsumerStatusStopped
+		}
+
+		return ConsumerStatusStalled
+	}
+
+	if lagGrowing {
+		for i := 1; i < len(samples); i++ {
+			if samples[i].Lag < samples[i-1].Lag {
+				return ConsumerStatusOK
+			}
+		}
+
+		return ConsumerStatusWarn
+	}
+
+	return ConsumerStatusOK
+}
+
+// EvaluateConsumerGroup evaluates the status of a consumer group as the
+// worst status of its partitions.
+func EvaluateConsumerGroup(topics map[string][]*ConsumerOffset) ConsumerStatus {
+	status := ConsumerStatusOK
+	for _, partitions := range topics {
+		for _, offset := range partitions {
+			if offset == nil {
+				continue
+			}
+
+			if offset.Status > status {
+				status = offset.Status
+			}
+		}
+	}
+
+	return status
+}
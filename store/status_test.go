@@ -0,0 +1,120 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/msales/kage/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerStatus_String(t *testing.T) {
+	assert.Equal(t, "OK", store.ConsumerStatusOK.String())
+	assert.Equal(t, "WARN", store.ConsumerStatusWarn.String())
+	assert.Equal(t, "STALLED", store.ConsumerStatusStalled.String())
+	assert.Equal(t, "STOPPED", store.ConsumerStatusStopped.String())
+	assert.Equal(t, "ERROR", store.ConsumerStatusError.String())
+	assert.Equal(t, "AT_RISK", store.ConsumerStatusAtRisk.String())
+	assert.Equal(t, "DATA_LOSS", store.ConsumerStatusDataLoss.String())
+	assert.Equal(t, "UNKNOWN", store.ConsumerStatus(100).String())
+}
+
+func TestEvaluateConsumerPartition(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []store.LagSample
+		changed int64
+		want    store.ConsumerStatus
+	}{
+		{
+			name:    "not enough samples",
+			samples: []store.LagSample{{Offset: 10, Lag: 100, Timestamp: 1000}},
+			want:    store.ConsumerStatusOK,
+		},
+		{
+			name: "caught up in window",
+			samples: []store.LagSample{
+				{Offset: 10, Lag: 0, Timestamp: 1000},
+				{Offset: 10, Lag: 100, Timestamp: 2000},
+			},
+			want: store.ConsumerStatusOK,
+		},
+		{
+			name: "rewound after catching up",
+			samples: []store.LagSample{
+				{Offset: 100, Lag: 0, Timestamp: 1000},
+				{Offset: 50, Lag: 50, Timestamp: 2000},
+			},
+			want: store.ConsumerStatusError,
+		},
+		{
+			name: "progressing with stable lag",
+			samples: []store.LagSample{
+				{Offset: 10, Lag: 100, Timestamp: 1000},
+				{Offset: 20, Lag: 110, Timestamp: 2000},
+				{Offset: 30, Lag: 100, Timestamp: 3000},
+			},
+			want: store.ConsumerStatusOK,
+		},
+		{
+			name: "progressing with growing lag",
+			samples: []store.LagSample{
+				{Offset: 10, Lag: 100, Timestamp: 1000},
+				{Offset: 20, Lag: 110, Timestamp: 2000},
+				{Offset: 30, Lag: 120, Timestamp: 3000},
+			},
+			want: store.ConsumerStatusWarn,
+		},
+		{
+			name: "rewound",
+			samples: []store.LagSample{
+				{Offset: 30, Lag: 100, Timestamp: 1000},
+				{Offset: 10, Lag: 120, Timestamp: 2000},
+			},
+			want: store.ConsumerStatusError,
+		},
+		{
+			name: "no commits in window",
+			samples: []store.LagSample{
+				{Offset: 10, Lag: 100, Timestamp: 1000},
+				{Offset: 10, Lag: 120, Timestamp: 2000},
+			},
+			changed: 1000,
+			want:    store.ConsumerStatusStalled,
+		},
+		{
+			name: "no commits for longer than stopped threshold",
+			samples: []store.LagSample{
+				{Offset: 10, Lag: 100, Timestamp: 5000},
+				{Offset: 10, Lag: 120, Timestamp: 6000},
+			},
+			changed: 1000,
+			want:    store.ConsumerStatusStopped,
+		},
+		{
+			name: "no commits for longer than stopped threshold without lag growth",
+			samples: []store.LagSample{
+				{Offset: 10, Lag: 100, Timestamp: 5000},
+				{Offset: 10, Lag: 100, Timestamp: 6000},
+			},
+			changed: 1000,
+			want:    store.ConsumerStatusStalled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := store.EvaluateConsumerPartition(tt.samples, tt.changed, 5000)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvaluateConsumerGroup(t *testing.T) {
+	topics := map[string][]*store.ConsumerOffset{
+		"foo": {{Status: store.ConsumerStatusOK}, nil},
+		"bar": {{Status: store.ConsumerStatusStalled}, {Status: store.ConsumerStatusWarn}},
+	}
+
+	assert.Equal(t, store.ConsumerStatusStalled, store.EvaluateConsumerGroup(topics))
+}
@@ -0,0 +1,1016 @@
+package store_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msales/kage/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStore represents the store behavior shared by all store implementations.
+type testStore interface {
+	SetState(interface{}) error
+	BrokerOffsets() store.BrokerOffsets
+	ConsumerOffsets() store.ConsumerOffsets
+	BrokerMetadata() store.BrokerMetadata
+	ConsumerGroups() store.ConsumerGroups
+	TopicConfigs() store.TopicConfigs
+	BrokerLogDirs() store.BrokerLogDirs
+	BrokerHistory(topic string, r store.HistoryRange) [][]store.HistoryPoint
+	ConsumerHistory(group, topic string, r store.HistoryRange) [][]store.HistoryPoint
+	CleanConsumerOffsets()
+	Channel() chan interface{}
+	Close()
+}
+
+type storeFactory func(t *testing.T) testStore
+
+func testStoreSetState(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	err := s.SetState(1)
+	assert.Error(t, err)
+
+	err = s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              true,
+		Offset:              0,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	assert.NoError(t, err)
+}
+
+func testStoreBrokerOffsets(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              true,
+		Offset:              0,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+
+	offsets := s.BrokerOffsets()
+
+	assert.Contains(t, offsets, "test")
+	assert.Len(t, offsets["test"], 1)
+	assert.Equal(t, int64(0), offsets["test"][0].OldestOffset)
+	assert.Equal(t, int64(1000), offsets["test"][0].NewestOffset)
+}
+
+func testStoreBrokerOffsetsMissingParition(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           1,
+		Oldest:              true,
+		Offset:              0,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 2,
+	})
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           1,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 2,
+	})
+
+	offsets := s.BrokerOffsets()
+
+	assert.Contains(t, offsets, "test")
+	assert.Len(t, offsets["test"], 2)
+	assert.Nil(t, offsets["test"][0])
+	assert.Equal(t, int64(0), offsets["test"][1].OldestOffset)
+	assert.Equal(t, int64(1000), offsets["test"][1].NewestOffset)
+}
+
+func testStoreBrokerOffsetsIncreasePartitions(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              true,
+		Offset:              0,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           1,
+		Oldest:              true,
+		Offset:              0,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 2,
+	})
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           1,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 2,
+	})
+
+	offsets := s.BrokerOffsets()
+
+	assert.Contains(t, offsets, "test")
+	assert.Len(t, offsets["test"], 2)
+	assert.Equal(t, int64(0), offsets["test"][1].OldestOffset)
+	assert.Equal(t, int64(1000), offsets["test"][1].NewestOffset)
+}
+
+func testStoreConsumerOffsets(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: time.Now().Unix(),
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Contains(t, offsets, "foo")
+	assert.Contains(t, offsets["foo"], "test")
+	assert.Len(t, offsets["foo"]["test"], 1)
+	assert.Equal(t, int64(500), offsets["foo"]["test"][0].Lag)
+}
+
+func testStoreConsumerOffsetsZeroOffset(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    0,
+		Timestamp: time.Now().Unix(),
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Contains(t, offsets, "foo")
+	assert.Contains(t, offsets["foo"], "test")
+	assert.Len(t, offsets["foo"]["test"], 1)
+	assert.Equal(t, int64(0), offsets["foo"]["test"][0].Lag)
+}
+
+func testStoreConsumerOffsetsMissingPartition(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           1,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 2,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 1,
+		Offset:    0,
+		Timestamp: time.Now().Unix(),
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Contains(t, offsets, "foo")
+	assert.Contains(t, offsets["foo"], "test")
+	assert.Len(t, offsets["foo"]["test"], 2)
+	assert.Nil(t, offsets["foo"]["test"][0])
+	assert.Equal(t, int64(0), offsets["foo"]["test"][1].Lag)
+}
+
+func testStoreConsumerOffsetsNoBrokerPartition(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: time.Now().Unix(),
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Len(t, offsets, 0)
+}
+
+func testStoreConsumerOffsetsBeforeBrokerOffsets(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	ts := time.Now().Unix() * 1000
+	for i, offset := range []int64{100, 200, 300} {
+		s.SetState(&store.ConsumerPartitionOffset{
+			Group:     "foo",
+			Topic:     "test",
+			Partition: 0,
+			Offset:    offset,
+			Timestamp: ts + int64(i)*1000,
+		})
+	}
+
+	assert.Len(t, s.ConsumerOffsets(), 0)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              true,
+		Offset:              0,
+		Timestamp:           ts + 3000,
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           ts + 3000,
+		TopicPartitionCount: 1,
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Contains(t, offsets, "foo")
+	assert.Len(t, offsets["foo"]["test"], 1)
+	assert.Equal(t, int64(300), offsets["foo"]["test"][0].Offset)
+	assert.Equal(t, int64(700), offsets["foo"]["test"][0].Lag)
+	assert.Equal(t, float64(100), offsets["foo"]["test"][0].ConsumeRate)
+}
+
+func testStoreCleanConsumerOffsetsBeforeBrokerOffsets(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: time.Now().Unix()*1000 - (25 * int64(time.Hour.Seconds()) * 1000),
+	})
+
+	s.CleanConsumerOffsets()
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix() * 1000,
+		TopicPartitionCount: 1,
+	})
+
+	assert.Len(t, s.ConsumerOffsets(), 0)
+}
+
+func testStoreConsumerOffsetsDeleted(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	ts := time.Now().Unix() * 1000
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           ts,
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: ts,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Timestamp: ts + 1000,
+		Deleted:   true,
+	})
+
+	assert.Len(t, s.ConsumerOffsets(), 0)
+	assert.Len(t, s.ConsumerHistory("foo", "test", store.HistoryRange{}), 0)
+
+	// A commit from before the deletion that arrives late is ignored.
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    400,
+		Timestamp: ts + 500,
+	})
+
+	assert.Len(t, s.ConsumerOffsets(), 0)
+
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    600,
+		Timestamp: ts + 2000,
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Contains(t, offsets, "foo")
+	assert.Equal(t, int64(600), offsets["foo"]["test"][0].Offset)
+}
+
+func testStoreConsumerOffsetsDeletedBeforeCommit(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	ts := time.Now().Unix() * 1000
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           ts,
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: ts + 2000,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Timestamp: ts + 1000,
+		Deleted:   true,
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Contains(t, offsets, "foo")
+	assert.Equal(t, int64(500), offsets["foo"]["test"][0].Offset)
+}
+
+func testStoreConsumerOffsetsBrokerPartitionNil(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 2,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 1,
+		Offset:    500,
+		Timestamp: time.Now().Unix(),
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Len(t, offsets, 0)
+}
+
+func testStoreConsumerOffsetsIncreasePartitions(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: time.Now().Unix(),
+	})
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           1,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 2,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 1,
+		Offset:    500,
+		Timestamp: time.Now().Unix(),
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Contains(t, offsets, "foo")
+	assert.Contains(t, offsets["foo"], "test")
+	assert.Len(t, offsets["foo"]["test"], 2)
+	assert.Equal(t, int64(500), offsets["foo"]["test"][1].Lag)
+}
+
+func testStoreConsumerOffsetsIncreasePartitionsBeforeBroker(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: time.Now().Unix(),
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 1,
+		Offset:    500,
+		Timestamp: time.Now().Unix(),
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Contains(t, offsets, "foo")
+	assert.Contains(t, offsets["foo"], "test")
+	assert.Len(t, offsets["foo"]["test"], 1)
+}
+
+func testStoreBrokerMetadata(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	ts := time.Now().Unix() * 1000
+	s.SetState(&store.BrokerPartitionMetadata{
+		Topic:               "test",
+		Partition:           0,
+		TopicPartitionCount: 1,
+		Leader:              100,
+		Replicas:            []int32{100, 101},
+		Isr:                 []int32{100, 101},
+		Timestamp:           ts,
+	})
+
+	brokerMetadata := s.BrokerMetadata()
+
+	assert.Contains(t, brokerMetadata, "test")
+	assert.Len(t, brokerMetadata["test"], 1)
+	assert.Equal(t, int32(100), brokerMetadata["test"][0].Leader)
+	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][0].Replicas)
+	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][0].Isr)
+	assert.Equal(t, ts, brokerMetadata["test"][0].Timestamp)
+}
+
+func testStoreBrokerMetadataMissingPartition(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionMetadata{
+		Topic:               "test",
+		Partition:           1,
+		TopicPartitionCount: 2,
+		Leader:              100,
+		Replicas:            []int32{100, 101},
+		Isr:                 []int32{100, 101},
+		Timestamp:           time.Now().Unix(),
+	})
+
+	brokerMetadata := s.BrokerMetadata()
+
+	assert.Contains(t, brokerMetadata, "test")
+	assert.Len(t, brokerMetadata["test"], 2)
+	assert.Nil(t, brokerMetadata["test"][0])
+	assert.Equal(t, int32(100), brokerMetadata["test"][1].Leader)
+	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][1].Replicas)
+	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][1].Isr)
+}
+
+func testStoreBrokerMetadataIncreasePartitions(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionMetadata{
+		Topic:               "test",
+		Partition:           0,
+		TopicPartitionCount: 1,
+		Leader:              100,
+		Replicas:            []int32{100, 101},
+		Isr:                 []int32{100, 101},
+		Timestamp:           time.Now().Unix(),
+	})
+	s.SetState(&store.BrokerPartitionMetadata{
+		Topic:               "test",
+		Partition:           1,
+		TopicPartitionCount: 2,
+		Leader:              100,
+		Replicas:            []int32{100, 101},
+		Isr:                 []int32{100, 101},
+		Timestamp:           time.Now().Unix(),
+	})
+
+	brokerMetadata := s.BrokerMetadata()
+
+	assert.Contains(t, brokerMetadata, "test")
+	assert.Len(t, brokerMetadata["test"], 1)
+	assert.Equal(t, int32(100), brokerMetadata["test"][0].Leader)
+	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][0].Replicas)
+	assert.Equal(t, []int32{100, 101}, brokerMetadata["test"][0].Isr)
+}
+
+func testStoreCleanConsumerOffsets(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: time.Now().Unix()*1000 - (25 * int64(time.Hour.Seconds()) * 1000),
+	})
+
+	s.CleanConsumerOffsets()
+
+	assert.Len(t, s.ConsumerOffsets(), 0)
+}
+
+func testStoreCleanConsumerOffsetsMissingPartition(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           1,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           time.Now().Unix(),
+		TopicPartitionCount: 2,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 1,
+		Offset:    500,
+		Timestamp: time.Now().Unix()*1000 - (25 * int64(time.Hour.Seconds()) * 1000),
+	})
+
+	s.CleanConsumerOffsets()
+
+	assert.Len(t, s.ConsumerOffsets(), 0)
+}
+
+func testStoreConsumerOffsetsStatus(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              1000,
+		Timestamp:           1000,
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: 1000,
+	})
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           0,
+		Oldest:              false,
+		Offset:              2000,
+		Timestamp:           2000,
+		TopicPartitionCount: 1,
+	})
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: 2000,
+	})
+
+	offsets := s.ConsumerOffsets()
+
+	assert.Equal(t, store.ConsumerStatusStalled, offsets["foo"]["test"][0].Status)
+
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    100,
+		Timestamp: 3000,
+	})
+
+	offsets = s.ConsumerOffsets()
+
+	assert.Equal(t, store.ConsumerStatusError, offsets["foo"]["test"][0].Status)
+}
+
+func testStoreConsumerOffsetsTimeLag(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	for i, offset := range []int64{1000, 2000, 4000} {
+		s.SetState(&store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           0,
+			Oldest:              false,
+			Offset:              offset,
+			Timestamp:           int64(i+1) * 10000,
+			TopicPartitionCount: 1,
+		})
+	}
+
+	tests := []struct {
+		offset int64
+		want   int64
+	}{
+		{offset: 4000, want: 0},
+		{offset: 3000, want: 10000},
+		{offset: 2000, want: 15000},
+		{offset: 1500, want: 20000},
+		{offset: 500, want: 25000},
+	}
+
+	for _, tt := range tests {
+		s.SetState(&store.ConsumerPartitionOffset{
+			Group:     "foo",
+			Topic:     "test",
+			Partition: 0,
+			Offset:    tt.offset,
+			Timestamp: 35000,
+		})
+
+		offsets := s.ConsumerOffsets()
+
+		assert.Equal(t, tt.want, offsets["foo"]["test"][0].TimeLag, "offset %d", tt.offset)
+	}
+}
+
+func testStoreHistory(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.BrokerPartitionOffset{
+		Topic:               "test",
+		Partition:           1,
+		Oldest:              true,
+		Offset:              100,
+		Timestamp:           10000,
+		TopicPartitionCount: 2,
+	})
+	for i, offset := range []int64{1000, 2000, 4000} {
+		s.SetState(&store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           1,
+			Oldest:              false,
+			Offset:              offset,
+			Timestamp:           int64(i+1) * 10000,
+			TopicPartitionCount: 2,
+		})
+		s.SetState(&store.ConsumerPartitionOffset{
+			Group:     "foo",
+			Topic:     "test",
+			Partition: 1,
+			Offset:    offset - 500,
+			Timestamp: int64(i+1)*10000 + 1,
+		})
+	}
+
+	broker := s.BrokerHistory("test", store.HistoryRange{From: 15000})
+	assert.Len(t, broker, 2)
+	assert.Nil(t, broker[0])
+	assert.Equal(t, []store.HistoryPoint{
+		{Timestamp: 20000, Offset: 2000, OldestOffset: 100},
+		{Timestamp: 30000, Offset: 4000, OldestOffset: 100},
+	}, broker[1])
+
+	consumer := s.ConsumerHistory("foo", "test", store.HistoryRange{To: 20001})
+	assert.Len(t, consumer, 2)
+	assert.Nil(t, consumer[0])
+	assert.Equal(t, []store.HistoryPoint{
+		{Timestamp: 10001, Offset: 500, Lag: 500},
+		{Timestamp: 20001, Offset: 1500, Lag: 500},
+	}, consumer[1])
+
+	consumer = s.ConsumerHistory("foo", "test", store.HistoryRange{Step: 60000})
+	assert.Equal(t, []store.HistoryPoint{{Timestamp: 0, Offset: 3500, Lag: 500}}, consumer[1])
+
+	assert.Nil(t, s.BrokerHistory("unknown", store.HistoryRange{}))
+	assert.Nil(t, s.ConsumerHistory("foo", "unknown", store.HistoryRange{}))
+	assert.Nil(t, s.ConsumerHistory("unknown", "test", store.HistoryRange{}))
+}
+
+func testStoreConsumerOffsetsRetention(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	ts := time.Now().Unix() * 1000
+	for _, oldest := range []bool{true, false} {
+		offset := int64(1000)
+		if oldest {
+			offset = 200
+		}
+
+		s.SetState(&store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           0,
+			Oldest:              oldest,
+			Offset:              offset,
+			Timestamp:           ts,
+			TopicPartitionCount: 1,
+		})
+	}
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    500,
+		Timestamp: ts,
+	})
+
+	offset := s.ConsumerOffsets()["foo"]["test"][0]
+	assert.Equal(t, int64(300), offset.DistanceToOldest)
+	assert.False(t, offset.DataLoss)
+	assert.False(t, offset.AtRisk)
+	assert.Equal(t, store.ConsumerStatusOK, offset.Status)
+
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    250,
+		Timestamp: ts + 1000,
+	})
+
+	offset = s.ConsumerOffsets()["foo"]["test"][0]
+	assert.Equal(t, int64(50), offset.DistanceToOldest)
+	assert.False(t, offset.DataLoss)
+	assert.True(t, offset.AtRisk)
+	assert.Equal(t, store.ConsumerStatusAtRisk, offset.Status)
+
+	s.SetState(&store.ConsumerPartitionOffset{
+		Group:     "foo",
+		Topic:     "test",
+		Partition: 0,
+		Offset:    100,
+		Timestamp: ts + 2000,
+	})
+
+	offset = s.ConsumerOffsets()["foo"]["test"][0]
+	assert.Equal(t, int64(-100), offset.DistanceToOldest)
+	assert.True(t, offset.DataLoss)
+	assert.False(t, offset.AtRisk)
+	assert.Equal(t, store.ConsumerStatusDataLoss, offset.Status)
+}
+
+func testStoreRates(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	broker := func(partition int32, offset, ts int64, count int) {
+		s.SetState(&store.BrokerPartitionOffset{
+			Topic:               "test",
+			Partition:           partition,
+			Offset:              offset,
+			Timestamp:           ts,
+			TopicPartitionCount: count,
+		})
+	}
+	consumer := func(offset, ts int64) {
+		s.SetState(&store.ConsumerPartitionOffset{
+			Group:     "foo",
+			Topic:     "test",
+			Partition: 0,
+			Offset:    offset,
+			Timestamp: ts,
+		})
+	}
+
+	broker(0, 1000, 10000, 1)
+	assert.Equal(t, float64(0), s.BrokerOffsets()["test"][0].ProduceRate)
+
+	broker(0, 2000, 20000, 1)
+	assert.Equal(t, float64(100), s.BrokerOffsets()["test"][0].ProduceRate)
+
+	// A repeated sample keeps the rate.
+	broker(0, 2000, 20000, 1)
+	assert.Equal(t, float64(100), s.BrokerOffsets()["test"][0].ProduceRate)
+
+	// An added partition starts without a rate.
+	broker(1, 500, 30000, 2)
+	assert.Equal(t, float64(0), s.BrokerOffsets()["test"][1].ProduceRate)
+
+	// A reset offset has no rate.
+	broker(0, 100, 30000, 2)
+	assert.Equal(t, float64(0), s.BrokerOffsets()["test"][0].ProduceRate)
+
+	consumer(0, 10000)
+	consumer(50, 15000)
+	assert.Equal(t, float64(0), s.ConsumerOffsets()["foo"]["test"][0].ConsumeRate)
+
+	consumer(80, 25000)
+	assert.Equal(t, float64(3), s.ConsumerOffsets()["foo"]["test"][0].ConsumeRate)
+
+	consumer(10, 35000)
+	assert.Equal(t, float64(0), s.ConsumerOffsets()["foo"]["test"][0].ConsumeRate)
+
+	// The lag grew from 0 to 90 over the 25 seconds of the window.
+	assert.Equal(t, -3.6, s.ConsumerOffsets()["foo"]["test"][0].DrainRate)
+}
+
+func testStoreConsumerGroups(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	ts := time.Now().Unix() * 1000
+	err := s.SetState(&store.ConsumerGroupDescription{
+		Group:        "foo",
+		State:        "Stable",
+		ProtocolType: "consumer",
+		Protocol:     "range",
+		Members: []store.ConsumerGroupMember{
+			{
+				MemberID:   "consumer-1-abc",
+				ClientID:   "consumer-1",
+				ClientHost: "/127.0.0.1",
+				Assignment: map[string][]int32{"test": {0, 1}},
+			},
+		},
+		Timestamp: ts,
+	})
+	assert.NoError(t, err)
+
+	groups := s.ConsumerGroups()
+
+	assert.Contains(t, groups, "foo")
+	assert.Equal(t, "Stable", groups["foo"].State)
+	assert.Equal(t, "consumer", groups["foo"].ProtocolType)
+	assert.Equal(t, "range", groups["foo"].Protocol)
+	assert.Equal(t, ts, groups["foo"].Timestamp)
+	assert.Len(t, groups["foo"].Members, 1)
+	assert.Equal(t, "consumer-1-abc", groups["foo"].Members[0].MemberID)
+	assert.Equal(t, []int32{0, 1}, groups["foo"].Members[0].Assignment["test"])
+
+	groups["foo"].Members[0].Assignment["test"][0] = 5
+	assert.Equal(t, []int32{0, 1}, s.ConsumerGroups()["foo"].Members[0].Assignment["test"])
+}
+
+func testStoreTopicConfigs(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	ts := time.Now().Unix() * 1000
+	err := s.SetState(&store.TopicConfigDescription{
+		Topic:     "test",
+		Configs:   map[string]string{"min.insync.replicas": "2"},
+		Timestamp: ts,
+	})
+	assert.NoError(t, err)
+
+	configs := s.TopicConfigs()
+
+	assert.Contains(t, configs, "test")
+	assert.Equal(t, map[string]string{"min.insync.replicas": "2"}, configs["test"].Configs)
+	assert.Equal(t, ts, configs["test"].Timestamp)
+
+	configs["test"].Configs["min.insync.replicas"] = "1"
+	assert.Equal(t, "2", s.TopicConfigs()["test"].Configs["min.insync.replicas"])
+}
+
+func testStoreBrokerLogDirs(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	ts := time.Now().Unix() * 1000
+	err := s.SetState(&store.BrokerLogDirsDescription{
+		Broker: 1,
+		LogDirs: []store.LogDir{
+			{
+				Path: "/data",
+				Replicas: []store.LogDirReplica{
+					{Topic: "test", Partition: 0, Size: 100, OffsetLag: 2},
+					{Topic: "test", Partition: 1, Size: 50},
+				},
+			},
+		},
+		Timestamp: ts,
+	})
+	assert.NoError(t, err)
+
+	dirs := s.BrokerLogDirs()
+
+	assert.Contains(t, dirs, int32(1))
+	assert.Equal(t, ts, dirs[1].Timestamp)
+	assert.Len(t, dirs[1].LogDirs, 1)
+	assert.Equal(t, "/data", dirs[1].LogDirs[0].Path)
+	assert.Equal(t, int64(150), dirs[1].LogDirs[0].Size())
+	assert.Equal(t, int64(2), dirs[1].LogDirs[0].Replicas[0].OffsetLag)
+
+	dirs[1].LogDirs[0].Replicas[0].Size = 0
+	assert.Equal(t, int64(100), s.BrokerLogDirs()[1].LogDirs[0].Replicas[0].Size)
+}
+
+func testStoreCleanConsumerGroups(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	s.SetState(&store.ConsumerGroupDescription{
+		Group:     "foo",
+		State:     "Empty",
+		Timestamp: time.Now().Unix() * 1000,
+	})
+	s.SetState(&store.ConsumerGroupDescription{
+		Group:     "bar",
+		State:     "Empty",
+		Timestamp: (time.Now().Unix() - 90000) * 1000,
+	})
+
+	s.CleanConsumerOffsets()
+
+	groups := s.ConsumerGroups()
+	assert.Contains(t, groups, "foo")
+	assert.NotContains(t, groups, "bar")
+}
+
+func testStoreFlush(t *testing.T, newStore storeFactory) {
+	s := newStore(t)
+
+	for i := 0; i < 100; i++ {
+		s.Channel() <- &store.ConsumerGroupDescription{
+			Group:     fmt.Sprintf("group-%d", i),
+			State:     "Stable",
+			Timestamp: time.Now().Unix() * 1000,
+		}
+	}
+	f := store.NewFlush()
+	s.Channel() <- f
+
+	select {
+	case <-f.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("flush was not done")
+	}
+
+	assert.Len(t, s.ConsumerGroups(), 100)
+}
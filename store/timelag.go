@@ -0,0 +1,67 @@
+package store
+
+// brokerHistorySize is the number of newest offset samples kept per topic partition.
+const brokerHistorySize = 120
+
+type topicPartitionKey struct {
+	topic     string
+	partition int32
+}
+
+// offsetSample represents a broker newest offset at a point in time.
+type offsetSample struct {
+	offset    int64
+	timestamp int64
+}
+
+// brokerHistory represents the recent newest offsets of a topic partition.
+type brokerHistory struct {
+	samples []offsetSample
+}
+
+// add adds a sample to the history, dropping the oldest sample if full.
+func (h *brokerHistory) add(s offsetSample) {
+	n := len(h.samples)
+	if n > 0 && s.timestamp < h.samples[n-1].timestamp {
+		// States are applied concurrently, ignore samples that arrive out of order.
+		return
+	}
+
+	h.samples = append(h.samples, s)
+	if len(h.samples) > brokerHistorySize {
+		h.samples = h.samples[len(h.samples)-brokerHistorySize:]
+	}
+}
+
+// estimateTimeLag estimates how far behind in milliseconds a consumer at the given
+// offset is at the given time, by finding when the offset became the head of the
+// partition and interpolating between samples.
+func (h *brokerHistory) estimateTimeLag(offset, ts int64) int64 {
+	n := len(h.samples)
+	if n == 0 || offset >= h.samples[n-1].offset {
+		return 0
+	}
+
+	i := 0
+	for i < n && h.samples[i].offset < offset {
+		i++
+	}
+
+	// The offset was the head before the history starts, the best we can
+	// give is a lower bound.
+	if i == 0 {
+		return positive(ts - h.samples[0].timestamp)
+	}
+
+	a, b := h.samples[i-1], h.samples[i]
+	headTs := a.timestamp + (offset-a.offset)*(b.timestamp-a.timestamp)/(b.offset-a.offset)
+
+	return positive(ts - headTs)
+}
+
+func positive(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
@@ -22,6 +22,74 @@ type Metadata struct {
 	Timestamp int64
 }
 
+// Topic config names.
+const (
+	ConfigCleanupPolicy         = "cleanup.policy"
+	ConfigRetentionMs           = "retention.ms"
+	ConfigRetentionBytes        = "retention.bytes"
+	ConfigMinInsyncReplicas     = "min.insync.replicas"
+	ConfigMaxMessageBytes       = "max.message.bytes"
+	ConfigSegmentBytes          = "segment.bytes"
+	ConfigUncleanLeaderElection = "unclean.leader.election.enable"
+)
+
+// TopicConfigDescription represents the configuration of a topic.
+type TopicConfigDescription struct {
+	Topic     string
+	Configs   map[string]string
+	Timestamp int64
+}
+
+// TopicConfigs represents a snapshot of the topic configurations.
+type TopicConfigs map[string]*TopicConfig
+
+// TopicConfig represents the configuration of a topic.
+type TopicConfig struct {
+	Configs   map[string]string
+	Timestamp int64
+}
+
+// BrokerLogDirsDescription represents the log directories of a broker.
+type BrokerLogDirsDescription struct {
+	Broker    int32
+	LogDirs   []LogDir
+	Timestamp int64
+}
+
+// BrokerLogDirs represents a snapshot of the broker log directories.
+type BrokerLogDirs map[int32]*LogDirs
+
+// LogDirs represents the log directories of a broker.
+type LogDirs struct {
+	LogDirs   []LogDir
+	Timestamp int64
+}
+
+// LogDir represents a log directory of a broker.
+type LogDir struct {
+	Path     string
+	Replicas []LogDirReplica
+}
+
+// Size returns the size of the log directory in bytes.
+func (d LogDir) Size() int64 {
+	var size int64
+	for _, r := range d.Replicas {
+		size += r.Size
+	}
+
+	return size
+}
+
+// LogDirReplica represents a partition replica in a log directory.
+type LogDirReplica struct {
+	Topic     string
+	Partition int32
+	Size      int64 // The size in bytes.
+	OffsetLag int64 // The offset lag of the log end offset behind the high watermark.
+	Temporary bool  // The replica is a future replica being moved between log directories.
+}
+
 // BrokerPartitionOffset represents a brokers partition offset.
 type BrokerPartitionOffset struct {
 	Topic               string
@@ -40,6 +108,7 @@ type BrokerOffset struct {
 	OldestOffset int64
 	NewestOffset int64
 	Timestamp    int64
+	ProduceRate  float64 // The produce rate in messages per second.
 }
 
 // ConsumerPartitionOffset represents a consumers partition offset.
@@ -50,6 +119,7 @@ type ConsumerPartitionOffset struct {
 	Oldest    bool
 	Offset    int64
 	Timestamp int64
+	Deleted   bool // The offset was deleted, e.g. when it expired.
 }
 
 // ConsumerOffsets represents a set of consumer group offsets.
@@ -57,7 +127,80 @@ type ConsumerOffsets map[string]map[string][]*ConsumerOffset
 
 // ConsumerOffset represents a consumer group topic partition offset.
 type ConsumerOffset struct {
-	Offset    int64
-	Timestamp int64
-	Lag       int64
+	Offset      int64
+	Timestamp   int64
+	Lag         int64
+	TimeLag     int64   // The estimated time lag in milliseconds.
+	ConsumeRate float64 // The consume rate in messages per second.
+	DrainRate   float64 // The rate in messages per second at which the lag decreases.
+	Status      ConsumerStatus
+
+	DistanceToOldest int64 // The number of messages between the oldest offset and the committed offset.
+	DataLoss         bool  // The committed offset is below the oldest offset.
+	AtRisk           bool  // The committed offset is close to the oldest offset while there is lag.
+}
+
+// ConsumerGroupDescription represents a consumer group description.
+type ConsumerGroupDescription struct {
+	Group        string
+	State        string
+	ProtocolType string
+	Protocol     string
+	Members      []ConsumerGroupMember
+	Timestamp    int64
+}
+
+// ConsumerGroups represents a set of consumer group descriptions.
+type ConsumerGroups map[string]*ConsumerGroup
+
+// ConsumerGroup represents a consumer group state and membership.
+type ConsumerGroup struct {
+	State        string
+	ProtocolType string
+	Protocol     string
+	Members      []ConsumerGroupMember
+	Timestamp    int64
+}
+
+// ConsumerGroupMember represents a consumer group member and its assignment.
+type ConsumerGroupMember struct {
+	MemberID   string
+	ClientID   string
+	ClientHost string
+	Assignment map[string][]int32
+}
+
+// HistoryPoint represents the state of a partition at a point in time.
+//
+// Downsampled points hold the last offsets and the maximum lag of their interval.
+type HistoryPoint struct {
+	Timestamp    int64
+	Offset       int64 // The consumer offset, or the newest broker offset.
+	OldestOffset int64 // The oldest broker offset, only set on broker history.
+	Lag          int64 // The consumer lag, only set on consumer history.
+}
+
+// HistoryRange represents a history query in milliseconds.
+//
+// A zero To has no upper bound, and a zero Step returns the points at their stored resolution.
+type HistoryRange struct {
+	From int64
+	To   int64
+	Step int64
+}
+
+// Flush represents a marker sent over the state channel, which is done
+// once all states sent before it have been applied to the store.
+type Flush struct {
+	done chan struct{}
+}
+
+// NewFlush creates and returns a new Flush.
+func NewFlush() *Flush {
+	return &Flush{done: make(chan struct{})}
+}
+
+// Done returns a channel that is closed when the flush is done.
+func (f *Flush) Done() <-chan struct{} {
+	return f.done
 }
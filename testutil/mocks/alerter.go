@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"github.com/msales/kage/alert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAlerter represents a mock alert rule evaluator.
+type MockAlerter struct {
+	mock.Mock
+}
+
+// Evaluate evaluates the alert rules against the state of a cluster.
+func (m *MockAlerter) Evaluate(s alert.Snapshot) {
+	m.Called(s)
+}
+
+// Alerts returns the pending and firing alerts.
+func (m *MockAlerter) Alerts() []alert.Alert {
+	args := m.Called()
+	return args.Get(0).([]alert.Alert)
+}
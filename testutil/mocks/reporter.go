@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"github.com/msales/kage/kafka"
 	"github.com/msales/kage/store"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,3 +25,18 @@ func (m *MockReporter) ReportConsumerOffsets(v *store.ConsumerOffsets) {
 func (m *MockReporter) ReportBrokerMetadata(v *store.BrokerMetadata) {
 	m.Called(v)
 }
+
+// ReportClusterHealth reports a snapshot of the cluster partition health.
+func (m *MockReporter) ReportClusterHealth(v *store.ClusterHealth) {
+	m.Called(v)
+}
+
+// ReportBrokerLogDirs reports a snapshot of the broker log directories.
+func (m *MockReporter) ReportBrokerLogDirs(v *store.BrokerLogDirs) {
+	m.Called(v)
+}
+
+// ReportClusterSummary reports a summary of the cluster brokers.
+func (m *MockReporter) ReportClusterSummary(v *kafka.ClusterSummary) {
+	m.Called(v)
+}
@@ -34,6 +34,36 @@ func (m *MockStore) BrokerMetadata() store.BrokerMetadata {
 	return args.Get(0).(store.BrokerMetadata)
 }
 
+// ConsumerGroups returns a snapshot of the current consumer group descriptions.
+func (m *MockStore) ConsumerGroups() store.ConsumerGroups {
+	args := m.Called()
+	return args.Get(0).(store.ConsumerGroups)
+}
+
+// TopicConfigs returns a snapshot of the current topic configurations.
+func (m *MockStore) TopicConfigs() store.TopicConfigs {
+	args := m.Called()
+	return args.Get(0).(store.TopicConfigs)
+}
+
+// BrokerLogDirs returns a snapshot of the current broker log directories.
+func (m *MockStore) BrokerLogDirs() store.BrokerLogDirs {
+	args := m.Called()
+	return args.Get(0).(store.BrokerLogDirs)
+}
+
+// BrokerHistory returns the offset history of a topic, indexed by partition.
+func (m *MockStore) BrokerHistory(topic string, r store.HistoryRange) [][]store.HistoryPoint {
+	args := m.Called(topic, r)
+	return args.Get(0).([][]store.HistoryPoint)
+}
+
+// ConsumerHistory returns the offset and lag history of a consumer group on a topic, indexed by partition.
+func (m *MockStore) ConsumerHistory(group, topic string, r store.HistoryRange) [][]store.HistoryPoint {
+	args := m.Called(group, topic, r)
+	return args.Get(0).([][]store.HistoryPoint)
+}
+
 // Channel get the offset channel.
 func (m *MockStore) Channel() chan interface{} {
 	args := m.Called()